@@ -0,0 +1,303 @@
+package stack
+
+import (
+	"fmt"
+	"math"
+)
+
+// Aggregates are running statistics over the contents of an AggregatingStack.
+type Aggregates struct {
+	Count uint
+	Sum   float64
+	Mean  float64
+	// Variance is the population variance, which is zero for fewer than two elements.
+	Variance float64
+}
+
+// AggregatingStack is a stack of float64 values that maintains the count, sum, mean
+// and variance of its contents incrementally, as values are pushed, popped and (for
+// a discarding stack) evicted from the bottom, so reading the aggregates never requires
+// a pass over the contents.  A discarding AggregatingStack thus maintains statistics
+// over a sliding window of the most recently pushed values.
+//
+// NaN and infinite values may be pushed, and they affect the aggregates as they would
+// in a direct computation over the contents: while the stack holds a NaN, or both
+// infinities, the sum, mean and variance are NaN, and while it holds only one of the
+// infinities, the sum and mean are that infinity and the variance is NaN.  Such values
+// are counted apart from the finite values, so once they are removed, the aggregates
+// are again those of the finite values alone.
+//
+// Removing a value by subtraction is not exact, so the aggregates of a stack through
+// which many values pass can drift from those of its contents.  To bound that drift,
+// the aggregates are recomputed from the contents after a number of removals that is
+// at least the depth of the stack, which keeps the average cost of a removal constant.
+type AggregatingStack struct {
+	stack *Stack
+}
+
+// NewAggregatingStack returns an empty AggregatingStack.  Options are applied as they
+// are by New().  If WithBackend() is used, the Backend must contain only float64 values.
+func NewAggregatingStack(options ...Option) (*AggregatingStack, error) {
+	configuration, err := newConfigurationFrom(options)
+	if err != nil {
+		return nil, err
+	}
+
+	innerBackend := configuration.backend
+	if innerBackend == nil {
+		innerBackend = NewRingBackend(configuration.initialCapacity)
+	}
+
+	aggregatingBackend, err := newAggregatingBackendAround(innerBackend)
+	if err != nil {
+		return nil, err
+	}
+
+	configuration.backend = aggregatingBackend
+
+	return &AggregatingStack{newStackUsingManipulator(configuration.manipulator())}, nil
+}
+
+// Push pushes a value to the top of the stack, as Stack.Push() does.
+func (stack *AggregatingStack) Push(value float64) (cannotPushBecauseStackIsFull bool) {
+	return stack.stack.Push(value)
+}
+
+// Pop removes the value from the top of the stack and returns it, as Stack.Pop() does.
+func (stack *AggregatingStack) Pop() (value float64, stackWasEmptyBeforePop bool) {
+	v, stackWasEmptyBeforePop := stack.stack.Pop()
+	if stackWasEmptyBeforePop {
+		return 0, true
+	}
+
+	return v.(float64), false
+}
+
+// Peek returns the value at the top of the stack, as Stack.Peek() does.
+func (stack *AggregatingStack) Peek() (value float64, stackIsEmpty bool) {
+	v, stackIsEmpty := stack.stack.Peek()
+	if stackIsEmpty {
+		return 0, true
+	}
+
+	return v.(float64), false
+}
+
+// Depth returns the number of values currently on the stack.
+func (stack *AggregatingStack) Depth() uint {
+	return stack.stack.Depth()
+}
+
+// IsEmpty returns true if the stack is empty (i.e., the depth is 0), or false otherwise.
+func (stack *AggregatingStack) IsEmpty() bool {
+	return stack.stack.IsEmpty()
+}
+
+// ResetToEmpty silently discards all elements on the stack and resets the aggregates.
+func (stack *AggregatingStack) ResetToEmpty() {
+	stack.stack.ResetToEmpty()
+}
+
+// SetMaximumDepthTo changes the maximum depth of the stack, as Stack.SetMaximumDepthTo()
+// does.  Values discarded because of a reduced maximum are removed from the aggregates.
+func (stack *AggregatingStack) SetMaximumDepthTo(maximumNumberOfAllowedElements uint) *AggregatingStack {
+	stack.stack.SetMaximumDepthTo(maximumNumberOfAllowedElements)
+	return stack
+}
+
+// RemoveMaximumDepth lifts the maximum depth of the stack, as Stack.RemoveMaximumDepth()
+// does.
+func (stack *AggregatingStack) RemoveMaximumDepth() *AggregatingStack {
+	stack.stack.RemoveMaximumDepth()
+	return stack
+}
+
+// Clone returns a new AggregatingStack that is independent of this one but has
+// identical contents, aggregates and configuration.
+func (stack *AggregatingStack) Clone() *AggregatingStack {
+	return &AggregatingStack{stack.stack.Clone()}
+}
+
+// Aggregates returns all of the aggregates of the stack contents, as of a single moment.
+func (stack *AggregatingStack) Aggregates() Aggregates {
+	release := stack.stack.holdManipulator()
+	defer release()
+
+	return stack.stack.manipulator.backend.(*aggregatingBackend).aggregates()
+}
+
+// Count returns the number of values on the stack.  It is the same as Depth().
+func (stack *AggregatingStack) Count() uint {
+	return stack.Aggregates().Count
+}
+
+// Sum returns the sum of the values on the stack, or zero if it is empty.
+func (stack *AggregatingStack) Sum() float64 {
+	return stack.Aggregates().Sum
+}
+
+// Mean returns the mean of the values on the stack, or zero if it is empty.
+func (stack *AggregatingStack) Mean() float64 {
+	return stack.Aggregates().Mean
+}
+
+// Variance returns the population variance of the values on the stack, or zero if
+// it has fewer than two values.
+func (stack *AggregatingStack) Variance() float64 {
+	return stack.Aggregates().Variance
+}
+
+// minimumNumberOfRemovalsBetweenRecomputations is the least number of removals after
+// which an aggregatingBackend recomputes its aggregates, so that a shallow stack is not
+// recomputed after nearly every removal.
+const minimumNumberOfRemovalsBetweenRecomputations = 1024
+
+// aggregatingBackend wraps another Backend, updating running aggregates as values are
+// added and removed.  The mean and the sum of squared differences from the mean of the
+// finite values are maintained with Welford's method, which is extended to removals by
+// running it in reverse.  NaN and infinite values are only counted.
+type aggregatingBackend struct {
+	Backend
+	countOfFiniteValues        uint
+	sum                        float64
+	mean                       float64
+	sumOfSquaredDifferences    float64
+	countOfNaNs                uint
+	countOfPositiveInfinities  uint
+	countOfNegativeInfinities  uint
+	removalsSinceRecomputation uint
+}
+
+func newAggregatingBackendAround(inner Backend) (*aggregatingBackend, error) {
+	for distanceFromTop := uint(0); distanceFromTop < inner.Depth(); distanceFromTop++ {
+		if _, isFloat64 := inner.ElementAt(distanceFromTop).(float64); !isFloat64 {
+			return nil, fmt.Errorf("backend contains a value that is not a float64")
+		}
+	}
+
+	backend := &aggregatingBackend{Backend: inner}
+	backend.recompute()
+
+	return backend, nil
+}
+
+func (backend *aggregatingBackend) PushOnTop(value interface{}) {
+	backend.Backend.PushOnTop(value)
+	backend.include(value.(float64))
+}
+
+func (backend *aggregatingBackend) PopFromTop() interface{} {
+	value := backend.Backend.PopFromTop()
+	backend.exclude(value.(float64))
+	return value
+}
+
+func (backend *aggregatingBackend) RemoveFromBottom() interface{} {
+	value := backend.Backend.RemoveFromBottom()
+	backend.exclude(value.(float64))
+	return value
+}
+
+func (backend *aggregatingBackend) Clear() {
+	backend.Backend.Clear()
+	backend.recompute()
+}
+
+func (backend *aggregatingBackend) Clone() Backend {
+	clone := *backend
+	clone.Backend = backend.Backend.Clone()
+	return &clone
+}
+
+func (backend *aggregatingBackend) include(value float64) {
+	switch {
+	case math.IsNaN(value):
+		backend.countOfNaNs++
+	case math.IsInf(value, 1):
+		backend.countOfPositiveInfinities++
+	case math.IsInf(value, -1):
+		backend.countOfNegativeInfinities++
+	default:
+		backend.countOfFiniteValues++
+		backend.sum += value
+
+		differenceFromOldMean := value - backend.mean
+		backend.mean += differenceFromOldMean / float64(backend.countOfFiniteValues)
+		backend.sumOfSquaredDifferences += differenceFromOldMean * (value - backend.mean)
+	}
+}
+
+func (backend *aggregatingBackend) exclude(value float64) {
+	switch {
+	case math.IsNaN(value):
+		backend.countOfNaNs--
+	case math.IsInf(value, 1):
+		backend.countOfPositiveInfinities--
+	case math.IsInf(value, -1):
+		backend.countOfNegativeInfinities--
+	default:
+		backend.excludeFiniteValue(value)
+	}
+
+	backend.removalsSinceRecomputation++
+	if backend.removalsSinceRecomputation >= minimumNumberOfRemovalsBetweenRecomputations && backend.removalsSinceRecomputation >= backend.Backend.Depth() {
+		backend.recompute()
+	}
+}
+
+func (backend *aggregatingBackend) excludeFiniteValue(value float64) {
+	backend.countOfFiniteValues--
+
+	if backend.countOfFiniteValues == 0 {
+		backend.sum, backend.mean, backend.sumOfSquaredDifferences = 0, 0, 0
+		return
+	}
+
+	backend.sum -= value
+
+	differenceFromOldMean := value - backend.mean
+	backend.mean -= differenceFromOldMean / float64(backend.countOfFiniteValues)
+	backend.sumOfSquaredDifferences -= differenceFromOldMean * (value - backend.mean)
+
+	if backend.sumOfSquaredDifferences < 0 {
+		backend.sumOfSquaredDifferences = 0
+	}
+}
+
+// recompute discards the running aggregates and computes them again from the values in
+// the wrapped Backend, from the bottom to the top.
+func (backend *aggregatingBackend) recompute() {
+	*backend = aggregatingBackend{Backend: backend.Backend}
+
+	for distanceFromTop := backend.Backend.Depth(); distanceFromTop > 0; distanceFromTop-- {
+		backend.include(backend.Backend.ElementAt(distanceFromTop - 1).(float64))
+	}
+}
+
+func (backend *aggregatingBackend) aggregates() Aggregates {
+	count := backend.countOfFiniteValues + backend.countOfNaNs + backend.countOfPositiveInfinities + backend.countOfNegativeInfinities
+
+	aggregates := Aggregates{Count: count, Sum: backend.sum, Mean: backend.mean}
+	if backend.countOfFiniteValues > 1 {
+		aggregates.Variance = backend.sumOfSquaredDifferences / float64(backend.countOfFiniteValues)
+	}
+
+	switch {
+	case backend.countOfNaNs > 0 || (backend.countOfPositiveInfinities > 0 && backend.countOfNegativeInfinities > 0):
+		aggregates.Sum, aggregates.Mean = math.NaN(), math.NaN()
+	case backend.countOfPositiveInfinities > 0:
+		aggregates.Sum, aggregates.Mean = math.Inf(1), math.Inf(1)
+	case backend.countOfNegativeInfinities > 0:
+		aggregates.Sum, aggregates.Mean = math.Inf(-1), math.Inf(-1)
+	default:
+		return aggregates
+	}
+
+	if count > 1 {
+		aggregates.Variance = math.NaN()
+	} else {
+		aggregates.Variance = 0
+	}
+
+	return aggregates
+}
@@ -0,0 +1,214 @@
+package stack_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	. "github.com/onsi/gomega"
+)
+
+func bruteForceAggregatesOf(values []float64) stack.Aggregates {
+	aggregates := stack.Aggregates{Count: uint(len(values))}
+	if len(values) == 0 {
+		return aggregates
+	}
+
+	for _, value := range values {
+		aggregates.Sum += value
+	}
+	aggregates.Mean = aggregates.Sum / float64(len(values))
+
+	if len(values) > 1 {
+		for _, value := range values {
+			aggregates.Variance += (value - aggregates.Mean) * (value - aggregates.Mean)
+		}
+		aggregates.Variance /= float64(len(values))
+	}
+
+	return aggregates
+}
+
+func expectAggregatesToBeNear(g *WithT, actual, expected stack.Aggregates) {
+	g.Expect(actual.Count).To(Equal(expected.Count))
+	g.Expect(actual.Sum).To(BeNumerically("~", expected.Sum, 1e-9))
+	g.Expect(actual.Mean).To(BeNumerically("~", expected.Mean, 1e-9))
+	g.Expect(actual.Variance).To(BeNumerically("~", expected.Variance, 1e-9))
+}
+
+func TestAggregatingStack(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s, err := stack.NewAggregatingStack()
+	g.Expect(err).To(BeNil())
+	g.Expect(s.Aggregates()).To(Equal(stack.Aggregates{}))
+
+	for _, testCase := range []struct {
+		operation          string
+		value              float64
+		expectedAggregates stack.Aggregates
+	}{
+		{"push", 2, stack.Aggregates{1, 2, 2, 0}},
+		{"push", 4, stack.Aggregates{2, 6, 3, 1}},
+		{"push", 9, stack.Aggregates{3, 15, 5, 26.0 / 3}},
+		{"pop", 9, stack.Aggregates{2, 6, 3, 1}},
+		{"pop", 4, stack.Aggregates{1, 2, 2, 0}},
+		{"pop", 2, stack.Aggregates{0, 0, 0, 0}},
+		{"push", -1, stack.Aggregates{1, -1, -1, 0}},
+	} {
+		if testCase.operation == "push" {
+			g.Expect(s.Push(testCase.value)).To(BeFalse())
+		} else {
+			value, stackWasEmpty := s.Pop()
+			g.Expect(stackWasEmpty).To(BeFalse())
+			g.Expect(value).To(Equal(testCase.value))
+		}
+
+		expectAggregatesToBeNear(g, s.Aggregates(), testCase.expectedAggregates)
+	}
+
+	g.Expect(s.Count()).To(Equal(uint(1)))
+	g.Expect(s.Sum()).To(Equal(-1.0))
+	g.Expect(s.Mean()).To(Equal(-1.0))
+	g.Expect(s.Variance()).To(Equal(0.0))
+
+	s.ResetToEmpty()
+	g.Expect(s.Aggregates()).To(Equal(stack.Aggregates{}))
+
+	_, stackWasEmpty := s.Pop()
+	g.Expect(stackWasEmpty).To(BeTrue())
+	_, stackIsEmpty := s.Peek()
+	g.Expect(stackIsEmpty).To(BeTrue())
+}
+
+func TestAggregatingStackWithBottomEviction(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s, err := stack.NewAggregatingStack(stack.WithMaxDepth(3), stack.WithDiscardOldest())
+	g.Expect(err).To(BeNil())
+
+	window := []float64{}
+	for _, value := range []float64{1, 5, 3, 10, -2, 7, 7, 7} {
+		s.Push(value)
+
+		window = append(window, value)
+		if len(window) > 3 {
+			window = window[1:]
+		}
+
+		expectAggregatesToBeNear(g, s.Aggregates(), bruteForceAggregatesOf(window))
+	}
+
+}
+
+func TestAggregatingStackWithBackendAndClone(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	backend := stack.NewRingBackend(4)
+	backend.PushOnTop(1.0)
+	backend.PushOnTop(3.0)
+
+	s, err := stack.NewAggregatingStack(stack.WithBackend(backend), stack.WithMaxDepth(3))
+	g.Expect(err).To(BeNil())
+	expectAggregatesToBeNear(g, s.Aggregates(), bruteForceAggregatesOf([]float64{1, 3}))
+
+	g.Expect(s.Push(5)).To(BeFalse())
+	g.Expect(s.Push(7)).To(BeTrue())
+
+	clone := s.Clone()
+	clone.Pop()
+	expectAggregatesToBeNear(g, s.Aggregates(), bruteForceAggregatesOf([]float64{1, 3, 5}))
+	expectAggregatesToBeNear(g, clone.Aggregates(), bruteForceAggregatesOf([]float64{1, 3}))
+
+	s.SetMaximumDepthTo(2)
+	expectAggregatesToBeNear(g, s.Aggregates(), bruteForceAggregatesOf([]float64{1, 3}))
+
+	s.RemoveMaximumDepth()
+	g.Expect(s.Push(5)).To(BeFalse())
+	g.Expect(s.Push(7)).To(BeFalse())
+	expectAggregatesToBeNear(g, s.Aggregates(), bruteForceAggregatesOf([]float64{1, 3, 5, 7}))
+
+	backendWithNonNumericValue := stack.NewRingBackend(1)
+	backendWithNonNumericValue.PushOnTop("one")
+	_, err = stack.NewAggregatingStack(stack.WithBackend(backendWithNonNumericValue))
+	g.Expect(err).ToNot(BeNil())
+}
+
+func TestAggregatingStackAgainstBruteForce(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	random := rand.New(rand.NewSource(38))
+	s, _ := stack.NewAggregatingStack(stack.WithMaxDepth(50), stack.WithDiscardOldest())
+	model := []float64{}
+
+	for i := 0; i < 5000; i++ {
+		if random.Intn(3) == 0 && len(model) > 0 {
+			s.Pop()
+			model = model[:len(model)-1]
+		} else {
+			value := math.Round(random.NormFloat64()*1000) / 10
+			s.Push(value)
+			model = append(model, value)
+			if len(model) > 50 {
+				model = model[1:]
+			}
+		}
+
+		expected := bruteForceAggregatesOf(model)
+		actual := s.Aggregates()
+		g.Expect(actual.Count).To(Equal(expected.Count))
+		g.Expect(actual.Mean).To(BeNumerically("~", expected.Mean, 1e-6))
+		g.Expect(actual.Variance).To(BeNumerically("~", expected.Variance, 1e-6*math.Max(1, expected.Variance)))
+	}
+}
+
+func TestAggregatingStackWithNonFiniteValues(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s, _ := stack.NewAggregatingStack()
+	s.Push(2)
+	s.Push(4)
+
+	s.Push(math.Inf(1))
+	aggregates := s.Aggregates()
+	g.Expect(aggregates.Count).To(Equal(uint(3)))
+	g.Expect(math.IsInf(aggregates.Sum, 1)).To(BeTrue())
+	g.Expect(math.IsInf(aggregates.Mean, 1)).To(BeTrue())
+	g.Expect(math.IsNaN(aggregates.Variance)).To(BeTrue())
+
+	s.Push(math.Inf(-1))
+	g.Expect(math.IsNaN(s.Sum())).To(BeTrue())
+	s.Pop()
+
+	s.Push(math.NaN())
+	g.Expect(math.IsNaN(s.Mean())).To(BeTrue())
+	s.Pop()
+	s.Pop()
+
+	g.Expect(s.Aggregates()).To(Equal(stack.Aggregates{2, 6, 3, 1}))
+
+	single, _ := stack.NewAggregatingStack()
+	single.Push(math.Inf(-1))
+	g.Expect(math.IsInf(single.Mean(), -1)).To(BeTrue())
+	g.Expect(single.Variance()).To(Equal(0.0))
+}
+
+func TestAggregatingStackRecomputesAggregatesAfterRemovals(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s, _ := stack.NewAggregatingStack()
+	s.Push(1)
+
+	// Adding and then subtracting 1e20 loses the 1 entirely.
+	s.Push(1e20)
+	s.Pop()
+	g.Expect(s.Sum()).To(Equal(0.0))
+
+	for i := 0; i < 1100; i++ {
+		s.Push(2)
+		s.Pop()
+	}
+
+	g.Expect(s.Aggregates()).To(Equal(stack.Aggregates{1, 1, 1, 0}))
+}
@@ -0,0 +1,124 @@
+package stack
+
+// Backend is the element storage used by a Stack.  A Stack's manipulator is the only
+// caller of a Backend's methods and never calls them concurrently, so a Backend need
+// not be safe for concurrent use.  The manipulator also enforces maximum depths and
+// discarding, and never asks a Backend to remove or retrieve an element it does not
+// have, so a Backend need only store what it is given.  A Backend must not be shared
+// between stacks.
+type Backend interface {
+	// PushOnTop adds a value to the top of the store.
+	PushOnTop(value interface{})
+
+	// PopFromTop removes the value at the top of the store and returns it.
+	PopFromTop() interface{}
+
+	// RemoveFromBottom removes the value at the bottom of the store and returns it.
+	RemoveFromBottom() interface{}
+
+	// ElementAt returns the value that is the provided number of elements below the
+	// top of the store, so that ElementAt(0) is the top of the store.
+	ElementAt(distanceFromTop uint) interface{}
+
+	// Depth returns the number of values in the store.
+	Depth() uint
+
+	// Clear removes all values from the store.
+	Clear()
+
+	// Clone returns an independent copy of the store and its values.
+	Clone() Backend
+}
+
+// NewRingBackend returns the Backend used by a Stack when no other is supplied.  It
+// stores elements in a circular buffer with an initial capacity of the provided number
+// of elements, so that removal from either end is cheap.  The buffer grows as needed.
+func NewRingBackend(initialCapacity uint) Backend {
+	return &ringBackend{
+		elementRing:            make([]interface{}, initialCapacity),
+		indexInRingOfBottom:    0,
+		numberOfStoredElements: 0,
+	}
+}
+
+type ringBackend struct {
+	elementRing            []interface{}
+	indexInRingOfBottom    int
+	numberOfStoredElements uint
+}
+
+func (ring *ringBackend) PushOnTop(value interface{}) {
+	if ring.numberOfStoredElements == uint(len(ring.elementRing)) {
+		ring.grow()
+	}
+
+	ring.elementRing[ring.ringIndexOf(ring.numberOfStoredElements)] = value
+	ring.numberOfStoredElements++
+}
+
+func (ring *ringBackend) PopFromTop() interface{} {
+	indexOfTop := ring.ringIndexOf(ring.numberOfStoredElements - 1)
+
+	value := ring.elementRing[indexOfTop]
+	ring.elementRing[indexOfTop] = nil
+	ring.numberOfStoredElements--
+
+	return value
+}
+
+func (ring *ringBackend) RemoveFromBottom() interface{} {
+	value := ring.elementRing[ring.indexInRingOfBottom]
+	ring.elementRing[ring.indexInRingOfBottom] = nil
+	ring.indexInRingOfBottom = ring.ringIndexOf(1)
+	ring.numberOfStoredElements--
+
+	return value
+}
+
+func (ring *ringBackend) ElementAt(distanceFromTop uint) interface{} {
+	return ring.elementRing[ring.ringIndexOf(ring.numberOfStoredElements-1-distanceFromTop)]
+}
+
+func (ring *ringBackend) Depth() uint {
+	return ring.numberOfStoredElements
+}
+
+func (ring *ringBackend) Clear() {
+	for i := range ring.elementRing {
+		ring.elementRing[i] = nil
+	}
+
+	ring.indexInRingOfBottom = 0
+	ring.numberOfStoredElements = 0
+}
+
+func (ring *ringBackend) Clone() Backend {
+	clonedRing := make([]interface{}, len(ring.elementRing))
+	copy(clonedRing, ring.elementRing)
+
+	return &ringBackend{
+		elementRing:            clonedRing,
+		indexInRingOfBottom:    ring.indexInRingOfBottom,
+		numberOfStoredElements: ring.numberOfStoredElements,
+	}
+}
+
+// ringIndexOf maps a distance from the bottom of the stack to an index in the ring.
+func (ring *ringBackend) ringIndexOf(distanceFromBottom uint) int {
+	return (ring.indexInRingOfBottom + int(distanceFromBottom)) % len(ring.elementRing)
+}
+
+func (ring *ringBackend) grow() {
+	newCapacity := 2 * len(ring.elementRing)
+	if newCapacity == 0 {
+		newCapacity = 1
+	}
+
+	grownRing := make([]interface{}, newCapacity)
+	for i := uint(0); i < ring.numberOfStoredElements; i++ {
+		grownRing[i] = ring.elementRing[ring.ringIndexOf(i)]
+	}
+
+	ring.elementRing = grownRing
+	ring.indexInRingOfBottom = 0
+}
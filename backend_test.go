@@ -0,0 +1,43 @@
+package stack_test
+
+import (
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	. "github.com/onsi/gomega"
+)
+
+func TestRingBackend(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	ring := stack.NewRingBackend(0)
+
+	for _, v := range []string{"first", "second", "third"} {
+		ring.PushOnTop(v)
+	}
+
+	g.Expect(ring.Depth()).To(Equal(uint(3)))
+	g.Expect(ring.ElementAt(0)).To(Equal("third"))
+	g.Expect(ring.ElementAt(2)).To(Equal("first"))
+
+	g.Expect(ring.RemoveFromBottom()).To(Equal("first"))
+	ring.PushOnTop("fourth")
+	ring.PushOnTop("fifth")
+
+	clone := ring.Clone()
+
+	g.Expect(ring.PopFromTop()).To(Equal("fifth"))
+	g.Expect(ring.RemoveFromBottom()).To(Equal("second"))
+	g.Expect(ring.PopFromTop()).To(Equal("fourth"))
+	g.Expect(ring.PopFromTop()).To(Equal("third"))
+	g.Expect(ring.Depth()).To(Equal(uint(0)))
+
+	g.Expect(clone.Depth()).To(Equal(uint(4)))
+	g.Expect(clone.ElementAt(0)).To(Equal("fifth"))
+	g.Expect(clone.ElementAt(3)).To(Equal("second"))
+
+	clone.Clear()
+	g.Expect(clone.Depth()).To(Equal(uint(0)))
+	clone.PushOnTop("sixth")
+	g.Expect(clone.PopFromTop()).To(Equal("sixth"))
+}
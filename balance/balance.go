@@ -0,0 +1,225 @@
+// Package balance checks that delimiters in text are balanced and properly nested.
+// The delimiters are configurable pairs of opening and closing tokens, such as
+// brackets, HTML-like tags or the keywords that open and close configuration
+// blocks.  Open delimiters are tracked on a stack.Stack, and a maximum nesting
+// depth may be enforced.  Problems are reported as an *Error giving the line and
+// column at which they were found.
+package balance
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/blorticus-go/stack"
+)
+
+// Pair is an opening token and its matching closing token.  If Open and Close are the
+// same, as for quotation marks, the token closes the innermost open delimiter if that
+// delimiter was opened with the same token, and otherwise opens a new delimiter.
+type Pair struct {
+	Open  string
+	Close string
+}
+
+// Brackets are the pairs (), [] and {}.
+var Brackets = []Pair{{"(", ")"}, {"[", "]"}, {"{", "}"}}
+
+// ErrorKind identifies the kind of problem described by an Error.
+type ErrorKind int
+
+const (
+	// MismatchedClose is a closing token that does not match the innermost open delimiter.
+	MismatchedClose ErrorKind = iota
+	// UnexpectedClose is a closing token found when no delimiter is open.
+	UnexpectedClose
+	// Unclosed is an opening token that is never closed.
+	Unclosed
+	// NestingTooDeep is an opening token that exceeds the maximum nesting depth.
+	NestingTooDeep
+)
+
+// Position is a location in the validated text.  Line and Column are 1-based, and
+// Column counts characters (runes) rather than bytes.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// Error describes the first problem found in the validated text.
+type Error struct {
+	Kind ErrorKind
+	// Position is where the problem was found.  For Unclosed, it is the end of the text.
+	Position Position
+	// Token is the token at Position, or the unclosed opening token for Unclosed.
+	Token string
+	// OpenToken and OpenedAt describe the innermost open delimiter, for MismatchedClose
+	// and Unclosed.
+	OpenToken string
+	OpenedAt  Position
+}
+
+func (err *Error) Error() string {
+	switch err.Kind {
+	case MismatchedClose:
+		return fmt.Sprintf("line %d, column %d: '%s' does not close '%s' opened at line %d, column %d",
+			err.Position.Line, err.Position.Column, err.Token, err.OpenToken, err.OpenedAt.Line, err.OpenedAt.Column)
+	case UnexpectedClose:
+		return fmt.Sprintf("line %d, column %d: '%s' closes nothing", err.Position.Line, err.Position.Column, err.Token)
+	case Unclosed:
+		return fmt.Sprintf("line %d, column %d: '%s' opened at line %d, column %d is never closed",
+			err.Position.Line, err.Position.Column, err.OpenToken, err.OpenedAt.Line, err.OpenedAt.Column)
+	default:
+		return fmt.Sprintf("line %d, column %d: '%s' exceeds the maximum nesting depth", err.Position.Line, err.Position.Column, err.Token)
+	}
+}
+
+// Validator checks text for balanced delimiters.  A Validator is safe for concurrent
+// use once it has been configured.
+type Validator struct {
+	pairs               []Pair
+	maximumNestingDepth uint
+}
+
+// NewValidator returns a Validator for the provided pairs.  An error is returned if no
+// pairs are provided, if a token is empty, or if a token is used by more than one pair.
+func NewValidator(pairs ...Pair) (*Validator, error) {
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("at least one pair is required")
+	}
+
+	pairOfToken := make(map[string]int)
+	for i, pair := range pairs {
+		if pair.Open == "" || pair.Close == "" {
+			return nil, fmt.Errorf("pair %d has an empty token", i+1)
+		}
+
+		for _, token := range []string{pair.Open, pair.Close} {
+			if j, alreadyUsed := pairOfToken[token]; alreadyUsed && j != i {
+				return nil, fmt.Errorf("token '%s' is used by more than one pair", token)
+			}
+			pairOfToken[token] = i
+		}
+	}
+
+	return &Validator{pairs: append([]Pair(nil), pairs...)}, nil
+}
+
+// WithAMaximumNestingDepthOf sets the maximum number of delimiters that may be open at
+// once.  An opening token that would exceed it is reported as NestingTooDeep.  A maximum
+// of zero, which is the default, means that nesting is unlimited.
+func (validator *Validator) WithAMaximumNestingDepthOf(maximumNumberOfOpenDelimiters uint) *Validator {
+	validator.maximumNestingDepth = maximumNumberOfOpenDelimiters
+	return validator
+}
+
+// ValidateString checks that the delimiters in the text are balanced.  It returns nil
+// if they are, or an *Error describing the first problem found.
+func (validator *Validator) ValidateString(text string) error {
+	openDelimiters := stack.NewStack()
+	defer openDelimiters.Close()
+
+	if validator.maximumNestingDepth > 0 {
+		openDelimiters.WithAMaximumDepthOf(validator.maximumNestingDepth)
+	}
+
+	position := Position{Line: 1, Column: 1}
+
+	for offset := 0; offset < len(text); {
+		pairIndex, token, isOpening := validator.longestTokenAt(text[offset:], openDelimiters)
+
+		if token == "" {
+			r, size := utf8.DecodeRuneInString(text[offset:])
+			position = position.advancedPast(r)
+			offset += size
+			continue
+		}
+
+		if isOpening {
+			delimiter := &openDelimiter{pairIndex, position}
+			if cannotPushBecauseStackIsFull := openDelimiters.Push(delimiter); cannotPushBecauseStackIsFull {
+				return &Error{Kind: NestingTooDeep, Position: position, Token: token}
+			}
+		} else {
+			innermost, stackWasEmpty := openDelimiters.Pop()
+			if stackWasEmpty {
+				return &Error{Kind: UnexpectedClose, Position: position, Token: token}
+			}
+
+			if innermost := innermost.(*openDelimiter); innermost.pairIndex != pairIndex {
+				return &Error{
+					Kind:      MismatchedClose,
+					Position:  position,
+					Token:     token,
+					OpenToken: validator.pairs[innermost.pairIndex].Open,
+					OpenedAt:  innermost.openedAt,
+				}
+			}
+		}
+
+		for _, r := range token {
+			position = position.advancedPast(r)
+		}
+		offset += len(token)
+	}
+
+	if innermost, stackWasEmpty := openDelimiters.Pop(); !stackWasEmpty {
+		innermost := innermost.(*openDelimiter)
+		return &Error{
+			Kind:      Unclosed,
+			Position:  position,
+			Token:     validator.pairs[innermost.pairIndex].Open,
+			OpenToken: validator.pairs[innermost.pairIndex].Open,
+			OpenedAt:  innermost.openedAt,
+		}
+	}
+
+	return nil
+}
+
+// Validate reads all of the text from the reader and checks it as ValidateString() does.
+// An error from the reader is returned as is.
+func (validator *Validator) Validate(reader io.Reader) error {
+	text, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	return validator.ValidateString(string(text))
+}
+
+type openDelimiter struct {
+	pairIndex int
+	openedAt  Position
+}
+
+// longestTokenAt returns the longest token at the start of the remaining text, along
+// with the index of its pair and whether it opens a delimiter.  If no token is there,
+// the returned token is empty.
+func (validator *Validator) longestTokenAt(remainingText string, openDelimiters *stack.Stack) (pairIndex int, token string, isOpening bool) {
+	for i, pair := range validator.pairs {
+		if len(pair.Open) > len(token) && strings.HasPrefix(remainingText, pair.Open) {
+			pairIndex, token, isOpening = i, pair.Open, true
+		}
+
+		if len(pair.Close) > len(token) && strings.HasPrefix(remainingText, pair.Close) {
+			pairIndex, token, isOpening = i, pair.Close, false
+		}
+	}
+
+	if token != "" && validator.pairs[pairIndex].Open == validator.pairs[pairIndex].Close {
+		innermost, stackIsEmpty := openDelimiters.Peek()
+		isOpening = stackIsEmpty || innermost.(*openDelimiter).pairIndex != pairIndex
+	}
+
+	return pairIndex, token, isOpening
+}
+
+func (position Position) advancedPast(r rune) Position {
+	if r == '\n' {
+		return Position{Line: position.Line + 1, Column: 1}
+	}
+
+	return Position{Line: position.Line, Column: position.Column + 1}
+}
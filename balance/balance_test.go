@@ -0,0 +1,119 @@
+package balance_test
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blorticus-go/stack/balance"
+)
+
+func TestValidateBrackets(t *testing.T) {
+	validator, err := balance.NewValidator(balance.Brackets...)
+	if err != nil {
+		t.Fatalf("unexpected error on NewValidator: %s", err)
+	}
+
+	for _, testCase := range []struct {
+		text          string
+		expectedError *balance.Error
+	}{
+		{"", nil},
+		{"a(b[c]{d}e)f", nil},
+		{"(\n  [\n  ]\n)", nil},
+		{"(]", &balance.Error{Kind: balance.MismatchedClose, Position: balance.Position{Line: 1, Column: 2}, Token: "]", OpenToken: "(", OpenedAt: balance.Position{Line: 1, Column: 1}}},
+		{"x\n  )", &balance.Error{Kind: balance.UnexpectedClose, Position: balance.Position{Line: 2, Column: 3}, Token: ")"}},
+		{"{\n(\n)", &balance.Error{Kind: balance.Unclosed, Position: balance.Position{Line: 3, Column: 2}, Token: "{", OpenToken: "{", OpenedAt: balance.Position{Line: 1, Column: 1}}},
+		{"é(é]", &balance.Error{Kind: balance.MismatchedClose, Position: balance.Position{Line: 1, Column: 4}, Token: "]", OpenToken: "(", OpenedAt: balance.Position{Line: 1, Column: 2}}},
+	} {
+		err := validator.ValidateString(testCase.text)
+
+		if testCase.expectedError == nil {
+			if err != nil {
+				t.Errorf("[%q] expected no error, got (%s)", testCase.text, err)
+			}
+			continue
+		}
+
+		var balanceError *balance.Error
+		if !errors.As(err, &balanceError) {
+			t.Errorf("[%q] expected *balance.Error, got (%v)", testCase.text, err)
+		} else if *balanceError != *testCase.expectedError {
+			t.Errorf("[%q] expected error (%+v), got (%+v)", testCase.text, *testCase.expectedError, *balanceError)
+		}
+	}
+}
+
+func TestValidateTagsAndQuotes(t *testing.T) {
+	validator, err := balance.NewValidator(
+		balance.Pair{Open: "<b>", Close: "</b>"},
+		balance.Pair{Open: "<", Close: ">"},
+		balance.Pair{Open: `"`, Close: `"`},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error on NewValidator: %s", err)
+	}
+
+	if err := validator.Validate(strings.NewReader(`<b>"<x>"</b>`)); err != nil {
+		t.Errorf("expected no error, got (%s)", err)
+	}
+
+	err = validator.ValidateString(`<b>"</b>"`)
+	var balanceError *balance.Error
+	if !errors.As(err, &balanceError) || balanceError.Kind != balance.MismatchedClose || balanceError.Position.Column != 5 {
+		t.Errorf("expected MismatchedClose at column 5, got (%v)", err)
+	}
+}
+
+func TestMaximumNestingDepth(t *testing.T) {
+	validator, _ := balance.NewValidator(balance.Brackets...)
+	validator.WithAMaximumNestingDepthOf(2)
+
+	if err := validator.ValidateString("(())()"); err != nil {
+		t.Errorf("expected no error, got (%s)", err)
+	}
+
+	err := validator.ValidateString("([{}])")
+	var balanceError *balance.Error
+	if !errors.As(err, &balanceError) || balanceError.Kind != balance.NestingTooDeep || balanceError.Position.Column != 3 || balanceError.Token != "{" {
+		t.Errorf("expected NestingTooDeep for '{' at column 3, got (%v)", err)
+	}
+}
+
+func TestNewValidatorErrors(t *testing.T) {
+	for _, testCase := range []struct {
+		testname string
+		pairs    []balance.Pair
+	}{
+		{"no pairs", nil},
+		{"empty token", []balance.Pair{{Open: "(", Close: ""}}},
+		{"shared token", []balance.Pair{{Open: "(", Close: ")"}, {Open: "[", Close: ")"}}},
+	} {
+		if _, err := balance.NewValidator(testCase.pairs...); err == nil {
+			t.Errorf("[%s] expected error, got none", testCase.testname)
+		}
+	}
+}
+
+func TestValidateStringDoesNotLeaveGoroutinesRunning(t *testing.T) {
+	validator, _ := balance.NewValidator(balance.Brackets...)
+	numberOfGoroutinesBefore := runtime.NumGoroutine()
+
+	for i := 0; i < 100; i++ {
+		validator.ValidateString("{[()]}")
+		validator.ValidateString("{[(])}")
+	}
+
+	// A closed stack's goroutine ends shortly after Close() returns.
+	numberOfGoroutines := runtime.NumGoroutine()
+	for attempt := 0; attempt < 100 && numberOfGoroutines > numberOfGoroutinesBefore; attempt++ {
+		time.Sleep(10 * time.Millisecond)
+		numberOfGoroutines = runtime.NumGoroutine()
+	}
+
+	if numberOfGoroutines > numberOfGoroutinesBefore {
+		t.Errorf("expected no more than %d goroutines, got %d", numberOfGoroutinesBefore, numberOfGoroutines)
+	}
+}
@@ -0,0 +1,294 @@
+// Package client operates on a stack served by package server, from another process or
+// host.  A client Stack has the same methods as a stack.Stack, so code written against
+// one can be adapted to the other with little change.
+//
+// The methods of a stack.Stack do not return errors, so neither do the methods of a
+// client Stack.  Instead, the first error encountered, whether from the network or
+// reported by the server, is recorded and returned by Err().  Once there has been an
+// error, every method returns immediately, as if the stack were empty and full, so a
+// sequence of operations may be performed and Err() checked once at the end.
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ServerError is an error reported by the server in response to a request.
+type ServerError struct {
+	Message string
+}
+
+func (err *ServerError) Error() string {
+	return "server error: " + err.Message
+}
+
+// Stack is a connection to a named stack on a server.  Its methods may be called
+// concurrently, and each is a single request to the server.
+type Stack struct {
+	name       string
+	connection net.Conn
+	reader     *bufio.Reader
+
+	mutex      sync.Mutex
+	firstError error
+}
+
+// Dial connects to the server at the network address, as net.Dial() does, for operations
+// on the stack with the provided name.  An error is returned if the connection cannot be
+// made or the server has no stack with the name.
+func Dial(network, address, stackName string) (*Stack, error) {
+	if stackName == "" || strings.ContainsAny(stackName, " \t\r\n\v\f") {
+		return nil, fmt.Errorf("stack name (%q) must be non-empty and must not contain whitespace", stackName)
+	}
+
+	connection, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	stack := &Stack{name: stackName, connection: connection, reader: bufio.NewReader(connection)}
+
+	// The response to DEPTH tells us whether the server has the named stack.
+	if stack.Depth(); stack.Err() != nil {
+		connection.Close()
+		return nil, stack.Err()
+	}
+
+	return stack, nil
+}
+
+// Close closes the connection to the server.  The stack on the server is not changed.
+func (stack *Stack) Close() error {
+	return stack.connection.Close()
+}
+
+// Err returns the first error encountered by any method, or nil if there has been none.
+func (stack *Stack) Err() error {
+	stack.mutex.Lock()
+	defer stack.mutex.Unlock()
+
+	return stack.firstError
+}
+
+// Name returns the name of the stack on the server.
+func (stack *Stack) Name() string {
+	return stack.name
+}
+
+// WithAMaximumDepthOf sets the maximum depth of the stack, as stack.Stack's method does.
+// A maximum of 0, or a server error, is recorded rather than causing a panic.
+func (stack *Stack) WithAMaximumDepthOf(maximumNumberOfAllowedElements uint) *Stack {
+	if maximumNumberOfAllowedElements < 1 {
+		stack.recordError(fmt.Errorf("stack size must be at least 1"))
+		return stack
+	}
+
+	stack.request("SETMAX", strconv.FormatUint(uint64(maximumNumberOfAllowedElements), 10))
+	return stack
+}
+
+// SetMaximumDepthTo is a synonym for WithAMaximumDepthOf.
+func (stack *Stack) SetMaximumDepthTo(maximumNumberOfAllowedElements uint) *Stack {
+	return stack.WithAMaximumDepthOf(maximumNumberOfAllowedElements)
+}
+
+// RemoveMaximumDepth removes the maximum depth of the stack, as stack.Stack's method does.
+// A server error is recorded rather than causing a panic.
+func (stack *Stack) RemoveMaximumDepth() *Stack {
+	stack.request("SETMAX", "0")
+	return stack
+}
+
+// Push pushes a value to the top of the stack, returning true when stack.Stack's Push()
+// would.  A string or []byte value is sent as it is, and any other value is formatted
+// with fmt.Sprint().  Values are strings on the server.
+func (stack *Stack) Push(value interface{}) (cannotPushBecauseStackIsFull bool) {
+	var valueAsString string
+	switch v := value.(type) {
+	case string:
+		valueAsString = v
+	case []byte:
+		valueAsString = string(v)
+	default:
+		valueAsString = fmt.Sprint(v)
+	}
+
+	response, err := stack.request("PUSH", strconv.Quote(valueAsString))
+	return err != nil || response == "FULL"
+}
+
+// Pop removes the value at the top of the stack and returns it as a string.  If the stack
+// is empty, Pop returns nil and true.  Otherwise, it returns the value and false.
+func (stack *Stack) Pop() (value interface{}, stackWasEmptyBeforePop bool) {
+	return stack.requestValue("POP")
+}
+
+// Peek returns the value at the top of the stack as a string, without removing it.  If
+// the stack is empty, Peek returns nil and true.  Otherwise, it returns the value and
+// false.
+func (stack *Stack) Peek() (value interface{}, stackIsEmpty bool) {
+	return stack.requestValue("PEEK")
+}
+
+// PopString is the same as Pop(), but the returned value is a string.
+func (stack *Stack) PopString() (string, bool) {
+	v, stackWasEmpty := stack.Pop()
+	if stackWasEmpty {
+		return "", true
+	}
+
+	return v.(string), false
+}
+
+// PopUint is the same as Pop(), but the value is parsed as a uint.  If it cannot be
+// parsed, the error is recorded, and 0 and true are returned.
+func (stack *Stack) PopUint() (uint, bool) {
+	v, stackWasEmpty := stack.PopString()
+	if stackWasEmpty {
+		return 0, true
+	}
+
+	u, err := strconv.ParseUint(v, 10, 0)
+	if err != nil {
+		stack.recordError(err)
+		return 0, true
+	}
+
+	return uint(u), false
+}
+
+// PopInt is the same as Pop(), but the value is parsed as an int.  If it cannot be
+// parsed, the error is recorded, and 0 and true are returned.
+func (stack *Stack) PopInt() (int, bool) {
+	v, stackWasEmpty := stack.PopString()
+	if stackWasEmpty {
+		return 0, true
+	}
+
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		stack.recordError(err)
+		return 0, true
+	}
+
+	return i, false
+}
+
+// PopByte is the same as Pop(), but the value is parsed as a byte, as PopUint() parses
+// a uint.  If it cannot be parsed, the error is recorded, and 0 and true are returned.
+func (stack *Stack) PopByte() (byte, bool) {
+	v, stackWasEmpty := stack.PopString()
+	if stackWasEmpty {
+		return 0, true
+	}
+
+	b, err := strconv.ParseUint(v, 10, 8)
+	if err != nil {
+		stack.recordError(err)
+		return 0, true
+	}
+
+	return byte(b), false
+}
+
+// Depth returns the number of elements on the stack.
+func (stack *Stack) Depth() uint {
+	response, err := stack.request("DEPTH", "")
+	if err != nil {
+		return 0
+	}
+
+	depth, err := strconv.ParseUint(strings.TrimPrefix(response, "DEPTH "), 10, 0)
+	if err != nil || !strings.HasPrefix(response, "DEPTH ") {
+		stack.recordError(fmt.Errorf("unexpected response (%s) from server", response))
+		return 0
+	}
+
+	return uint(depth)
+}
+
+// IsEmpty returns true if the stack is empty (i.e., the depth is 0), or false otherwise.
+func (stack *Stack) IsEmpty() bool {
+	return stack.Depth() == 0
+}
+
+// ResetToEmpty silently discards all elements on the stack.
+func (stack *Stack) ResetToEmpty() {
+	stack.request("RESET", "")
+}
+
+func (stack *Stack) requestValue(command string) (value interface{}, stackIsEmpty bool) {
+	response, err := stack.request(command, "")
+	if err != nil || response == "EMPTY" {
+		return nil, true
+	}
+
+	if !strings.HasPrefix(response, "VALUE ") {
+		stack.recordError(fmt.Errorf("unexpected response (%s) from server", response))
+		return nil, true
+	}
+
+	valueAsString, err := strconv.Unquote(strings.TrimPrefix(response, "VALUE "))
+	if err != nil {
+		stack.recordError(fmt.Errorf("unexpected response (%s) from server", response))
+		return nil, true
+	}
+
+	return valueAsString, false
+}
+
+// request sends a request to the server and returns the response.  If there is already
+// an error, or if the request fails, or if the server responds with an error, an error is
+// returned and no response is.
+func (stack *Stack) request(command string, argument string) (response string, err error) {
+	stack.mutex.Lock()
+	defer stack.mutex.Unlock()
+
+	if stack.firstError != nil {
+		return "", stack.firstError
+	}
+
+	requestLine := command + " " + stack.name
+	if argument != "" {
+		requestLine += " " + argument
+	}
+
+	if _, err := stack.connection.Write([]byte(requestLine + "\n")); err != nil {
+		stack.firstError = err
+		return "", err
+	}
+
+	response, err = stack.reader.ReadString('\n')
+	if err != nil {
+		stack.firstError = err
+		return "", err
+	}
+
+	response = strings.TrimRight(response, "\r\n")
+
+	if strings.HasPrefix(response, "ERROR ") {
+		message, unquoteErr := strconv.Unquote(strings.TrimPrefix(response, "ERROR "))
+		if unquoteErr != nil {
+			message = strings.TrimPrefix(response, "ERROR ")
+		}
+
+		stack.firstError = &ServerError{message}
+		return "", stack.firstError
+	}
+
+	return response, nil
+}
+
+func (stack *Stack) recordError(err error) {
+	stack.mutex.Lock()
+	defer stack.mutex.Unlock()
+
+	if stack.firstError == nil {
+		stack.firstError = err
+	}
+}
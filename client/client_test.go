@@ -0,0 +1,161 @@
+package client_test
+
+import (
+	"errors"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	"github.com/blorticus-go/stack/client"
+	"github.com/blorticus-go/stack/server"
+)
+
+func serveOn(t *testing.T, network, address string, stacks map[string]*stack.Stack) string {
+	s := server.New()
+	for name, st := range stacks {
+		s.Register(name, st)
+	}
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		t.Fatalf("unable to listen on (%s): %s", address, err)
+	}
+
+	go s.Serve(listener)
+	t.Cleanup(func() { s.Close() })
+
+	return listener.Addr().String()
+}
+
+func TestClientOverTCP(t *testing.T) {
+	address := serveOn(t, "tcp", "127.0.0.1:0", map[string]*stack.Stack{"s": stack.NewStack()})
+
+	s, err := client.Dial("tcp", address, "s")
+	if err != nil {
+		t.Fatalf("unexpected error on Dial: %s", err)
+	}
+	defer s.Close()
+
+	if !s.IsEmpty() {
+		t.Errorf("expected new stack to be empty")
+	}
+
+	s.Push("one")
+	s.Push([]byte("two"))
+	s.Push(3)
+	s.Push(4)
+
+	if depth := s.Depth(); depth != 4 {
+		t.Errorf("expected depth 4, got %d", depth)
+	}
+
+	if value, stackIsEmpty := s.Peek(); stackIsEmpty || value != "4" {
+		t.Errorf("expected Peek() to return (4, false), got (%v, %t)", value, stackIsEmpty)
+	}
+
+	if value, stackWasEmpty := s.PopInt(); stackWasEmpty || value != 4 {
+		t.Errorf("expected PopInt() to return (4, false), got (%d, %t)", value, stackWasEmpty)
+	}
+
+	if value, stackWasEmpty := s.PopUint(); stackWasEmpty || value != 3 {
+		t.Errorf("expected PopUint() to return (3, false), got (%d, %t)", value, stackWasEmpty)
+	}
+
+	if value, stackWasEmpty := s.PopString(); stackWasEmpty || value != "two" {
+		t.Errorf("expected PopString() to return (two, false), got (%s, %t)", value, stackWasEmpty)
+	}
+
+	if cannotPush := s.SetMaximumDepthTo(2).Push("x"); cannotPush {
+		t.Errorf("expected Push() with room to return false")
+	}
+
+	if cannotPush := s.Push("y"); !cannotPush {
+		t.Errorf("expected Push() on full stack to return true")
+	}
+
+	if cannotPush := s.RemoveMaximumDepth().Push("y"); cannotPush {
+		t.Errorf("expected Push() after RemoveMaximumDepth() to return false")
+	}
+
+	s.ResetToEmpty()
+	if _, stackWasEmpty := s.Pop(); !stackWasEmpty {
+		t.Errorf("expected Pop() after ResetToEmpty() to report empty stack")
+	}
+
+	if err := s.Err(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestClientOverUnixSocket(t *testing.T) {
+	backing := stack.NewStack()
+	address := serveOn(t, "unix", filepath.Join(t.TempDir(), "stack.sock"), map[string]*stack.Stack{"s": backing})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			s, err := client.Dial("unix", address, "s")
+			if err != nil {
+				t.Errorf("unexpected error on Dial: %s", err)
+				return
+			}
+			defer s.Close()
+
+			for j := 0; j < 50; j++ {
+				s.Push(j)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if depth := backing.Depth(); depth != 200 {
+		t.Errorf("expected depth 200, got %d", depth)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	address := serveOn(t, "tcp", "127.0.0.1:0", map[string]*stack.Stack{
+		"s":      stack.NewStack(),
+		"window": stack.NewBoundedDiscardingStack(2),
+	})
+
+	if _, err := client.Dial("tcp", address, "missing"); err == nil {
+		t.Errorf("expected error on Dial for unknown stack, got none")
+	}
+
+	if _, err := client.Dial("tcp", address, "has space"); err == nil {
+		t.Errorf("expected error on Dial for invalid name, got none")
+	}
+
+	window, _ := client.Dial("tcp", address, "window")
+	defer window.Close()
+
+	window.SetMaximumDepthTo(5)
+	var serverError *client.ServerError
+	if !errors.As(window.Err(), &serverError) {
+		t.Fatalf("expected *client.ServerError, got (%v)", window.Err())
+	}
+
+	if cannotPush := window.Push("x"); !cannotPush {
+		t.Errorf("expected Push() after an error to return true")
+	}
+
+	s, _ := client.Dial("tcp", address, "s")
+	s.Push("not a number")
+	if _, stackWasEmpty := s.PopInt(); !stackWasEmpty || s.Err() == nil {
+		t.Errorf("expected PopInt() of a non-number to record an error")
+	}
+
+	s.Close()
+	other, _ := client.Dial("tcp", address, "s")
+	other.WithAMaximumDepthOf(0)
+	if other.Err() == nil {
+		t.Errorf("expected WithAMaximumDepthOf(0) to record an error")
+	}
+	other.Close()
+}
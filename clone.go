@@ -0,0 +1,72 @@
+package stack
+
+// Clone returns a new stack that is independent of this one but has identical contents
+// and configuration, including its maximum depth, whether it is a discarding stack, any
+// discard callback and the layout of its backend.  The Backend must support Clone().
+func (stack *Stack) Clone() *Stack {
+	release := stack.holdManipulator()
+	defer release()
+
+	return newStackUsingManipulator(stack.manipulator.clone())
+}
+
+// Equal returns true if this stack and the other stack have the same depth and each
+// element of this stack is equal to the element at the same position in the other
+// stack, as determined by the function eq.  If eq is nil, elements are compared with
+// ==, which will panic if elements are not comparable.  Neither stack can be changed
+// while the comparison is made.  A stack is always equal to itself.
+func (stack *Stack) Equal(other *Stack, eq func(a, b interface{}) bool) bool {
+	if stack == other {
+		return true
+	}
+
+	if eq == nil {
+		eq = func(a, b interface{}) bool { return a == b }
+	}
+
+	release := holdManipulatorsOf(stack, other)
+	defer release()
+
+	depth := stack.manipulator.backend.Depth()
+	if depth != other.manipulator.backend.Depth() {
+		return false
+	}
+
+	for distanceFromTop := uint(0); distanceFromTop < depth; distanceFromTop++ {
+		if !eq(stack.manipulator.backend.ElementAt(distanceFromTop), other.manipulator.backend.ElementAt(distanceFromTop)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AppendFrom atomically moves all elements of the other stack onto the top of this
+// stack, leaving the other stack empty.  The elements keep their order, so the top
+// of the other stack becomes the top of this stack.  Neither stack can be changed
+// until the move is complete.  If this is a standard stack with a maximum depth and
+// the elements will not all fit, nothing is moved and true is returned.  If this is
+// a discarding stack, elements at its bottom are discarded as needed to make room,
+// and true is returned if it is full after the move.  Otherwise, false is returned.
+// Appending a stack to itself does nothing and returns false.
+func (stack *Stack) AppendFrom(other *Stack) (cannotAppendBecauseStackIsFull bool) {
+	if stack == other {
+		return false
+	}
+
+	release := holdManipulatorsOf(stack, other)
+	defer release()
+
+	target, source := stack.manipulator, other.manipulator
+
+	if !target.discardsFIFOAfterMaxSize && target.maximumStackDepth > 0 &&
+		target.backend.Depth()+source.backend.Depth() > target.maximumStackDepth {
+		return true
+	}
+
+	for source.backend.Depth() > 0 {
+		target.push(source.backend.RemoveFromBottom())
+	}
+
+	return target.discardsFIFOAfterMaxSize && target.backend.Depth() >= target.maximumStackDepth
+}
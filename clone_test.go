@@ -0,0 +1,146 @@
+package stack_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	. "github.com/onsi/gomega"
+)
+
+func TestClone(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s := stack.NewBoundedDiscardingStack(3)
+	for _, v := range []string{"first", "second", "third", "fourth"} {
+		s.Push(v)
+	}
+
+	c := s.Clone()
+	g.Expect(c.Equal(s, nil)).To(BeTrue())
+
+	s.Pop()
+	g.Expect(c.Equal(s, nil)).To(BeFalse())
+
+	for _, testCase := range []*stackOperationTestCase{
+		{testname: "Clone Initial Check", operation: "check", expectedStackDepthAfterOperation: 3},
+		{testname: "Clone Push fifth value", operation: "push", valueToPush: "fifth", expectStackToHaveBeenFull: true, expectedStackDepthAfterOperation: 3},
+		{testname: "Clone First Pop", operation: "pop", expectedPopValue: "fifth", expectStackToHaveBeenEmpty: false, expectedStackDepthAfterOperation: 2},
+		{testname: "Clone Second Pop", operation: "pop", expectedPopValue: "fourth", expectStackToHaveBeenEmpty: false, expectedStackDepthAfterOperation: 1},
+		{testname: "Clone Third Pop", operation: "pop", expectedPopValue: "third", expectStackToHaveBeenEmpty: false, expectedStackDepthAfterOperation: 0},
+	} {
+		testCase.evaluateAgainstStack(c, g)
+	}
+
+	g.Expect(s.Depth()).To(Equal(uint(2)))
+}
+
+// uncloneableBackend is a Backend whose Clone() panics, as a SpillingBackend's does when
+// it cannot read a spilled segment.
+type uncloneableBackend struct {
+	stack.Backend
+}
+
+func (backend *uncloneableBackend) Clone() stack.Backend {
+	panic("unable to clone backend")
+}
+
+func TestCloneReleasesStackWhenBackendCannotBeCloned(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s, _ := stack.New(stack.WithBackend(&uncloneableBackend{stack.NewRingBackend(4)}))
+	s.Push("a")
+
+	g.Expect(func() { s.Clone() }).To(PanicWith("unable to clone backend"))
+
+	value, _ := s.Pop()
+	g.Expect(value).To(Equal("a"))
+}
+
+func TestEqual(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	a, b := stack.NewStack(), stack.NewStack()
+	g.Expect(a.Equal(b, nil)).To(BeTrue())
+	g.Expect(a.Equal(a, nil)).To(BeTrue())
+
+	a.Push(1)
+	a.Push(2)
+	b.Push(1)
+	g.Expect(a.Equal(b, nil)).To(BeFalse())
+
+	b.Push(-2)
+	g.Expect(a.Equal(b, nil)).To(BeFalse())
+
+	absoluteValuesAreEqual := func(x, y interface{}) bool {
+		i, j := x.(int), y.(int)
+		return i == j || i == -j
+	}
+	g.Expect(a.Equal(b, absoluteValuesAreEqual)).To(BeTrue())
+}
+
+func TestAppendFrom(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s, other := stack.NewStack(), stack.NewStack()
+	s.Push("first")
+	other.Push("second")
+	other.Push("third")
+
+	g.Expect(s.AppendFrom(other)).To(BeFalse())
+	g.Expect(other.IsEmpty()).To(BeTrue())
+
+	for _, testCase := range []*stackOperationTestCase{
+		{testname: "Appended First Pop", operation: "pop", expectedPopValue: "third", expectStackToHaveBeenEmpty: false, expectedStackDepthAfterOperation: 2},
+		{testname: "Appended Second Pop", operation: "pop", expectedPopValue: "second", expectStackToHaveBeenEmpty: false, expectedStackDepthAfterOperation: 1},
+		{testname: "Appended Third Pop", operation: "pop", expectedPopValue: "first", expectStackToHaveBeenEmpty: false, expectedStackDepthAfterOperation: 0},
+	} {
+		testCase.evaluateAgainstStack(s, g)
+	}
+
+	bounded := stack.NewStack().WithAMaximumDepthOf(2)
+	bounded.Push("first")
+	other.Push("second")
+	other.Push("third")
+
+	g.Expect(bounded.AppendFrom(other)).To(BeTrue())
+	g.Expect(bounded.Depth()).To(Equal(uint(1)))
+	g.Expect(other.Depth()).To(Equal(uint(2)))
+
+	discarding := stack.NewBoundedDiscardingStack(2)
+	discarding.Push("first")
+
+	g.Expect(discarding.AppendFrom(other)).To(BeTrue())
+	g.Expect(other.IsEmpty()).To(BeTrue())
+	g.Expect(discarding.PopString()).To(Equal("third"))
+	g.Expect(discarding.PopString()).To(Equal("second"))
+	g.Expect(discarding.IsEmpty()).To(BeTrue())
+
+	g.Expect(s.AppendFrom(s)).To(BeFalse())
+}
+
+func TestConcurrentAppendFromConservesElements(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	a, b := stack.NewStack(), stack.NewStack()
+	for i := 0; i < 100; i++ {
+		a.Push(i)
+		b.Push(i)
+	}
+
+	var wg sync.WaitGroup
+	for _, pair := range [][2]*stack.Stack{{a, b}, {b, a}, {a, b}, {b, a}} {
+		wg.Add(1)
+		go func(target, source *stack.Stack) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				target.AppendFrom(source)
+				target.Equal(source, nil)
+				target.Clone()
+			}
+		}(pair[0], pair[1])
+	}
+	wg.Wait()
+
+	g.Expect(a.Depth() + b.Depth()).To(Equal(uint(200)))
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/blorticus-go/stack"
+)
+
+// savedStack is the serialized form of a stack.  A file whose name ends in .gob holds
+// it encoded with encoding/gob, and any other file holds it encoded as JSON.
+type savedStack struct {
+	MaximumDepth       uint          `json:"maximumDepth"`
+	IsDiscarding       bool          `json:"isDiscarding"`
+	ElementsFromBottom []interface{} `json:"elementsFromBottom"`
+}
+
+func fileIsGob(pathOfFile string) bool {
+	return filepath.Ext(pathOfFile) == ".gob"
+}
+
+func readSavedStack(pathOfFile string) (*savedStack, error) {
+	file, err := os.Open(pathOfFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	saved := &savedStack{}
+
+	if fileIsGob(pathOfFile) {
+		err = gob.NewDecoder(file).Decode(saved)
+	} else {
+		err = json.NewDecoder(file).Decode(saved)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("file (%s) does not contain a saved stack: %w", pathOfFile, err)
+	}
+
+	if saved.IsDiscarding && saved.MaximumDepth == 0 {
+		return nil, fmt.Errorf("file (%s) has a discarding stack without a maximum depth", pathOfFile)
+	}
+
+	return saved, nil
+}
+
+func writeSavedStack(pathOfFile string, saved *savedStack) error {
+	file, err := os.Create(pathOfFile)
+	if err != nil {
+		return err
+	}
+
+	if fileIsGob(pathOfFile) {
+		err = gob.NewEncoder(file).Encode(saved)
+	} else {
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(saved)
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// newStackFrom returns a stack with the configuration and contents of the saved stack.
+func newStackFrom(saved *savedStack) (*stack.Stack, error) {
+	if err := saved.requireElementsWithinMaximumDepth(); err != nil {
+		return nil, err
+	}
+
+	s, err := stack.New(optionsFor(saved.MaximumDepth, saved.IsDiscarding)...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, element := range saved.ElementsFromBottom {
+		s.Push(element)
+	}
+
+	return s, nil
+}
+
+// requireElementsWithinMaximumDepth returns an error if the saved stack is a standard
+// stack with more elements than its maximum depth allows.
+func (saved *savedStack) requireElementsWithinMaximumDepth() error {
+	if !saved.IsDiscarding && saved.MaximumDepth > 0 && uint(len(saved.ElementsFromBottom)) > saved.MaximumDepth {
+		return fmt.Errorf("saved stack has %d elements, which exceeds its maximum depth of %d", len(saved.ElementsFromBottom), saved.MaximumDepth)
+	}
+
+	return nil
+}
+
+// optionsFor returns the options for a stack with the maximum depth, or none if it is 0,
+// that is discarding or not.
+func optionsFor(maximumDepth uint, discardsOldest bool) []stack.Option {
+	options := []stack.Option{}
+	if maximumDepth > 0 {
+		options = append(options, stack.WithMaxDepth(maximumDepth))
+	}
+	if discardsOldest {
+		options = append(options, stack.WithDiscardOldest())
+	}
+
+	return options
+}
+
+// savedStackFrom returns the serialized form of a stack.
+func savedStackFrom(s *stack.Stack) *savedStack {
+	elementsFromTop := s.Range(0, -1)
+
+	elementsFromBottom := make([]interface{}, len(elementsFromTop))
+	for i, element := range elementsFromTop {
+		elementsFromBottom[len(elementsFromTop)-1-i] = element
+	}
+
+	return &savedStack{
+		MaximumDepth:       s.MaximumDepth(),
+		IsDiscarding:       s.IsDiscarding(),
+		ElementsFromBottom: elementsFromBottom,
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/blorticus-go/stack"
+)
+
+func TestSavedStackRoundTrip(t *testing.T) {
+	for _, nameOfFile := range []string{"saved.json", "saved.gob"} {
+		original := stack.NewBoundedDiscardingStack(3)
+		for _, value := range []string{"a", "b", "c", "d"} {
+			original.Push(value)
+		}
+
+		pathOfFile := filepath.Join(t.TempDir(), nameOfFile)
+		if err := writeSavedStack(pathOfFile, savedStackFrom(original)); err != nil {
+			t.Fatalf("[%s] unexpected error on write: %s", nameOfFile, err)
+		}
+
+		saved, err := readSavedStack(pathOfFile)
+		if err != nil {
+			t.Fatalf("[%s] unexpected error on read: %s", nameOfFile, err)
+		}
+
+		expected := &savedStack{MaximumDepth: 3, IsDiscarding: true, ElementsFromBottom: []interface{}{"b", "c", "d"}}
+		if !reflect.DeepEqual(saved, expected) {
+			t.Errorf("[%s] expected saved stack (%+v), got (%+v)", nameOfFile, expected, saved)
+		}
+
+		restored, err := newStackFrom(saved)
+		if err != nil {
+			t.Fatalf("[%s] unexpected error on newStackFrom(): %s", nameOfFile, err)
+		}
+
+		if !restored.Equal(original, nil) || !restored.IsDiscarding() || restored.MaximumDepth() != 3 {
+			t.Errorf("[%s] expected restored stack to match original", nameOfFile)
+		}
+	}
+}
+
+func TestInvalidSavedStacks(t *testing.T) {
+	for _, testCase := range []struct {
+		testName               string
+		contents               string
+		expectedErrorToContain string
+	}{
+		{"not JSON", "[", "does not contain a saved stack"},
+		{"discarding without maximum", `{"isDiscarding": true}`, "without a maximum depth"},
+		{"too many elements", `{"maximumDepth": 1, "elementsFromBottom": [1, 2]}`, "exceeds its maximum depth"},
+	} {
+		saved, err := readSavedStack(writeFile(t, "saved.json", testCase.contents))
+		if err == nil {
+			_, err = newStackFrom(saved)
+		}
+
+		if err == nil || !strings.Contains(err.Error(), testCase.expectedErrorToContain) {
+			t.Errorf("[%s] expected error containing (%s), got (%v)", testCase.testName, testCase.expectedErrorToContain, err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Command stack drives a stack interactively, or from a file of commands, for debugging
+// and for reproducing reported problems.  The stack is in memory unless -address is
+// provided, in which case it is a stack served by package server.  Run it and enter
+// help for the commands.  For example:
+//		stack -load before.json -replay steps.txt -save after.json
+//		stack -address localhost:7000 -stack jobs
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/blorticus-go/stack"
+	"github.com/blorticus-go/stack/client"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command with the provided arguments, and returns the exit status.
+func run(arguments []string, input io.Reader, output io.Writer, errorOutput io.Writer) int {
+	flags := flag.NewFlagSet("stack", flag.ContinueOnError)
+	flags.SetOutput(errorOutput)
+
+	network := flags.String("network", "tcp", "network of the server, as for net.Dial()")
+	address := flags.String("address", "", "address of a server; if empty, the stack is in memory")
+	stackName := flags.String("stack", "", "name of the stack on the server")
+	maximumDepth := flags.Uint("max", 0, "maximum depth of an in-memory stack; 0 for none")
+	discardsOldest := flags.Bool("discard", false, "make an in-memory stack discard its oldest element when full; requires -max")
+	pathOfFileToLoad := flags.String("load", "", "file from which to load the stack before running commands")
+	pathOfFileToReplay := flags.String("replay", "", "file of commands to run instead of reading commands from the input")
+	pathOfFileToSave := flags.String("save", "", "file to which to save the stack after running commands")
+
+	if err := flags.Parse(arguments); err != nil {
+		return 2
+	}
+
+	if flags.NArg() > 0 {
+		fmt.Fprintf(errorOutput, "unexpected arguments: %v\n", flags.Args())
+		return 2
+	}
+
+	var savedStackToLoad *savedStack
+	if *pathOfFileToLoad != "" {
+		saved, err := readSavedStack(*pathOfFileToLoad)
+		if err != nil {
+			fmt.Fprintln(errorOutput, err)
+			return 1
+		}
+		savedStackToLoad = saved
+	}
+
+	stackToDrive, err := targetFrom(*network, *address, *stackName, *maximumDepth, *discardsOldest, savedStackToLoad)
+	if err != nil {
+		fmt.Fprintln(errorOutput, err)
+		return 1
+	}
+
+	session := newSession(stackToDrive, output)
+
+	if savedStackToLoad != nil {
+		if err := session.stack.replaceWith(savedStackToLoad); err != nil {
+			fmt.Fprintln(errorOutput, err)
+			return 1
+		}
+	}
+
+	if *pathOfFileToReplay != "" {
+		err = session.replay(*pathOfFileToReplay)
+	} else {
+		err = session.runCommands(input, "input", inputIsTerminal(input))
+	}
+
+	if err != nil {
+		fmt.Fprintln(errorOutput, err)
+		return 1
+	}
+
+	if *pathOfFileToSave != "" {
+		if err := session.save(*pathOfFileToSave); err != nil {
+			fmt.Fprintln(errorOutput, err)
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// targetFrom returns the stack to drive.  A stack in this process has the maximum depth
+// and discarding mode of the stack to be loaded into it, if there is one.  The flags
+// need not be provided in that case, but if they are, they must match the saved stack.
+func targetFrom(network, address, stackName string, maximumDepth uint, discardsOldest bool, savedStackToLoad *savedStack) (target, error) {
+	if address != "" {
+		if maximumDepth > 0 || discardsOldest {
+			return nil, fmt.Errorf("-max and -discard apply only to an in-memory stack")
+		}
+
+		remote, err := client.Dial(network, address, stackName)
+		if err != nil {
+			return nil, err
+		}
+
+		return &remoteTarget{remote}, nil
+	}
+
+	if discardsOldest && maximumDepth == 0 {
+		return nil, fmt.Errorf("-discard requires -max")
+	}
+
+	if savedStackToLoad != nil {
+		flagsWereProvided := maximumDepth > 0 || discardsOldest
+		if flagsWereProvided && (maximumDepth != savedStackToLoad.MaximumDepth || discardsOldest != savedStackToLoad.IsDiscarding) {
+			return nil, fmt.Errorf("-max %d and -discard=%t conflict with the saved stack, which has a maximum depth of %d and discarding of %t", maximumDepth, discardsOldest, savedStackToLoad.MaximumDepth, savedStackToLoad.IsDiscarding)
+		}
+
+		maximumDepth, discardsOldest = savedStackToLoad.MaximumDepth, savedStackToLoad.IsDiscarding
+	}
+
+	s, err := stack.New(optionsFor(maximumDepth, discardsOldest)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &localTarget{s}, nil
+}
+
+// inputIsTerminal returns true if the input is a terminal, in which case the session is
+// interactive.
+func inputIsTerminal(input io.Reader) bool {
+	file, inputIsFile := input.(*os.File)
+	if !inputIsFile {
+		return false
+	}
+
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
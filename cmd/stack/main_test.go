@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	"github.com/blorticus-go/stack/server"
+)
+
+func runWith(t *testing.T, input string, arguments ...string) (exitStatus int, output string, errorOutput string) {
+	var outputBuffer, errorOutputBuffer bytes.Buffer
+	exitStatus = run(arguments, strings.NewReader(input), &outputBuffer, &errorOutputBuffer)
+	return exitStatus, outputBuffer.String(), errorOutputBuffer.String()
+}
+
+func writeFile(t *testing.T, name string, contents string) string {
+	pathOfFile := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(pathOfFile, []byte(contents), 0o600); err != nil {
+		t.Fatalf("unable to write (%s): %s", pathOfFile, err)
+	}
+	return pathOfFile
+}
+
+func TestCommandsFromInput(t *testing.T) {
+	for _, testCase := range []struct {
+		testName               string
+		arguments              []string
+		input                  string
+		expectedExitStatus     int
+		expectedOutput         string
+		expectedErrorToContain string
+	}{
+		{
+			testName:       "push, peek, pop and depth",
+			input:          "push a\npush b c\n\n# comment\npeek\ndepth\npop\npop\npop\n",
+			expectedOutput: "ok\nok\nb c\n2\nb c\na\nempty\n",
+		},
+		{
+			testName:       "list and reset",
+			input:          "push 1\npush 2\nlist\nreset\ndepth\n",
+			expectedOutput: "ok\nok\n0: 2\n1: 1\nok\n0\n",
+		},
+		{
+			testName:       "bounded",
+			arguments:      []string{"-max", "1"},
+			input:          "push 1\npush 2\nsetmax 0\npush 2\ndepth\n",
+			expectedOutput: "ok\nfull\nok\nok\n2\n",
+		},
+		{
+			testName:       "discarding",
+			arguments:      []string{"-max", "2", "-discard"},
+			input:          "push 1\npush 2\npush 3\nlist\n",
+			expectedOutput: "ok\nfull\nfull\n0: 3\n1: 2\n",
+		},
+		{
+			testName:       "quit stops commands",
+			input:          "push 1\nquit\npush 2\n",
+			expectedOutput: "ok\n",
+		},
+		{
+			testName:               "setmax on a discarding stack",
+			arguments:              []string{"-max", "2", "-discard"},
+			input:                  "push 1\nsetmax 5\npush 2\n",
+			expectedExitStatus:     1,
+			expectedOutput:         "ok\n",
+			expectedErrorToContain: "input line 2: You may not set a maximum stack depth with a discarding stack",
+		},
+		{
+			testName:               "unknown command",
+			input:                  "frob\n",
+			expectedExitStatus:     1,
+			expectedErrorToContain: "unknown command (frob)",
+		},
+		{
+			testName:               "discard without max",
+			arguments:              []string{"-discard"},
+			expectedExitStatus:     1,
+			expectedErrorToContain: "-discard requires -max",
+		},
+	} {
+		exitStatus, output, errorOutput := runWith(t, testCase.input, testCase.arguments...)
+
+		if exitStatus != testCase.expectedExitStatus {
+			t.Errorf("[%s] expected exit status (%d), got (%d), with error output (%s)", testCase.testName, testCase.expectedExitStatus, exitStatus, errorOutput)
+		}
+
+		if output != testCase.expectedOutput {
+			t.Errorf("[%s] expected output (%q), got (%q)", testCase.testName, testCase.expectedOutput, output)
+		}
+
+		if testCase.expectedErrorToContain == "" && errorOutput != "" {
+			t.Errorf("[%s] expected no error output, got (%s)", testCase.testName, errorOutput)
+		} else if !strings.Contains(errorOutput, testCase.expectedErrorToContain) {
+			t.Errorf("[%s] expected error output to contain (%s), got (%s)", testCase.testName, testCase.expectedErrorToContain, errorOutput)
+		}
+	}
+}
+
+func TestLoadReplayAndSave(t *testing.T) {
+	pathOfLoadedFile := writeFile(t, "before.json", `{"maximumDepth": 3, "elementsFromBottom": ["a", 2]}`)
+	pathOfReplayedFile := writeFile(t, "steps.txt", "# reproduce\npop\npush c\npush d\nlist\n")
+	pathOfSavedFile := filepath.Join(t.TempDir(), "after.gob")
+
+	exitStatus, output, errorOutput := runWith(t, "", "-load", pathOfLoadedFile, "-replay", pathOfReplayedFile, "-save", pathOfSavedFile)
+	if exitStatus != 0 {
+		t.Fatalf("expected exit status 0, got (%d), with error output (%s)", exitStatus, errorOutput)
+	}
+
+	if expected := "2\nok\nok\n0: d\n1: c\n2: a\n"; output != expected {
+		t.Errorf("expected output (%q), got (%q)", expected, output)
+	}
+
+	exitStatus, output, errorOutput = runWith(t, "push e\nlist\n", "-load", pathOfSavedFile)
+	if exitStatus != 0 {
+		t.Fatalf("expected exit status 0 after loading saved file, got (%d), with error output (%s)", exitStatus, errorOutput)
+	}
+
+	if expected := "full\n0: d\n1: c\n2: a\n"; output != expected {
+		t.Errorf("expected output after loading saved file (%q), got (%q)", expected, output)
+	}
+}
+
+func TestLoadWithFlags(t *testing.T) {
+	pathOfLoadedFile := writeFile(t, "before.json", `{"maximumDepth": 3, "elementsFromBottom": ["a"]}`)
+
+	exitStatus, output, errorOutput := runWith(t, "depth\n", "-max", "3", "-load", pathOfLoadedFile)
+	if exitStatus != 0 {
+		t.Fatalf("expected exit status 0 when flags match the saved stack, got (%d), with error output (%s)", exitStatus, errorOutput)
+	}
+
+	if output != "1\n" {
+		t.Errorf("expected output (%q), got (%q)", "1\n", output)
+	}
+
+	for _, arguments := range [][]string{
+		{"-max", "5"},
+		{"-max", "3", "-discard"},
+	} {
+		exitStatus, _, errorOutput = runWith(t, "depth\n", append(arguments, "-load", pathOfLoadedFile)...)
+		if exitStatus != 1 {
+			t.Errorf("expected exit status 1 for flags (%v) that conflict with the saved stack, got (%d)", arguments, exitStatus)
+		}
+
+		if !strings.Contains(errorOutput, "conflict with the saved stack") {
+			t.Errorf("expected error output for flags (%v) to contain (conflict with the saved stack), got (%s)", arguments, errorOutput)
+		}
+	}
+}
+
+func TestReplayThatFails(t *testing.T) {
+	pathOfReplayedFile := writeFile(t, "steps.txt", "push a\nsetmax x\npush b\n")
+
+	exitStatus, output, errorOutput := runWith(t, "replay "+pathOfReplayedFile+"\ndepth\n")
+	if exitStatus != 1 {
+		t.Errorf("expected exit status 1, got (%d)", exitStatus)
+	}
+
+	if output != "ok\n" {
+		t.Errorf("expected output (%q), got (%q)", "ok\n", output)
+	}
+
+	if expected := pathOfReplayedFile + " line 2: setmax requires"; !strings.Contains(errorOutput, expected) {
+		t.Errorf("expected error output to contain (%s), got (%s)", expected, errorOutput)
+	}
+}
+
+func TestLoadCommand(t *testing.T) {
+	pathOfBoundedFile := writeFile(t, "bounded.json", `{"maximumDepth": 2, "elementsFromBottom": ["a", "b"]}`)
+	pathOfDiscardingFile := writeFile(t, "discarding.json", `{"maximumDepth": 2, "isDiscarding": true, "elementsFromBottom": ["a"]}`)
+
+	exitStatus, output, errorOutput := runWith(t, "push x\nload "+pathOfBoundedFile+"\nlist\npush c\nsetmax 0\npush c\ndepth\n")
+	if exitStatus != 0 {
+		t.Fatalf("expected exit status 0, got (%d), with error output (%s)", exitStatus, errorOutput)
+	}
+
+	if expected := "ok\nok\n0: b\n1: a\nfull\nok\nok\n3\n"; output != expected {
+		t.Errorf("expected output (%q), got (%q)", expected, output)
+	}
+
+	exitStatus, output, errorOutput = runWith(t, "push x\nload "+pathOfDiscardingFile+"\n")
+	if exitStatus != 1 {
+		t.Errorf("expected exit status 1 when loading a discarding stack into a standard stack, got (%d)", exitStatus)
+	}
+
+	if !strings.Contains(errorOutput, "saved stack is discarding") {
+		t.Errorf("expected error output to contain (saved stack is discarding), got (%s)", errorOutput)
+	}
+
+	exitStatus, output, errorOutput = runWith(t, "push x\npush y\npush z\nload "+pathOfDiscardingFile+"\nlist\n", "-max", "2", "-discard")
+	if exitStatus != 0 {
+		t.Fatalf("expected exit status 0 when loading into a discarding stack, got (%d), with error output (%s)", exitStatus, errorOutput)
+	}
+
+	if expected := "ok\nfull\nfull\nok\n0: a\n"; output != expected {
+		t.Errorf("expected output (%q), got (%q)", expected, output)
+	}
+}
+
+func TestRemoteStack(t *testing.T) {
+	served := stack.NewStack()
+	served.Push("x")
+
+	s := server.New()
+	s.Register("jobs", served)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen on loopback: %s", err)
+	}
+
+	serveResult := make(chan error, 1)
+	go func() { serveResult <- s.Serve(listener) }()
+	defer func() {
+		s.Close()
+		if err := <-serveResult; !errors.Is(err, server.ErrServerClosed) {
+			t.Errorf("expected Serve() to return ErrServerClosed, got (%v)", err)
+		}
+	}()
+
+	pathOfLoadedFile := writeFile(t, "before.json", `{"elementsFromBottom": ["a", "b"]}`)
+
+	exitStatus, output, errorOutput := runWith(t, "depth\nload "+pathOfLoadedFile+"\nsetmax 2\npush c\npop\n", "-address", listener.Addr().String(), "-stack", "jobs")
+	if exitStatus != 0 {
+		t.Fatalf("expected exit status 0, got (%d), with error output (%s)", exitStatus, errorOutput)
+	}
+
+	if expected := "1\nok\nok\nfull\nb\n"; output != expected {
+		t.Errorf("expected output (%q), got (%q)", expected, output)
+	}
+
+	if value, _ := served.Peek(); value != "a" || served.Depth() != 1 {
+		t.Errorf("expected served stack to have only (a), got depth (%d) and top (%v)", served.Depth(), value)
+	}
+
+	exitStatus, _, errorOutput = runWith(t, "list\n", "-address", listener.Addr().String(), "-stack", "jobs")
+	if exitStatus != 1 || !strings.Contains(errorOutput, "cannot be listed") {
+		t.Errorf("expected list of remote stack to fail, got exit status (%d) and error output (%s)", exitStatus, errorOutput)
+	}
+
+	exitStatus, _, errorOutput = runWith(t, "", "-address", listener.Addr().String(), "-stack", "missing")
+	if exitStatus != 1 || errorOutput == "" {
+		t.Errorf("expected missing remote stack to fail, got exit status (%d) and error output (%s)", exitStatus, errorOutput)
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const helpText = `commands:
+  push <value>     push the rest of the line, as a string
+  pop              pop and print the top value
+  peek             print the top value without removing it
+  depth            print the number of values
+  list             print every value, starting with the top
+  setmax <n>       set the maximum depth, or remove it with 0
+  reset            remove every value
+  load <file>      replace the values and maximum depth with those saved in the file
+  save <file>      save the stack to the file (.gob for gob, otherwise JSON)
+  replay <file>    run the commands in the file, stopping at the first error
+  help             print this text
+  quit             exit
+`
+
+// session runs commands against a target stack, writing their results to its output.
+type session struct {
+	stack  target
+	output io.Writer
+
+	// filesBeingReplayed holds the files that are being replayed, so that a file that
+	// replays itself is reported rather than replayed forever.
+	filesBeingReplayed map[string]bool
+}
+
+func newSession(stack target, output io.Writer) *session {
+	return &session{stack: stack, output: output, filesBeingReplayed: make(map[string]bool)}
+}
+
+// runCommands runs each line of the input as a command until the input ends or a quit
+// command is run.  Blank lines and lines starting with # are ignored.  If the session is
+// interactive, a prompt is written before each line, and an error is written to the
+// output without stopping.  Otherwise, the first error stops the commands and is
+// returned, with the line number at which it happened.
+func (session *session) runCommands(input io.Reader, nameOfInput string, isInteractive bool) error {
+	scanner := bufio.NewScanner(input)
+
+	for lineNumber := 1; ; lineNumber++ {
+		if isInteractive {
+			fmt.Fprint(session.output, "stack> ")
+		}
+
+		if !scanner.Scan() {
+			break
+		}
+
+		quit, err := session.execute(scanner.Text())
+		if err != nil {
+			if !isInteractive {
+				return fmt.Errorf("%s line %d: %w", nameOfInput, lineNumber, err)
+			}
+			fmt.Fprintf(session.output, "error: %s\n", err)
+		}
+
+		if quit {
+			return nil
+		}
+	}
+
+	return scanner.Err()
+}
+
+// execute runs a single command.  It returns true for quit if the command asks for the
+// session to end.
+func (session *session) execute(line string) (quit bool, err error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return false, nil
+	}
+
+	command, argument, _ := strings.Cut(line, " ")
+	argument = strings.TrimSpace(argument)
+
+	if err := session.stack.err(); err != nil {
+		return true, err
+	}
+
+	switch strings.ToLower(command) {
+	case "push":
+		if argument == "" {
+			return false, fmt.Errorf("push requires a value")
+		}
+
+		if session.stack.Push(argument) {
+			session.writeLine("full")
+		} else {
+			session.writeLine("ok")
+		}
+
+	case "pop":
+		session.writeValue(session.stack.Pop())
+
+	case "peek":
+		session.writeValue(session.stack.Peek())
+
+	case "depth":
+		session.writeLine(strconv.FormatUint(uint64(session.stack.Depth()), 10))
+
+	case "list":
+		elements, err := session.stack.elementsFromTop()
+		if err != nil {
+			return false, err
+		}
+
+		for i, element := range elements {
+			session.writeLine(fmt.Sprintf("%d: %v", i, element))
+		}
+
+	case "setmax":
+		maximumDepth, err := strconv.ParseUint(argument, 10, 0)
+		if err != nil {
+			return false, fmt.Errorf("setmax requires a non-negative integer")
+		}
+
+		if err := session.stack.setMaximumDepth(uint(maximumDepth)); err != nil {
+			return false, err
+		}
+		session.writeLine("ok")
+
+	case "reset":
+		session.stack.ResetToEmpty()
+		session.writeLine("ok")
+
+	case "load":
+		if err := session.load(argument); err != nil {
+			return false, err
+		}
+		session.writeLine("ok")
+
+	case "save":
+		if err := session.save(argument); err != nil {
+			return false, err
+		}
+		session.writeLine("ok")
+
+	case "replay":
+		if err := session.replay(argument); err != nil {
+			return false, err
+		}
+
+	case "help":
+		fmt.Fprint(session.output, helpText)
+
+	case "quit", "exit":
+		return true, nil
+
+	default:
+		return false, fmt.Errorf("unknown command (%s); try help", command)
+	}
+
+	return false, session.stack.err()
+}
+
+func (session *session) load(pathOfFile string) error {
+	if pathOfFile == "" {
+		return fmt.Errorf("load requires a file name")
+	}
+
+	saved, err := readSavedStack(pathOfFile)
+	if err != nil {
+		return err
+	}
+
+	return session.stack.replaceWith(saved)
+}
+
+func (session *session) save(pathOfFile string) error {
+	if pathOfFile == "" {
+		return fmt.Errorf("save requires a file name")
+	}
+
+	saved, err := session.stack.save()
+	if err != nil {
+		return err
+	}
+
+	return writeSavedStack(pathOfFile, saved)
+}
+
+func (session *session) replay(pathOfFile string) error {
+	if pathOfFile == "" {
+		return fmt.Errorf("replay requires a file name")
+	}
+
+	if session.filesBeingReplayed[pathOfFile] {
+		return fmt.Errorf("file (%s) is already being replayed", pathOfFile)
+	}
+
+	file, err := os.Open(pathOfFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	session.filesBeingReplayed[pathOfFile] = true
+	defer delete(session.filesBeingReplayed, pathOfFile)
+
+	return session.runCommands(file, pathOfFile, false)
+}
+
+func (session *session) writeValue(value interface{}, stackIsEmpty bool) {
+	if stackIsEmpty {
+		session.writeLine("empty")
+	} else {
+		session.writeLine(fmt.Sprint(value))
+	}
+}
+
+func (session *session) writeLine(line string) {
+	fmt.Fprintln(session.output, line)
+}
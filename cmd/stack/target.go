@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/blorticus-go/stack"
+	"github.com/blorticus-go/stack/client"
+)
+
+// target is the stack that a session operates on, which is either a stack in this
+// process or a stack on a server.
+type target interface {
+	Push(value interface{}) (cannotPushBecauseStackIsFull bool)
+	Pop() (value interface{}, stackWasEmptyBeforePop bool)
+	Peek() (value interface{}, stackIsEmpty bool)
+	Depth() uint
+	ResetToEmpty()
+
+	// setMaximumDepth sets the maximum depth, or removes it if maximumDepth is 0.
+	setMaximumDepth(maximumDepth uint) error
+
+	// elementsFromTop returns every element, starting with the top.
+	elementsFromTop() ([]interface{}, error)
+
+	// replaceWith replaces the contents of the stack, and for a stack in this process its
+	// maximum depth, with those of the saved stack.
+	replaceWith(saved *savedStack) error
+
+	// save returns the serialized form of the stack.
+	save() (*savedStack, error)
+
+	// err returns an error that prevents further operations, or nil if there is none.
+	err() error
+}
+
+type localTarget struct {
+	*stack.Stack
+}
+
+func (local *localTarget) setMaximumDepth(maximumDepth uint) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	if maximumDepth == 0 {
+		local.RemoveMaximumDepth()
+	} else {
+		local.SetMaximumDepthTo(maximumDepth)
+	}
+
+	return nil
+}
+
+func (local *localTarget) elementsFromTop() ([]interface{}, error) {
+	return local.Range(0, -1), nil
+}
+
+// replaceWith empties the stack and pushes the saved elements onto it, after giving it
+// the saved maximum depth.  Whether a stack is discarding cannot be changed, and neither
+// can the maximum depth of a discarding stack, so a saved stack that differs in either
+// is an error, and the stack is left unchanged.
+func (local *localTarget) replaceWith(saved *savedStack) error {
+	switch {
+	case saved.IsDiscarding && !local.IsDiscarding():
+		return fmt.Errorf("saved stack is discarding, but the stack is not; start with -discard to load it")
+	case !saved.IsDiscarding && local.IsDiscarding():
+		return fmt.Errorf("saved stack is not discarding, but the stack is")
+	case saved.IsDiscarding && saved.MaximumDepth != local.MaximumDepth():
+		return fmt.Errorf("saved stack has a maximum depth of %d, but the maximum depth of a discarding stack cannot be changed from %d", saved.MaximumDepth, local.MaximumDepth())
+	}
+
+	if err := saved.requireElementsWithinMaximumDepth(); err != nil {
+		return err
+	}
+
+	local.ResetToEmpty()
+
+	if !saved.IsDiscarding {
+		if err := local.setMaximumDepth(saved.MaximumDepth); err != nil {
+			return err
+		}
+	}
+
+	for _, element := range saved.ElementsFromBottom {
+		local.Push(element)
+	}
+
+	return nil
+}
+
+func (local *localTarget) save() (*savedStack, error) {
+	return savedStackFrom(local.Stack), nil
+}
+
+func (local *localTarget) err() error {
+	return nil
+}
+
+// remoteTarget is a stack served by package server.  The server's protocol cannot list
+// the elements of a stack or report whether it is discarding, so a remote stack cannot be
+// listed or saved, and loading a file replaces only its elements.
+type remoteTarget struct {
+	*client.Stack
+}
+
+func (remote *remoteTarget) setMaximumDepth(maximumDepth uint) error {
+	if maximumDepth == 0 {
+		remote.RemoveMaximumDepth()
+	} else {
+		remote.SetMaximumDepthTo(maximumDepth)
+	}
+
+	return remote.Err()
+}
+
+func (remote *remoteTarget) elementsFromTop() ([]interface{}, error) {
+	return nil, fmt.Errorf("the elements of a remote stack cannot be listed")
+}
+
+func (remote *remoteTarget) replaceWith(saved *savedStack) error {
+	remote.ResetToEmpty()
+
+	for _, element := range saved.ElementsFromBottom {
+		remote.Push(element)
+	}
+
+	if depth := remote.Depth(); remote.Err() == nil && depth != uint(len(saved.ElementsFromBottom)) {
+		return fmt.Errorf("remote stack holds %d of the %d saved elements", depth, len(saved.ElementsFromBottom))
+	}
+
+	return remote.Err()
+}
+
+func (remote *remoteTarget) save() (*savedStack, error) {
+	return nil, fmt.Errorf("a remote stack cannot be saved")
+}
+
+func (remote *remoteTarget) err() error {
+	return remote.Err()
+}
@@ -0,0 +1,146 @@
+package stack
+
+import "reflect"
+
+// PopIf removes the value from the top of the stack and returns it, but only if the
+// provided condition returns true for that value.  The condition is evaluated and the
+// value popped as a single operation, so no other operation can change the stack in
+// between.  If the stack was empty, the condition is not evaluated, and PopIf returns
+// an undefined value, false and true.  If the condition was not satisfied, PopIf returns
+// the value at the top of the stack, which remains there, false and false.  Otherwise,
+// it returns the popped value, true and false.  The condition must not operate on
+// the stack.  If it panics, the panic is raised again in the caller of PopIf.
+func (stack *Stack) PopIf(condition func(top interface{}) bool) (value interface{}, valueWasPopped bool, stackWasEmptyBeforePop bool) {
+	responseChannel := make(chan *stackManipulationResponse)
+	stack.send(&stackManipulationMessage{
+		operation:       popIf,
+		popCondition:    condition,
+		responseChannel: responseChannel,
+	})
+
+	response := stack.receive(responseChannel)
+
+	return response.poppedValueOrCurrentDepth, response.conditionWasSatisfied, response.stackIsEmptyOrFullBeforeOperation
+}
+
+// PushIf pushes a value to the top of the stack, but only if the provided condition
+// returns true.  The condition is passed the current depth of the stack and the value
+// at the top of the stack (which is nil if the stack is empty).  The condition is
+// evaluated and the value pushed as a single operation, so no other operation can change
+// the stack in between.  If the condition was not satisfied, PushIf returns false and
+// false.  Otherwise, the value is pushed as it would be by Push(), and PushIf returns
+// true and the value that Push() would return.  The condition must not operate on the
+// stack.  If it panics, the panic is raised again in the caller of PushIf.
+func (stack *Stack) PushIf(condition func(depth uint, top interface{}) bool, value interface{}) (conditionWasSatisfied bool, cannotPushBecauseStackIsFull bool) {
+	responseChannel := make(chan *stackManipulationResponse)
+	stack.send(&stackManipulationMessage{
+		operation:       pushIf,
+		pushCondition:   condition,
+		valueToPush:     value,
+		responseChannel: responseChannel,
+	})
+
+	response := stack.receive(responseChannel)
+
+	return response.conditionWasSatisfied, response.stackIsEmptyOrFullBeforeOperation
+}
+
+// PushIfDepthBelow pushes a value to the top of the stack, but only if the stack
+// currently has fewer than the specified number of elements.  It returns true if
+// the value was pushed, or false otherwise.
+func (stack *Stack) PushIfDepthBelow(depthLimit uint, value interface{}) (valueWasPushed bool) {
+	conditionWasSatisfied, cannotPushBecauseStackIsFull := stack.PushIf(func(depth uint, _ interface{}) bool {
+		return depth < depthLimit
+	}, value)
+
+	return conditionWasSatisfied && !cannotPushBecauseStackIsFull
+}
+
+// CompareAndSwapTop replaces the value at the top of the stack with newValue, but only
+// if the value at the top of the stack is equal to oldValue.  Values are compared
+// with ==, and values that are not comparable are never equal.  The comparison and
+// the replacement are made as a single operation, so no other operation can change
+// the stack in between.  It returns true if the value was replaced, or false if it
+// was not (including because the stack was empty).
+func (stack *Stack) CompareAndSwapTop(oldValue interface{}, newValue interface{}) (valueWasSwapped bool) {
+	responseChannel := make(chan *stackManipulationResponse)
+	stack.send(&stackManipulationMessage{
+		operation:       compareAndSwapTop,
+		valueToCompare:  oldValue,
+		valueToPush:     newValue,
+		responseChannel: responseChannel,
+	})
+
+	response := stack.receive(responseChannel)
+
+	return response.conditionWasSatisfied
+}
+
+func (manipulator *stackManipulator) popIf(condition func(top interface{}) bool) *stackManipulationResponse {
+	if manipulator.backend.Depth() == 0 {
+		return &stackManipulationResponse{stackIsEmptyOrFullBeforeOperation: true}
+	}
+
+	top := manipulator.backend.ElementAt(0)
+
+	if !condition(top) {
+		return &stackManipulationResponse{poppedValueOrCurrentDepth: top}
+	}
+
+	return &stackManipulationResponse{
+		poppedValueOrCurrentDepth: manipulator.backend.PopFromTop(),
+		conditionWasSatisfied:     true,
+	}
+}
+
+func (manipulator *stackManipulator) pushIf(condition func(depth uint, top interface{}) bool, value interface{}) *stackManipulationResponse {
+	depth := manipulator.backend.Depth()
+
+	var top interface{}
+	if depth > 0 {
+		top = manipulator.backend.ElementAt(0)
+	}
+
+	if !condition(depth, top) {
+		return &stackManipulationResponse{}
+	}
+
+	return &stackManipulationResponse{
+		stackIsEmptyOrFullBeforeOperation: manipulator.push(value),
+		conditionWasSatisfied:             true,
+	}
+}
+
+func (manipulator *stackManipulator) compareAndSwapTop(oldValue interface{}, newValue interface{}) *stackManipulationResponse {
+	if manipulator.backend.Depth() == 0 || !valuesAreEqual(manipulator.backend.ElementAt(0), oldValue) {
+		return &stackManipulationResponse{}
+	}
+
+	manipulator.backend.PopFromTop()
+	manipulator.backend.PushOnTop(newValue)
+
+	return &stackManipulationResponse{conditionWasSatisfied: true}
+}
+
+// valuesAreEqual compares two values with ==, but returns false rather than panicking
+// if the values are not comparable.
+func valuesAreEqual(a interface{}, b interface{}) (areEqual bool) {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	typeOfA := reflect.TypeOf(a)
+	if typeOfA != reflect.TypeOf(b) || !typeOfA.Comparable() {
+		return false
+	}
+
+	// a comparable struct or array may still contain an interface holding an
+	// uncomparable value
+	defer func() {
+		if recover() != nil {
+			areEqual = false
+		}
+	}()
+
+	return a == b
+}
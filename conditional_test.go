@@ -0,0 +1,123 @@
+package stack_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	. "github.com/onsi/gomega"
+)
+
+func TestPopIf(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s := stack.NewStack()
+	isFirst := func(top interface{}) bool { return top == "first" }
+
+	value, valueWasPopped, stackWasEmpty := s.PopIf(isFirst)
+	g.Expect(value).To(BeNil())
+	g.Expect(valueWasPopped).To(BeFalse())
+	g.Expect(stackWasEmpty).To(BeTrue())
+
+	s.Push("first")
+	s.Push("second")
+
+	value, valueWasPopped, stackWasEmpty = s.PopIf(isFirst)
+	g.Expect(value).To(Equal("second"))
+	g.Expect(valueWasPopped).To(BeFalse())
+	g.Expect(stackWasEmpty).To(BeFalse())
+	g.Expect(s.Depth()).To(Equal(uint(2)))
+
+	s.Pop()
+
+	value, valueWasPopped, stackWasEmpty = s.PopIf(isFirst)
+	g.Expect(value).To(Equal("first"))
+	g.Expect(valueWasPopped).To(BeTrue())
+	g.Expect(stackWasEmpty).To(BeFalse())
+	g.Expect(s.IsEmpty()).To(BeTrue())
+}
+
+func TestPushIf(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s := stack.NewStack().WithAMaximumDepthOf(3)
+
+	var observedDepth uint
+	var observedTop interface{}
+	conditionWasSatisfied, stackWasFull := s.PushIf(func(depth uint, top interface{}) bool {
+		observedDepth, observedTop = depth, top
+		return true
+	}, "first")
+	g.Expect(conditionWasSatisfied).To(BeTrue())
+	g.Expect(stackWasFull).To(BeFalse())
+	g.Expect(observedDepth).To(Equal(uint(0)))
+	g.Expect(observedTop).To(BeNil())
+
+	conditionWasSatisfied, stackWasFull = s.PushIf(func(depth uint, top interface{}) bool {
+		return top != "first"
+	}, "second")
+	g.Expect(conditionWasSatisfied).To(BeFalse())
+	g.Expect(stackWasFull).To(BeFalse())
+	g.Expect(s.Depth()).To(Equal(uint(1)))
+
+	g.Expect(s.PushIfDepthBelow(2, "second")).To(BeTrue())
+	g.Expect(s.PushIfDepthBelow(2, "third")).To(BeFalse())
+	g.Expect(s.PushIfDepthBelow(10, "third")).To(BeTrue())
+	g.Expect(s.PushIfDepthBelow(10, "fourth")).To(BeFalse())
+	g.Expect(s.Depth()).To(Equal(uint(3)))
+}
+
+func TestCompareAndSwapTop(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s := stack.NewStack()
+	g.Expect(s.CompareAndSwapTop(nil, "first")).To(BeFalse())
+
+	s.Push("first")
+	g.Expect(s.CompareAndSwapTop("second", "third")).To(BeFalse())
+	g.Expect(s.CompareAndSwapTop("first", "second")).To(BeTrue())
+	g.Expect(s.PopString()).To(Equal("second"))
+
+	s.Push([]int{1})
+	g.Expect(s.CompareAndSwapTop([]int{1}, "second")).To(BeFalse())
+	g.Expect(s.Depth()).To(Equal(uint(1)))
+}
+
+func TestConditionPanicIsRaisedInCaller(t *testing.T) {
+	s := stack.NewStack()
+	s.Push("first")
+
+	f := func() { s.PopIf(func(interface{}) bool { panic("in condition") }) }
+	if functionDidPanic := testForPanic(f); !functionDidPanic {
+		t.Errorf("On PopIf with panicking condition expected panic, did not panic")
+	}
+
+	f = func() { s.PushIf(func(uint, interface{}) bool { panic("in condition") }, "second") }
+	if functionDidPanic := testForPanic(f); !functionDidPanic {
+		t.Errorf("On PushIf with panicking condition expected panic, did not panic")
+	}
+
+	if s.Depth() != 1 {
+		t.Errorf("After panicking conditions expected depth 1, got %d", s.Depth())
+	}
+}
+
+func TestConcurrentPushIfDepthBelowNeverExceedsLimit(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s := stack.NewStack()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s.PushIfDepthBelow(50, j)
+			}
+		}()
+	}
+	wg.Wait()
+
+	g.Expect(s.Depth()).To(Equal(uint(50)))
+}
@@ -0,0 +1,178 @@
+package stack
+
+import (
+	"sync/atomic"
+)
+
+// Deque is a work-stealing double-ended queue of arbitrary, untyped values, following
+// the design of Chase and Lev.  It has a single owner goroutine, which uses Push() and
+// Pop() to add and remove values at the bottom of the deque in LIFO order.  Any number
+// of other goroutines, called thieves, may concurrently use Steal() to remove values
+// from the top of the deque in FIFO order.  Only the owner may call Push() and Pop(),
+// and the owner may also call Steal().  Operations do not take locks, so a thief never
+// delays the owner except when both contend for the last value in the deque.
+type Deque struct {
+	indexOfTop    int64
+	indexOfBottom int64
+	buffer        atomic.Value
+	isBounded     bool
+}
+
+type dequeBuffer struct {
+	slots []atomic.Value
+}
+
+type dequeSlot struct {
+	value interface{}
+}
+
+// NewDeque returns an empty deque with storage initially allocated for the specified
+// number of elements.  When the storage is full, Push() grows it, so the deque is
+// unbounded.
+func NewDeque(initialCapacity uint) *Deque {
+	if initialCapacity < 1 {
+		initialCapacity = 1
+	}
+
+	deque := &Deque{}
+	deque.buffer.Store(newDequeBuffer(int64(initialCapacity)))
+
+	return deque
+}
+
+// NewBoundedDeque returns an empty deque which can contain no more than the specified
+// number of elements.  When the deque contains that number of elements, Push() discards
+// the pushed value and indicates that the deque was full.  This will panic if the maximum
+// number of elements is zero.
+func NewBoundedDeque(maximumNumberOfAllowedElements uint) *Deque {
+	if maximumNumberOfAllowedElements < 1 {
+		panic("deque size must be at least 1")
+	}
+
+	deque := NewDeque(maximumNumberOfAllowedElements)
+	deque.isBounded = true
+
+	return deque
+}
+
+// Push adds a value to the bottom of the deque.  It may only be called by the owner.
+// If this is a bounded deque and it was full before the Push(), the value is discarded
+// and true is returned.  Otherwise, the value is added and false is returned.
+func (deque *Deque) Push(value interface{}) (cannotPushBecauseDequeIsFull bool) {
+	bottom := atomic.LoadInt64(&deque.indexOfBottom)
+	top := atomic.LoadInt64(&deque.indexOfTop)
+	buffer := deque.buffer.Load().(*dequeBuffer)
+
+	if bottom-top >= buffer.capacity() {
+		if deque.isBounded {
+			return true
+		}
+
+		buffer = buffer.grownToHoldElementsBetween(top, bottom)
+		deque.buffer.Store(buffer)
+	}
+
+	buffer.put(bottom, value)
+	atomic.StoreInt64(&deque.indexOfBottom, bottom+1)
+
+	return false
+}
+
+// Pop removes the value at the bottom of the deque, which is the value most recently
+// pushed by the owner and not yet removed, and returns it.  It may only be called by
+// the owner.  If the deque was empty before the operation (including because a thief
+// stole the last value), Pop returns an undefined value and true.  Otherwise, it returns
+// the popped value and false.
+func (deque *Deque) Pop() (value interface{}, dequeWasEmptyBeforePop bool) {
+	bottom := atomic.LoadInt64(&deque.indexOfBottom) - 1
+	buffer := deque.buffer.Load().(*dequeBuffer)
+	atomic.StoreInt64(&deque.indexOfBottom, bottom)
+
+	top := atomic.LoadInt64(&deque.indexOfTop)
+
+	if top > bottom {
+		atomic.StoreInt64(&deque.indexOfBottom, bottom+1)
+		return nil, true
+	}
+
+	value = buffer.get(bottom)
+
+	if top < bottom {
+		buffer.put(bottom, nil)
+		return value, false
+	}
+
+	// this is the last value, so a thief may be racing for it
+	wonRace := atomic.CompareAndSwapInt64(&deque.indexOfTop, top, top+1)
+	atomic.StoreInt64(&deque.indexOfBottom, bottom+1)
+
+	if !wonRace {
+		return nil, true
+	}
+
+	return value, false
+}
+
+// Steal removes the value at the top of the deque, which is the oldest value in the
+// deque, and returns it.  It may be called by any goroutine.  If the deque was empty
+// before the operation, Steal returns an undefined value and true.  Otherwise, it
+// returns the stolen value and false.
+func (deque *Deque) Steal() (value interface{}, dequeWasEmptyBeforeSteal bool) {
+	for {
+		top := atomic.LoadInt64(&deque.indexOfTop)
+		bottom := atomic.LoadInt64(&deque.indexOfBottom)
+
+		if top >= bottom {
+			return nil, true
+		}
+
+		value = deque.buffer.Load().(*dequeBuffer).get(top)
+
+		if atomic.CompareAndSwapInt64(&deque.indexOfTop, top, top+1) {
+			return value, false
+		}
+	}
+}
+
+// Depth returns the number of values currently in the deque.  When thieves or the owner
+// are concurrently operating on the deque, the result is approximate.
+func (deque *Deque) Depth() uint {
+	bottom := atomic.LoadInt64(&deque.indexOfBottom)
+	top := atomic.LoadInt64(&deque.indexOfTop)
+
+	if bottom <= top {
+		return 0
+	}
+
+	return uint(bottom - top)
+}
+
+// IsEmpty returns true if the deque is empty (i.e., the depth is 0), or false otherwise.
+func (deque *Deque) IsEmpty() bool {
+	return deque.Depth() == 0
+}
+
+func newDequeBuffer(capacity int64) *dequeBuffer {
+	return &dequeBuffer{slots: make([]atomic.Value, capacity)}
+}
+
+func (buffer *dequeBuffer) capacity() int64 {
+	return int64(len(buffer.slots))
+}
+
+func (buffer *dequeBuffer) put(index int64, value interface{}) {
+	buffer.slots[index%buffer.capacity()].Store(dequeSlot{value})
+}
+
+func (buffer *dequeBuffer) get(index int64) interface{} {
+	return buffer.slots[index%buffer.capacity()].Load().(dequeSlot).value
+}
+
+func (buffer *dequeBuffer) grownToHoldElementsBetween(top int64, bottom int64) *dequeBuffer {
+	grownBuffer := newDequeBuffer(2 * buffer.capacity())
+	for index := top; index < bottom; index++ {
+		grownBuffer.put(index, buffer.get(index))
+	}
+
+	return grownBuffer
+}
@@ -0,0 +1,150 @@
+package stack_test
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	. "github.com/onsi/gomega"
+)
+
+func TestDequeOwnerIsLIFOAndThiefIsFIFO(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	d := stack.NewDeque(2)
+
+	for i := 0; i < 6; i++ {
+		g.Expect(d.Push(i)).To(BeFalse())
+	}
+	g.Expect(d.Depth()).To(Equal(uint(6)))
+
+	for _, testCase := range []struct {
+		operation     string
+		expectedValue int
+	}{
+		{"pop", 5},
+		{"steal", 0},
+		{"pop", 4},
+		{"steal", 1},
+		{"steal", 2},
+		{"pop", 3},
+	} {
+		var value interface{}
+		var dequeWasEmpty bool
+		if testCase.operation == "pop" {
+			value, dequeWasEmpty = d.Pop()
+		} else {
+			value, dequeWasEmpty = d.Steal()
+		}
+
+		g.Expect(dequeWasEmpty).To(BeFalse(), testCase.operation)
+		g.Expect(value).To(Equal(testCase.expectedValue), testCase.operation)
+	}
+
+	g.Expect(d.IsEmpty()).To(BeTrue())
+
+	_, dequeWasEmpty := d.Pop()
+	g.Expect(dequeWasEmpty).To(BeTrue())
+
+	_, dequeWasEmpty = d.Steal()
+	g.Expect(dequeWasEmpty).To(BeTrue())
+}
+
+func TestBoundedDeque(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	d := stack.NewBoundedDeque(2)
+	g.Expect(d.Push(1)).To(BeFalse())
+	g.Expect(d.Push(2)).To(BeFalse())
+	g.Expect(d.Push(3)).To(BeTrue())
+	g.Expect(d.Depth()).To(Equal(uint(2)))
+
+	d.Steal()
+	g.Expect(d.Push(3)).To(BeFalse())
+
+	value, _ := d.Pop()
+	g.Expect(value).To(Equal(3))
+	value, _ = d.Pop()
+	g.Expect(value).To(Equal(2))
+
+	if functionDidPanic := testForPanic(func() { stack.NewBoundedDeque(0) }); !functionDidPanic {
+		t.Errorf("On NewBoundedDeque 0 expected panic, did not panic")
+	}
+}
+
+func stressDeque(t *testing.T, d *stack.Deque, numberOfThieves int, numberOfValues int) {
+	g := NewGomegaWithT(t)
+
+	receivedValues := make(chan int, numberOfValues)
+	ownerIsDone := make(chan struct{})
+
+	var thieves sync.WaitGroup
+	for i := 0; i < numberOfThieves; i++ {
+		thieves.Add(1)
+		go func() {
+			defer thieves.Done()
+			for {
+				if value, dequeWasEmpty := d.Steal(); !dequeWasEmpty {
+					receivedValues <- value.(int)
+					continue
+				}
+
+				select {
+				case <-ownerIsDone:
+					return
+				default:
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < numberOfValues; {
+		if dequeWasFull := d.Push(i); !dequeWasFull {
+			i++
+		} else {
+			runtime.Gosched()
+		}
+
+		if i%3 == 0 {
+			if value, dequeWasEmpty := d.Pop(); !dequeWasEmpty {
+				receivedValues <- value.(int)
+			}
+		}
+	}
+
+	for {
+		value, dequeWasEmpty := d.Pop()
+		if dequeWasEmpty {
+			break
+		}
+		receivedValues <- value.(int)
+	}
+
+	close(ownerIsDone)
+	thieves.Wait()
+	close(receivedValues)
+
+	seen := make([]bool, numberOfValues)
+	count := 0
+	for value := range receivedValues {
+		g.Expect(seen[value]).To(BeFalse(), "value %d received more than once", value)
+		seen[value] = true
+		count++
+	}
+
+	g.Expect(count).To(Equal(numberOfValues))
+}
+
+func TestDequeStressGrowable(t *testing.T) {
+	stressDeque(t, stack.NewDeque(1), 4, 20000)
+}
+
+func TestDequeStressBounded(t *testing.T) {
+	stressDeque(t, stack.NewBoundedDeque(8), 4, 20000)
+}
+
+func TestDequeStressContendedLastElement(t *testing.T) {
+	stressDeque(t, stack.NewBoundedDeque(1), 8, 5000)
+}
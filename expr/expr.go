@@ -0,0 +1,446 @@
+// Package expr evaluates small arithmetic expressions.  An infix expression is split
+// into tokens, converted to Reverse Polish Notation (RPN) with Dijkstra's shunting-yard
+// algorithm, and the RPN is then evaluated.  Both steps use a stack.Stack.  The set of
+// binary operators and functions is configurable, and errors report the position in
+// the expression at which they were detected.
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/blorticus-go/stack"
+)
+
+// Error describes a malformed expression or a failure to evaluate one.
+type Error struct {
+	// Position is the 1-based byte offset in the expression at which the error
+	// was detected.
+	Position int
+	Message  string
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("at position %d: %s", err.Position, err.Message)
+}
+
+// BinaryOperator defines an infix operator.  Operators with higher Precedence are
+// applied first.  Among operators of equal precedence, left-associative operators are
+// applied from left to right and right-associative operators from right to left.
+// The unary operators '-' and '+' have a precedence of UnaryOperatorPrecedence.
+type BinaryOperator struct {
+	Symbol           string
+	Precedence       int
+	RightAssociative bool
+	Apply            func(left float64, right float64) (float64, error)
+}
+
+// UnaryOperatorPrecedence is the precedence of the unary '-' and '+' operators.  It is
+// higher than the default multiplicative operators but lower than '^', so that -2^2
+// is -4.
+const UnaryOperatorPrecedence = 3
+
+// Function defines a named function.  If NumberOfArguments is negative, the function
+// accepts one or more arguments.
+type Function struct {
+	Name              string
+	NumberOfArguments int
+	Apply             func(arguments []float64) (float64, error)
+}
+
+// Evaluator converts and evaluates expressions using a set of operators and functions.
+// An Evaluator is safe for concurrent use once it has been configured.
+type Evaluator struct {
+	operators map[string]*BinaryOperator
+	functions map[string]*Function
+}
+
+// NewEvaluator returns an Evaluator with the operators + - * / % (precedence 1 and 2)
+// and ^ (precedence 4, right-associative), and the functions abs, sqrt, min and max.
+func NewEvaluator() *Evaluator {
+	evaluator := &Evaluator{
+		operators: make(map[string]*BinaryOperator),
+		functions: make(map[string]*Function),
+	}
+
+	for _, operator := range []*BinaryOperator{
+		{"+", 1, false, func(l, r float64) (float64, error) { return l + r, nil }},
+		{"-", 1, false, func(l, r float64) (float64, error) { return l - r, nil }},
+		{"*", 2, false, func(l, r float64) (float64, error) { return l * r, nil }},
+		{"/", 2, false, func(l, r float64) (float64, error) {
+			if r == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			return l / r, nil
+		}},
+		{"%", 2, false, func(l, r float64) (float64, error) {
+			if r == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			return math.Mod(l, r), nil
+		}},
+		{"^", 4, true, func(l, r float64) (float64, error) { return math.Pow(l, r), nil }},
+	} {
+		evaluator.WithOperator(operator)
+	}
+
+	for _, function := range []*Function{
+		{"abs", 1, func(a []float64) (float64, error) { return math.Abs(a[0]), nil }},
+		{"sqrt", 1, func(a []float64) (float64, error) {
+			if a[0] < 0 {
+				return 0, fmt.Errorf("square root of negative number")
+			}
+			return math.Sqrt(a[0]), nil
+		}},
+		{"min", -1, func(a []float64) (float64, error) {
+			m := a[0]
+			for _, v := range a[1:] {
+				m = math.Min(m, v)
+			}
+			return m, nil
+		}},
+		{"max", -1, func(a []float64) (float64, error) {
+			m := a[0]
+			for _, v := range a[1:] {
+				m = math.Max(m, v)
+			}
+			return m, nil
+		}},
+	} {
+		evaluator.WithFunction(function)
+	}
+
+	return evaluator
+}
+
+// WithOperator adds a binary operator, replacing any operator with the same symbol.
+func (evaluator *Evaluator) WithOperator(operator *BinaryOperator) *Evaluator {
+	evaluator.operators[operator.Symbol] = operator
+	return evaluator
+}
+
+// WithFunction adds a function, replacing any function with the same name.
+func (evaluator *Evaluator) WithFunction(function *Function) *Evaluator {
+	evaluator.functions[function.Name] = function
+	return evaluator
+}
+
+// Evaluate converts an infix expression to RPN and evaluates it, resolving identifiers
+// using the provided variables.
+func (evaluator *Evaluator) Evaluate(expression string, variables map[string]float64) (float64, error) {
+	rpn, err := evaluator.ToRPN(expression)
+	if err != nil {
+		return 0, err
+	}
+
+	return evaluator.EvaluateRPN(rpn, variables)
+}
+
+// ToRPN converts an infix expression to a sequence of tokens in RPN.  An Error is
+// returned if the expression is malformed, or if it calls an unknown function or calls
+// a function with the wrong number of arguments.
+func (evaluator *Evaluator) ToRPN(expression string) ([]*Token, error) {
+	tokens, err := evaluator.Tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	converter := &shuntingYard{
+		evaluator:                evaluator,
+		output:                   make([]*Token, 0, len(tokens)),
+		operators:                stack.NewStack(),
+		argumentCountsOfGroups:   stack.NewStack(),
+		nextTokenMustBeAnOperand: true,
+	}
+	defer converter.operators.Close()
+	defer converter.argumentCountsOfGroups.Close()
+
+	for i, token := range tokens {
+		var nextToken *Token
+		if i+1 < len(tokens) {
+			nextToken = tokens[i+1]
+		}
+
+		if err := converter.accept(token, nextToken); err != nil {
+			return nil, err
+		}
+	}
+
+	return converter.finish(len(expression) + 1)
+}
+
+// EvaluateRPN evaluates a sequence of tokens in RPN, as produced by ToRPN(), resolving
+// identifiers using the provided variables.  An Error is returned if an identifier is
+// not a variable, if an operator or function fails, or if the tokens are not valid RPN.
+func (evaluator *Evaluator) EvaluateRPN(rpn []*Token, variables map[string]float64) (float64, error) {
+	operands := stack.NewStack()
+	defer operands.Close()
+
+	for _, token := range rpn {
+		switch token.Kind {
+		case Number:
+			value, err := strconv.ParseFloat(token.Text, 64)
+			if err != nil {
+				return 0, &Error{token.Position, fmt.Sprintf("invalid number '%s'", token.Text)}
+			}
+			operands.Push(value)
+
+		case Identifier:
+			value, isDefined := variables[token.Text]
+			if !isDefined {
+				return 0, &Error{token.Position, fmt.Sprintf("undefined variable '%s'", token.Text)}
+			}
+			operands.Push(value)
+
+		case UnaryOperator:
+			value, stackWasEmpty := operands.Pop()
+			if stackWasEmpty {
+				return 0, &Error{token.Position, fmt.Sprintf("missing operand for '%s'", token.Text)}
+			}
+			if token.Text == "-" {
+				value = -value.(float64)
+			}
+			operands.Push(value)
+
+		case Operator:
+			operator, isDefined := evaluator.operators[token.Text]
+			if !isDefined {
+				return 0, &Error{token.Position, fmt.Sprintf("unknown operator '%s'", token.Text)}
+			}
+
+			arguments, err := popArguments(operands, 2, token)
+			if err != nil {
+				return 0, err
+			}
+
+			value, err := operator.Apply(arguments[0], arguments[1])
+			if err != nil {
+				return 0, &Error{token.Position, err.Error()}
+			}
+			operands.Push(value)
+
+		case FunctionCall:
+			function, isDefined := evaluator.functions[token.Text]
+			if !isDefined {
+				return 0, &Error{token.Position, fmt.Sprintf("unknown function '%s'", token.Text)}
+			}
+
+			arguments, err := popArguments(operands, token.NumberOfArguments, token)
+			if err != nil {
+				return 0, err
+			}
+
+			value, err := function.Apply(arguments)
+			if err != nil {
+				return 0, &Error{token.Position, err.Error()}
+			}
+			operands.Push(value)
+
+		default:
+			return 0, &Error{token.Position, fmt.Sprintf("unexpected '%s' in RPN", token.Text)}
+		}
+	}
+
+	if operands.Depth() != 1 {
+		position := 1
+		if len(rpn) > 0 {
+			position = rpn[len(rpn)-1].Position
+		}
+		return 0, &Error{position, fmt.Sprintf("RPN leaves %d values rather than 1", operands.Depth())}
+	}
+
+	value, _ := operands.Pop()
+
+	return value.(float64), nil
+}
+
+func popArguments(operands *stack.Stack, numberOfArguments int, token *Token) ([]float64, error) {
+	arguments := make([]float64, numberOfArguments)
+	for i := numberOfArguments - 1; i >= 0; i-- {
+		value, stackWasEmpty := operands.Pop()
+		if stackWasEmpty {
+			return nil, &Error{token.Position, fmt.Sprintf("missing operand for '%s'", token.Text)}
+		}
+		arguments[i] = value.(float64)
+	}
+
+	return arguments, nil
+}
+
+// shuntingYard holds the state of a conversion from infix to RPN.  Operators, open
+// parentheses and function calls wait on the operators stack.  For each open parenthesis
+// on that stack, the argumentCountsOfGroups stack holds the number of arguments seen so
+// far if the parenthesis opens a function call, or -1 if it merely groups.
+type shuntingYard struct {
+	evaluator                *Evaluator
+	output                   []*Token
+	operators                *stack.Stack
+	argumentCountsOfGroups   *stack.Stack
+	previousToken            *Token
+	nextTokenMustBeAnOperand bool
+}
+
+func (converter *shuntingYard) accept(token *Token, nextToken *Token) error {
+	defer func() { converter.previousToken = token }()
+
+	startsAnOperand := token.Kind == Number || token.Kind == Identifier || token.Kind == UnaryOperator || token.Kind == LeftParenthesis
+	closesEmptyArgumentList := token.Kind == RightParenthesis && converter.previousToken != nil &&
+		converter.previousToken.Kind == LeftParenthesis && converter.currentGroupIsFunctionCall()
+
+	if startsAnOperand != converter.nextTokenMustBeAnOperand && !closesEmptyArgumentList {
+		if converter.nextTokenMustBeAnOperand {
+			return &Error{token.Position, fmt.Sprintf("expected an operand but found '%s'", token.Text)}
+		}
+		return &Error{token.Position, fmt.Sprintf("expected an operator but found '%s'", token.Text)}
+	}
+
+	switch token.Kind {
+	case Number:
+		converter.output = append(converter.output, token)
+		converter.nextTokenMustBeAnOperand = false
+
+	case Identifier:
+		if nextToken != nil && nextToken.Kind == LeftParenthesis {
+			if _, isDefined := converter.evaluator.functions[token.Text]; !isDefined {
+				return &Error{token.Position, fmt.Sprintf("unknown function '%s'", token.Text)}
+			}
+			converter.operators.Push(&Token{Kind: FunctionCall, Text: token.Text, Position: token.Position})
+		} else {
+			converter.output = append(converter.output, token)
+			converter.nextTokenMustBeAnOperand = false
+		}
+
+	case UnaryOperator:
+		converter.operators.Push(token)
+
+	case Operator:
+		operator := converter.evaluator.operators[token.Text]
+		for {
+			top, wasPopped, _ := converter.operators.PopIf(func(top interface{}) bool {
+				return converter.operatorOnStackTakesPrecedenceOver(top.(*Token), operator)
+			})
+			if !wasPopped {
+				break
+			}
+			converter.output = append(converter.output, top.(*Token))
+		}
+		converter.operators.Push(token)
+		converter.nextTokenMustBeAnOperand = true
+
+	case LeftParenthesis:
+		top, _ := converter.operators.Peek()
+		if top != nil && top.(*Token).Kind == FunctionCall {
+			converter.argumentCountsOfGroups.Push(1)
+		} else {
+			converter.argumentCountsOfGroups.Push(-1)
+		}
+		converter.operators.Push(token)
+
+	case Comma:
+		if !converter.moveOperatorsToOutputUntilLeftParenthesis() || !converter.currentGroupIsFunctionCall() {
+			return &Error{token.Position, "',' outside of function call"}
+		}
+		argumentCount, _ := converter.argumentCountsOfGroups.PopInt()
+		converter.argumentCountsOfGroups.Push(argumentCount + 1)
+		converter.nextTokenMustBeAnOperand = true
+
+	case RightParenthesis:
+		if !converter.moveOperatorsToOutputUntilLeftParenthesis() {
+			return &Error{token.Position, "unmatched ')'"}
+		}
+		converter.operators.Pop()
+
+		argumentCount, _ := converter.argumentCountsOfGroups.PopInt()
+		if argumentCount >= 0 {
+			if closesEmptyArgumentList {
+				argumentCount = 0
+			}
+
+			functionCall, _ := converter.operators.Pop()
+			if err := converter.emitFunctionCall(functionCall.(*Token), argumentCount); err != nil {
+				return err
+			}
+		}
+		converter.nextTokenMustBeAnOperand = false
+	}
+
+	return nil
+}
+
+// finish moves the remaining operators to the output and returns it.  The position
+// is used to report an expression that ends while an operand is expected.
+func (converter *shuntingYard) finish(positionOfEndOfExpression int) ([]*Token, error) {
+	if converter.nextTokenMustBeAnOperand {
+		return nil, &Error{positionOfEndOfExpression, "unexpected end of expression"}
+	}
+
+	for {
+		top, stackWasEmpty := converter.operators.Pop()
+		if stackWasEmpty {
+			return converter.output, nil
+		}
+
+		if top.(*Token).Kind == LeftParenthesis {
+			return nil, &Error{top.(*Token).Position, "unmatched '('"}
+		}
+
+		converter.output = append(converter.output, top.(*Token))
+	}
+}
+
+// moveOperatorsToOutputUntilLeftParenthesis moves operators to the output until the
+// top of the operators stack is an open parenthesis, which is left on the stack.  It
+// returns false if there is no open parenthesis.
+func (converter *shuntingYard) moveOperatorsToOutputUntilLeftParenthesis() (leftParenthesisWasFound bool) {
+	for {
+		top, wasPopped, stackWasEmpty := converter.operators.PopIf(func(top interface{}) bool {
+			return top.(*Token).Kind != LeftParenthesis
+		})
+		if stackWasEmpty {
+			return false
+		}
+		if !wasPopped {
+			return true
+		}
+		converter.output = append(converter.output, top.(*Token))
+	}
+}
+
+func (converter *shuntingYard) currentGroupIsFunctionCall() bool {
+	argumentCount, stackIsEmpty := converter.argumentCountsOfGroups.Peek()
+	return !stackIsEmpty && argumentCount.(int) >= 0
+}
+
+func (converter *shuntingYard) operatorOnStackTakesPrecedenceOver(onStack *Token, incoming *BinaryOperator) bool {
+	var precedenceOfOperatorOnStack int
+
+	switch onStack.Kind {
+	case Operator:
+		precedenceOfOperatorOnStack = converter.evaluator.operators[onStack.Text].Precedence
+	case UnaryOperator:
+		precedenceOfOperatorOnStack = UnaryOperatorPrecedence
+	default:
+		return false
+	}
+
+	return precedenceOfOperatorOnStack > incoming.Precedence ||
+		(precedenceOfOperatorOnStack == incoming.Precedence && !incoming.RightAssociative)
+}
+
+func (converter *shuntingYard) emitFunctionCall(functionCall *Token, numberOfArguments int) error {
+	function := converter.evaluator.functions[functionCall.Text]
+
+	if function.NumberOfArguments >= 0 && numberOfArguments != function.NumberOfArguments {
+		return &Error{functionCall.Position, fmt.Sprintf("function '%s' takes %d arguments but was passed %d", function.Name, function.NumberOfArguments, numberOfArguments)}
+	}
+
+	if function.NumberOfArguments < 0 && numberOfArguments < 1 {
+		return &Error{functionCall.Position, fmt.Sprintf("function '%s' takes at least 1 argument", function.Name)}
+	}
+
+	functionCall.NumberOfArguments = numberOfArguments
+	converter.output = append(converter.output, functionCall)
+
+	return nil
+}
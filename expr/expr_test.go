@@ -0,0 +1,163 @@
+package expr_test
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blorticus-go/stack/expr"
+)
+
+func TestEvaluate(t *testing.T) {
+	evaluator := expr.NewEvaluator()
+	variables := map[string]float64{"x": 3, "rate_2": 0.5}
+
+	for _, testCase := range []struct {
+		expression    string
+		expectedValue float64
+	}{
+		{"1 + 2", 3},
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 - 4 - 3", 3},
+		{"2 ^ 3 ^ 2", 512},
+		{"-2 ^ 2", -4},
+		{"2 * -x", -6},
+		{"-(1 + 2)", -3},
+		{"+4", 4},
+		{"7 % 4", 3},
+		{"1.5e1 / .5", 30},
+		{"x * rate_2", 1.5},
+		{"max(1, x, 2)", 3},
+		{"min(4, abs(-2) + 1)", 3},
+		{"sqrt(16) + max(1)", 5},
+	} {
+		value, err := evaluator.Evaluate(testCase.expression, variables)
+		if err != nil {
+			t.Errorf("[%s] unexpected error: %s", testCase.expression, err)
+		} else if value != testCase.expectedValue {
+			t.Errorf("[%s] expected %v, got %v", testCase.expression, testCase.expectedValue, value)
+		}
+	}
+}
+
+func TestToRPN(t *testing.T) {
+	evaluator := expr.NewEvaluator()
+
+	for _, testCase := range []struct {
+		expression  string
+		expectedRPN string
+	}{
+		{"1 + 2 * 3", "1 2 3 * +"},
+		{"(1 + 2) * 3", "1 2 + 3 *"},
+		{"a ^ b ^ c", "a b c ^ ^"},
+		{"-a * b", "a - b *"},
+		{"max(a, b + 1) - c", "a b 1 + max/2 c -"},
+	} {
+		rpn, err := evaluator.ToRPN(testCase.expression)
+		if err != nil {
+			t.Errorf("[%s] unexpected error: %s", testCase.expression, err)
+			continue
+		}
+
+		texts := make([]string, len(rpn))
+		for i, token := range rpn {
+			texts[i] = token.Text
+			if token.Kind == expr.FunctionCall {
+				texts[i] = fmt.Sprintf("%s/%d", token.Text, token.NumberOfArguments)
+			}
+		}
+
+		if got := strings.Join(texts, " "); got != testCase.expectedRPN {
+			t.Errorf("[%s] expected RPN (%s), got (%s)", testCase.expression, testCase.expectedRPN, got)
+		}
+	}
+}
+
+func TestErrorPositions(t *testing.T) {
+	evaluator := expr.NewEvaluator()
+
+	for _, testCase := range []struct {
+		expression       string
+		expectedPosition int
+	}{
+		{"1 + $", 5},
+		{"1 +", 4},
+		{"1 2", 3},
+		{"(1 + 2", 1},
+		{"1 + 2)", 6},
+		{"1, 2", 2},
+		{"nope(1)", 1},
+		{"sqrt(1, 2)", 1},
+		{"max()", 1},
+		{"1 + y", 5},
+		{"1 / (2 - 2)", 3},
+		{"* 2", 1},
+	} {
+		_, err := evaluator.Evaluate(testCase.expression, nil)
+
+		var exprError *expr.Error
+		if !errors.As(err, &exprError) {
+			t.Errorf("[%s] expected *expr.Error, got (%v)", testCase.expression, err)
+		} else if exprError.Position != testCase.expectedPosition {
+			t.Errorf("[%s] expected error at position %d, got (%s)", testCase.expression, testCase.expectedPosition, exprError)
+		}
+	}
+}
+
+func TestPluggableOperatorsAndFunctions(t *testing.T) {
+	evaluator := expr.NewEvaluator().
+		WithOperator(&expr.BinaryOperator{Symbol: "<=", Precedence: 0, Apply: func(l, r float64) (float64, error) {
+			if l <= r {
+				return 1, nil
+			}
+			return 0, nil
+		}}).
+		WithOperator(&expr.BinaryOperator{Symbol: "<", Precedence: 0, Apply: func(l, r float64) (float64, error) {
+			if l < r {
+				return 1, nil
+			}
+			return 0, nil
+		}}).
+		WithFunction(&expr.Function{Name: "pi", NumberOfArguments: 0, Apply: func([]float64) (float64, error) { return 3, nil }})
+
+	for _, testCase := range []struct {
+		expression    string
+		expectedValue float64
+	}{
+		{"1 + 2 <= 3", 1},
+		{"1 + 2 < 3", 0},
+		{"pi() * 2", 6},
+	} {
+		value, err := evaluator.Evaluate(testCase.expression, nil)
+		if err != nil {
+			t.Errorf("[%s] unexpected error: %s", testCase.expression, err)
+		} else if value != testCase.expectedValue {
+			t.Errorf("[%s] expected %v, got %v", testCase.expression, testCase.expectedValue, value)
+		}
+	}
+}
+
+func TestEvaluateDoesNotLeaveGoroutinesRunning(t *testing.T) {
+	evaluator := expr.NewEvaluator()
+	numberOfGoroutinesBefore := runtime.NumGoroutine()
+
+	for i := 0; i < 100; i++ {
+		evaluator.Evaluate("max(1, 2) * (3 + 4)", nil)
+		evaluator.Evaluate("1 +", nil)
+	}
+
+	// A closed stack's goroutine ends shortly after Close() returns.
+	numberOfGoroutines := runtime.NumGoroutine()
+	for attempt := 0; attempt < 100 && numberOfGoroutines > numberOfGoroutinesBefore; attempt++ {
+		time.Sleep(10 * time.Millisecond)
+		numberOfGoroutines = runtime.NumGoroutine()
+	}
+
+	if numberOfGoroutines > numberOfGoroutinesBefore {
+		t.Errorf("expected no more than %d goroutines, got %d", numberOfGoroutinesBefore, numberOfGoroutines)
+	}
+}
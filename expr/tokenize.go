@@ -0,0 +1,163 @@
+package expr
+
+import (
+	"strings"
+)
+
+// TokenKind identifies the kind of a Token.
+type TokenKind int
+
+const (
+	// Number is a numeric literal, such as 3 or 2.5e-3.
+	Number TokenKind = iota
+	// Identifier is the name of a variable or, when followed by '(', a function.
+	Identifier
+	// Operator is a binary operator.
+	Operator
+	// UnaryOperator is a prefix operator.  Only '-' and '+' are unary operators.
+	UnaryOperator
+	// LeftParenthesis is '('.
+	LeftParenthesis
+	// RightParenthesis is ')'.
+	RightParenthesis
+	// Comma separates function arguments.
+	Comma
+	// FunctionCall is a function name in RPN.  It is never produced by Tokenize().
+	FunctionCall
+)
+
+// Token is a lexical element of an expression.
+type Token struct {
+	Kind TokenKind
+	Text string
+	// Position is the 1-based byte offset of the token in the expression.
+	Position int
+	// NumberOfArguments is the number of arguments passed to a FunctionCall.
+	NumberOfArguments int
+}
+
+// Tokenize splits an infix expression into tokens.  Operators are matched against the
+// operators known to the Evaluator, preferring the longest match, so multi-character
+// operators like "<=" may be used.  An Error is returned if the expression contains
+// a character that does not start a valid token.
+func (evaluator *Evaluator) Tokenize(expression string) ([]*Token, error) {
+	tokens := make([]*Token, 0, len(expression)/2)
+
+	for offset := 0; offset < len(expression); {
+		c := rune(expression[offset])
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			offset++
+			continue
+
+		case c == '(':
+			tokens = append(tokens, &Token{Kind: LeftParenthesis, Text: "(", Position: offset + 1})
+			offset++
+
+		case c == ')':
+			tokens = append(tokens, &Token{Kind: RightParenthesis, Text: ")", Position: offset + 1})
+			offset++
+
+		case c == ',':
+			tokens = append(tokens, &Token{Kind: Comma, Text: ",", Position: offset + 1})
+			offset++
+
+		case isDigit(c) || (c == '.' && offset+1 < len(expression) && isDigit(rune(expression[offset+1]))):
+			length := lengthOfNumberAt(expression[offset:])
+			tokens = append(tokens, &Token{Kind: Number, Text: expression[offset : offset+length], Position: offset + 1})
+			offset += length
+
+		case c == '_' || isLetter(c):
+			length := lengthOfIdentifierAt(expression[offset:])
+			tokens = append(tokens, &Token{Kind: Identifier, Text: expression[offset : offset+length], Position: offset + 1})
+			offset += length
+
+		default:
+			symbol := evaluator.longestOperatorSymbolAt(expression[offset:])
+			if symbol == "" {
+				return nil, &Error{Position: offset + 1, Message: "unexpected character '" + string(c) + "'"}
+			}
+
+			kind := Operator
+			if (symbol == "-" || symbol == "+") && tokenPrecedesOperand(tokens) {
+				kind = UnaryOperator
+			}
+
+			tokens = append(tokens, &Token{Kind: kind, Text: symbol, Position: offset + 1})
+			offset += len(symbol)
+		}
+	}
+
+	return tokens, nil
+}
+
+// tokenPrecedesOperand returns true if the token after the provided tokens must begin
+// an operand, so that a '-' or '+' there is a sign rather than a binary operator.
+func tokenPrecedesOperand(tokensSoFar []*Token) bool {
+	if len(tokensSoFar) == 0 {
+		return true
+	}
+
+	switch tokensSoFar[len(tokensSoFar)-1].Kind {
+	case Operator, UnaryOperator, LeftParenthesis, Comma:
+		return true
+	}
+
+	return false
+}
+
+func (evaluator *Evaluator) longestOperatorSymbolAt(remainingExpression string) string {
+	longestSymbol := ""
+	for symbol := range evaluator.operators {
+		if len(symbol) > len(longestSymbol) && strings.HasPrefix(remainingExpression, symbol) {
+			longestSymbol = symbol
+		}
+	}
+
+	return longestSymbol
+}
+
+func isLetter(c rune) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c rune) bool {
+	return c >= '0' && c <= '9'
+}
+
+func lengthOfNumberAt(remainingExpression string) int {
+	length := 0
+	for length < len(remainingExpression) && (isDigit(rune(remainingExpression[length])) || remainingExpression[length] == '.') {
+		length++
+	}
+
+	if length < len(remainingExpression) && (remainingExpression[length] == 'e' || remainingExpression[length] == 'E') {
+		exponentLength := 1
+		if length+exponentLength < len(remainingExpression) && strings.ContainsRune("+-", rune(remainingExpression[length+exponentLength])) {
+			exponentLength++
+		}
+
+		if length+exponentLength < len(remainingExpression) && isDigit(rune(remainingExpression[length+exponentLength])) {
+			length += exponentLength
+			for length < len(remainingExpression) && isDigit(rune(remainingExpression[length])) {
+				length++
+			}
+		}
+	}
+
+	return length
+}
+
+func lengthOfIdentifierAt(remainingExpression string) int {
+	length := 0
+	for length < len(remainingExpression) {
+		c := rune(remainingExpression[length])
+		if c != '_' && !isLetter(c) && !isDigit(c) {
+			break
+		}
+		length++
+	}
+
+	return length
+}
@@ -0,0 +1,250 @@
+package stack
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FrameStack is a stack divided into named frames, such as the call frames of an
+// interpreter.  Element operations (Push, Pop, Peek, Depth and so forth) apply only to
+// the current frame, which is the most recently pushed one, so that a Pop() never
+// reaches a value that belongs to an enclosing frame.  PopFrame() removes the current
+// frame and all of its values at once.  Each frame may have its own maximum depth,
+// and the FrameStack itself may have a maximum number of frames.
+//
+// A FrameStack always has at least one frame, which is created with it and which
+// cannot be popped.  All methods other than Close() may be called concurrently.  Each
+// frame is a Stack, which is closed when the frame is popped.
+type FrameStack struct {
+	frames *Stack
+}
+
+// FrameSummary describes a frame of a FrameStack, as reported by Frames().
+type FrameSummary struct {
+	Name string
+	// Depth is the number of values in the frame.
+	Depth uint
+	// MaximumDepth is the maximum number of values allowed in the frame, or 0 if
+	// there is no maximum.
+	MaximumDepth uint
+}
+
+type frame struct {
+	name     string
+	elements *Stack
+}
+
+// NewFrameStack returns a FrameStack with a single, empty frame with the provided name.
+// The options configure the elements of that frame, as they do for New().
+func NewFrameStack(nameOfOutermostFrame string, options ...Option) (*FrameStack, error) {
+	outermostFrame, err := newFrame(nameOfOutermostFrame, options)
+	if err != nil {
+		return nil, err
+	}
+
+	frames := NewStack()
+	frames.Push(outermostFrame)
+
+	return &FrameStack{frames}, nil
+}
+
+func newFrame(name string, options []Option) (*frame, error) {
+	elements, err := New(options...)
+	if err != nil {
+		return nil, fmt.Errorf("frame (%s): %w", name, err)
+	}
+
+	return &frame{name, elements}, nil
+}
+
+// WithAMaximumNumberOfFramesOf sets the maximum number of frames, including the
+// outermost frame, that the FrameStack may have.  PushFrame() returns an error rather
+// than exceed it.  The maximum must be at least 1, and it may not be less than the
+// current number of frames, or this method will panic.
+func (stack *FrameStack) WithAMaximumNumberOfFramesOf(maximumNumberOfFrames uint) *FrameStack {
+	if maximumNumberOfFrames > 0 && maximumNumberOfFrames < stack.frames.Depth() {
+		panic(fmt.Sprintf("the stack already has more than %d frames", maximumNumberOfFrames))
+	}
+
+	stack.frames.WithAMaximumDepthOf(maximumNumberOfFrames)
+	return stack
+}
+
+// PushFrame adds a new, empty frame with the provided name, which becomes the current
+// frame.  The options configure the elements of the new frame, as they do for New().
+// If the options are invalid, an error is returned.  If the FrameStack already has its
+// maximum number of frames, an error wrapping ErrStackOverflow is returned.  In either
+// case, no frame is added.
+func (stack *FrameStack) PushFrame(name string, options ...Option) error {
+	newCurrentFrame, err := newFrame(name, options)
+	if err != nil {
+		return err
+	}
+
+	if stack.frames.Push(newCurrentFrame) {
+		return fmt.Errorf("%w: cannot push frame (%s) because the maximum number of frames has been reached", ErrStackOverflow, name)
+	}
+
+	return nil
+}
+
+// PopFrame removes the current frame, discarding any values still in it, and returns
+// its name.  The frame below it becomes the current frame.  If the current frame is the
+// outermost frame, it is not removed, and PopFrame returns an undefined value and true.
+// Otherwise, it returns the name and false.
+func (stack *FrameStack) PopFrame() (name string, cannotPopBecauseOnlyOutermostFrameRemains bool) {
+	poppedFrame, frameWasPopped, _ := stack.frames.PopIf(func(interface{}) bool {
+		return stack.frames.manipulator.backend.Depth() > 1
+	})
+
+	if !frameWasPopped {
+		return "", true
+	}
+
+	poppedFrame.(*frame).elements.Close()
+
+	return poppedFrame.(*frame).name, false
+}
+
+// Close closes the stack of every frame, and the stack that holds the frames, as
+// Stack.Close() does.  After Close, the FrameStack must not be used.
+func (stack *FrameStack) Close() {
+	release := stack.frames.holdManipulator()
+
+	frames := stack.frames.manipulator.backend
+	for distanceFromTop := uint(0); distanceFromTop < frames.Depth(); distanceFromTop++ {
+		frames.ElementAt(distanceFromTop).(*frame).elements.Close()
+	}
+
+	release()
+
+	stack.frames.Close()
+}
+
+// CurrentFrameName returns the name of the current frame.
+func (stack *FrameStack) CurrentFrameName() string {
+	currentFrame, _ := stack.frames.Peek()
+	return currentFrame.(*frame).name
+}
+
+// NumberOfFrames returns the number of frames, including the outermost frame.
+func (stack *FrameStack) NumberOfFrames() uint {
+	return stack.frames.Depth()
+}
+
+// Push pushes a value onto the current frame, as Stack.Push() does, using the maximum
+// depth and discarding mode of that frame.
+func (stack *FrameStack) Push(value interface{}) (cannotPushBecauseFrameIsFull bool) {
+	release, currentFrame := stack.holdCurrentFrame()
+	defer release()
+
+	return currentFrame.elements.Push(value)
+}
+
+// Pop removes the value at the top of the current frame and returns it.  If the current
+// frame is empty, Pop returns an undefined value and true, even if an enclosing frame has
+// values.  Otherwise, it returns the value and false.
+func (stack *FrameStack) Pop() (value interface{}, frameWasEmptyBeforePop bool) {
+	release, currentFrame := stack.holdCurrentFrame()
+	defer release()
+
+	return currentFrame.elements.Pop()
+}
+
+// Peek returns the value at the top of the current frame without removing it.  If the
+// current frame is empty, Peek returns an undefined value and true.  Otherwise, it
+// returns the value and false.
+func (stack *FrameStack) Peek() (value interface{}, frameIsEmpty bool) {
+	release, currentFrame := stack.holdCurrentFrame()
+	defer release()
+
+	return currentFrame.elements.Peek()
+}
+
+// Depth returns the number of values in the current frame.
+func (stack *FrameStack) Depth() uint {
+	release, currentFrame := stack.holdCurrentFrame()
+	defer release()
+
+	return currentFrame.elements.Depth()
+}
+
+// IsEmpty returns true if the current frame has no values, or false otherwise.
+func (stack *FrameStack) IsEmpty() bool {
+	return stack.Depth() == 0
+}
+
+// ResetToEmpty silently discards all values in the current frame.  Other frames are
+// not changed.
+func (stack *FrameStack) ResetToEmpty() {
+	release, currentFrame := stack.holdCurrentFrame()
+	defer release()
+
+	currentFrame.elements.ResetToEmpty()
+}
+
+// TotalDepth returns the number of values in all frames.
+func (stack *FrameStack) TotalDepth() uint {
+	totalDepth := uint(0)
+	for _, summary := range stack.Frames() {
+		totalDepth += summary.Depth
+	}
+
+	return totalDepth
+}
+
+// Frames returns a summary of each frame, as of a single moment, starting with the
+// current frame and ending with the outermost frame.
+func (stack *FrameStack) Frames() []FrameSummary {
+	release := stack.frames.holdManipulator()
+	defer release()
+
+	frames := stack.frames.manipulator.backend
+	summaries := make([]FrameSummary, frames.Depth())
+
+	for distanceFromTop := range summaries {
+		summaries[distanceFromTop] = frames.ElementAt(uint(distanceFromTop)).(*frame).summary()
+	}
+
+	return summaries
+}
+
+// StackTrace returns a description of the frames, one per line, starting with the
+// current frame.  Each line provides the frame number, where the outermost frame is
+// frame 0, the frame name and the number of values in the frame.  For example:
+//		#2 parseTerm (1 values)
+//		#1 parseExpression (3 values, maximum 16)
+//		#0 main (0 values)
+func (stack *FrameStack) StackTrace() string {
+	summaries := stack.Frames()
+
+	var trace strings.Builder
+	for i, summary := range summaries {
+		fmt.Fprintf(&trace, "#%d %s (%d values", len(summaries)-1-i, summary.Name, summary.Depth)
+		if summary.MaximumDepth > 0 {
+			fmt.Fprintf(&trace, ", maximum %d", summary.MaximumDepth)
+		}
+		trace.WriteString(")\n")
+	}
+
+	return trace.String()
+}
+
+func (f *frame) summary() FrameSummary {
+	release := f.elements.holdManipulator()
+	defer release()
+
+	return FrameSummary{
+		Name:         f.name,
+		Depth:        f.elements.manipulator.backend.Depth(),
+		MaximumDepth: f.elements.manipulator.maximumStackDepth,
+	}
+}
+
+// holdCurrentFrame holds the manipulator of the frame stack, as holdManipulator() does,
+// so that the current frame cannot be popped until the returned release function is
+// called.
+func (stack *FrameStack) holdCurrentFrame() (release func(), currentFrame *frame) {
+	release = stack.frames.holdManipulator()
+	return release, stack.frames.manipulator.backend.ElementAt(0).(*frame)
+}
@@ -0,0 +1,139 @@
+package stack_test
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	. "github.com/onsi/gomega"
+)
+
+func TestFrameStack(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s, err := stack.NewFrameStack("main")
+	g.Expect(err).To(BeNil())
+	g.Expect(s.CurrentFrameName()).To(Equal("main"))
+	g.Expect(s.NumberOfFrames()).To(Equal(uint(1)))
+	g.Expect(s.IsEmpty()).To(BeTrue())
+
+	s.Push(1)
+	s.Push(2)
+
+	g.Expect(s.PushFrame("f")).To(Succeed())
+	g.Expect(s.CurrentFrameName()).To(Equal("f"))
+	g.Expect(s.IsEmpty()).To(BeTrue())
+
+	_, frameWasEmpty := s.Pop()
+	g.Expect(frameWasEmpty).To(BeTrue())
+	_, frameIsEmpty := s.Peek()
+	g.Expect(frameIsEmpty).To(BeTrue())
+
+	s.Push("a")
+	s.Push("b")
+	s.Push("c")
+	g.Expect(s.Depth()).To(Equal(uint(3)))
+	g.Expect(s.TotalDepth()).To(Equal(uint(5)))
+
+	value, frameWasEmpty := s.Pop()
+	g.Expect(frameWasEmpty).To(BeFalse())
+	g.Expect(value).To(Equal("c"))
+
+	g.Expect(s.PushFrame("g", stack.WithMaxDepth(1))).To(Succeed())
+	g.Expect(s.Push(true)).To(BeFalse())
+	g.Expect(s.Push(false)).To(BeTrue())
+
+	g.Expect(s.Frames()).To(Equal([]stack.FrameSummary{
+		{Name: "g", Depth: 1, MaximumDepth: 1},
+		{Name: "f", Depth: 2, MaximumDepth: 0},
+		{Name: "main", Depth: 2, MaximumDepth: 0},
+	}))
+	g.Expect(s.StackTrace()).To(Equal("#2 g (1 values, maximum 1)\n#1 f (2 values)\n#0 main (2 values)\n"))
+
+	name, cannotPop := s.PopFrame()
+	g.Expect(cannotPop).To(BeFalse())
+	g.Expect(name).To(Equal("g"))
+
+	name, cannotPop = s.PopFrame()
+	g.Expect(cannotPop).To(BeFalse())
+	g.Expect(name).To(Equal("f"))
+
+	_, cannotPop = s.PopFrame()
+	g.Expect(cannotPop).To(BeTrue())
+	g.Expect(s.CurrentFrameName()).To(Equal("main"))
+
+	value, _ = s.Pop()
+	g.Expect(value).To(Equal(2))
+
+	s.ResetToEmpty()
+	g.Expect(s.TotalDepth()).To(Equal(uint(0)))
+
+	g.Expect(s.PushFrame("bad", stack.WithDiscardOldest())).ToNot(Succeed())
+	g.Expect(s.NumberOfFrames()).To(Equal(uint(1)))
+
+	_, err = stack.NewFrameStack("main", stack.WithDiscardOldest())
+	g.Expect(err).ToNot(BeNil())
+}
+
+func TestFrameStackClosesPoppedFrames(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	numberOfGoroutinesBefore := runtime.NumGoroutine()
+	s, _ := stack.NewFrameStack("main")
+
+	for i := 0; i < 1000; i++ {
+		g.Expect(s.PushFrame("f", stack.WithMaxDepth(4))).To(Succeed())
+		s.Push(i)
+		s.PopFrame()
+	}
+
+	g.Expect(s.PushFrame("g")).To(Succeed())
+	s.Close()
+
+	g.Eventually(runtime.NumGoroutine).Should(BeNumerically("<=", numberOfGoroutinesBefore))
+}
+
+func TestFrameStackWithAMaximumNumberOfFrames(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s, _ := stack.NewFrameStack("main")
+	s.WithAMaximumNumberOfFramesOf(2)
+
+	g.Expect(s.PushFrame("f")).To(Succeed())
+
+	err := s.PushFrame("g")
+	g.Expect(errors.Is(err, stack.ErrStackOverflow)).To(BeTrue())
+	g.Expect(s.CurrentFrameName()).To(Equal("f"))
+
+	g.Expect(testForPanic(func() { s.WithAMaximumNumberOfFramesOf(1) })).To(BeTrue())
+	g.Expect(testForPanic(func() { s.WithAMaximumNumberOfFramesOf(0) })).To(BeTrue())
+
+	s.PopFrame()
+	s.WithAMaximumNumberOfFramesOf(1)
+	g.Expect(s.PushFrame("f")).ToNot(Succeed())
+}
+
+func TestFrameStackConcurrentFrames(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s, _ := stack.NewFrameStack("main")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s.Push(j)
+				s.PushFrame("f")
+				s.PopFrame()
+				s.Pop()
+			}
+		}()
+	}
+	wg.Wait()
+
+	g.Expect(s.NumberOfFrames()).To(Equal(uint(1)))
+}
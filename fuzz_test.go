@@ -0,0 +1,166 @@
+package stack_test
+
+import (
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	. "github.com/onsi/gomega"
+)
+
+// referenceStack is a simple model of the behavior of a stack, against which a Stack is
+// checked.  Its elements are held from the bottom of the stack to the top.
+type referenceStack struct {
+	elements       []interface{}
+	maximumDepth   uint
+	discardsOldest bool
+}
+
+func (reference *referenceStack) push(value interface{}) (cannotPushBecauseStackIsFull bool) {
+	if reference.discardsOldest {
+		if uint(len(reference.elements)) >= reference.maximumDepth {
+			reference.elements = reference.elements[1:]
+		}
+		reference.elements = append(reference.elements, value)
+		return uint(len(reference.elements)) >= reference.maximumDepth
+	}
+
+	if reference.maximumDepth > 0 && uint(len(reference.elements)) >= reference.maximumDepth {
+		return true
+	}
+
+	reference.elements = append(reference.elements, value)
+	return false
+}
+
+func (reference *referenceStack) pop() (value interface{}, stackWasEmptyBeforePop bool) {
+	if len(reference.elements) == 0 {
+		return nil, true
+	}
+
+	value = reference.elements[len(reference.elements)-1]
+	reference.elements = reference.elements[:len(reference.elements)-1]
+	return value, false
+}
+
+func (reference *referenceStack) setMaximumDepth(maximumDepth uint) {
+	if maximumDepth > 0 && uint(len(reference.elements)) > maximumDepth {
+		reference.elements = reference.elements[:maximumDepth]
+	}
+
+	reference.maximumDepth = maximumDepth
+}
+
+func (reference *referenceStack) contentsFromTop() []interface{} {
+	contents := make([]interface{}, len(reference.elements))
+	for i, element := range reference.elements {
+		contents[len(contents)-1-i] = element
+	}
+	return contents
+}
+
+// The operations decoded from the fuzz input.  After the first two bytes, each byte is
+// an operation, taken modulo the number of operations, and setMaximumDepth takes the
+// following byte as its argument.
+const (
+	fuzzPush = iota
+	fuzzPop
+	fuzzReset
+	fuzzSetMaximumDepth
+	fuzzDepth
+	numberOfFuzzOperations
+)
+
+// FuzzStackOperations decodes its input as a kind of stack and a sequence of operations
+// on it.  The first byte selects a standard, bounded or discarding stack, and the second
+// selects the maximum depth (from 1 to 8) of a bounded or discarding stack.  The stack
+// starts with a small capacity, so that pushes grow its storage and evictions from a
+// discarding stack wrap around it.  After every operation, the stack is compared with
+// a reference model.
+func FuzzStackOperations(f *testing.F) {
+	f.Fuzz(func(t *testing.T, input []byte) {
+		g := NewGomegaWithT(t)
+
+		if len(input) < 2 {
+			return
+		}
+
+		kindOfStack, maximumDepth, operations := input[0]%3, uint(input[1]%8)+1, input[2:]
+
+		reference := &referenceStack{}
+		options := []stack.Option{stack.WithInitialCapacity(2)}
+
+		switch kindOfStack {
+		case 1:
+			reference.maximumDepth = maximumDepth
+			options = append(options, stack.WithMaxDepth(maximumDepth))
+		case 2:
+			reference.maximumDepth, reference.discardsOldest = maximumDepth, true
+			options = append(options, stack.WithMaxDepth(maximumDepth), stack.WithDiscardOldest())
+		}
+
+		s, err := stack.New(options...)
+		g.Expect(err).To(BeNil())
+		t.Cleanup(s.Close)
+
+		nextValueToPush := 0
+
+		for i := 0; i < len(operations); i++ {
+			switch operations[i] % numberOfFuzzOperations {
+			case fuzzPush:
+				nextValueToPush++
+				g.Expect(s.Push(nextValueToPush)).To(Equal(reference.push(nextValueToPush)), "Push() at operation %d", i)
+
+			case fuzzPop:
+				expectedValue, expectedStackWasEmpty := reference.pop()
+				value, stackWasEmpty := s.Pop()
+				g.Expect(stackWasEmpty).To(Equal(expectedStackWasEmpty), "Pop() at operation %d", i)
+				if !expectedStackWasEmpty {
+					g.Expect(value).To(Equal(expectedValue), "Pop() at operation %d", i)
+				}
+
+			case fuzzReset:
+				s.ResetToEmpty()
+				reference.elements = nil
+
+			case fuzzSetMaximumDepth:
+				newMaximumDepth := uint(0)
+				if i+1 < len(operations) {
+					i++
+					newMaximumDepth = uint(operations[i] % 9)
+				}
+
+				switch {
+				case reference.discardsOldest && newMaximumDepth == 0:
+					g.Expect(func() { s.RemoveMaximumDepth() }).To(Panic(), "RemoveMaximumDepth() at operation %d", i)
+				case reference.discardsOldest:
+					g.Expect(func() { s.SetMaximumDepthTo(newMaximumDepth) }).To(Panic(), "SetMaximumDepthTo() at operation %d", i)
+				case newMaximumDepth == 0:
+					s.RemoveMaximumDepth()
+					reference.setMaximumDepth(0)
+				default:
+					s.SetMaximumDepthTo(newMaximumDepth)
+					reference.setMaximumDepth(newMaximumDepth)
+				}
+
+			case fuzzDepth:
+				g.Expect(s.Depth()).To(Equal(uint(len(reference.elements))), "Depth() at operation %d", i)
+			}
+
+			g.Expect(s.Range(0, -1)).To(Equal(reference.contentsFromTop()), "contents after operation %d", i)
+			g.Expect(s.MaximumDepth()).To(Equal(reference.maximumDepth), "maximum depth after operation %d", i)
+			g.Expect(s.IsEmpty()).To(Equal(len(reference.elements) == 0), "IsEmpty() after operation %d", i)
+
+			if reference.maximumDepth > 0 {
+				g.Expect(s.Depth()).To(BeNumerically("<=", reference.maximumDepth), "Depth() after operation %d", i)
+			}
+
+			top, stackIsEmpty := s.Peek()
+			if len(reference.elements) == 0 {
+				g.Expect(stackIsEmpty).To(BeTrue(), "Peek() after operation %d", i)
+			} else {
+				g.Expect(stackIsEmpty).To(BeFalse(), "Peek() after operation %d", i)
+				g.Expect(top).To(Equal(reference.elements[len(reference.elements)-1]), "Peek() after operation %d", i)
+			}
+		}
+	})
+}
@@ -0,0 +1,306 @@
+// Package stackgrpc exposes named stacks through the gRPC StackService defined in
+// stack.proto.  A Service implements StackServiceServer over a set of registered
+// stack.Stack instances, and the generated StackServiceClient operates on them remotely.
+//
+// Values are bytes in the service.  They are pushed as strings, so that a stack served
+// here may also be served by package server, and a popped value that is not a string or
+// a []byte is formatted with fmt.Sprint().
+//
+// Errors are reported with gRPC status codes: NotFound for a stack name that is not
+// registered, InvalidArgument for a malformed request, FailedPrecondition for an
+// operation that the stack does not allow (such as changing the maximum depth of a
+// discarding stack), ResourceExhausted for a Watch stream that does not keep up with
+// the operations it reports and Aborted for a Watch stream whose stack is unregistered.
+package stackgrpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative stack.proto
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/blorticus-go/stack"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// WatchBufferSize is the number of events that may be waiting to be sent on a Watch
+// stream.  A stream that falls further behind than this is ended.
+const WatchBufferSize = 256
+
+// Service implements StackServiceServer.  Stacks may be registered and unregistered
+// while the service is in use.
+type Service struct {
+	UnimplementedStackServiceServer
+
+	stacksMutex sync.RWMutex
+	stacks      map[string]*servedStack
+}
+
+// servedStack is a registered stack and the Watch streams for it.  Operations through
+// the service hold the mutex, so that watchers receive events in the order in which the
+// operations were performed.  Once the stack is unregistered, no watcher may be added.
+type servedStack struct {
+	stack           *stack.Stack
+	mutex           sync.Mutex
+	watchers        map[chan *StackEvent]struct{}
+	wasUnregistered bool
+}
+
+// NewService returns a Service with no registered stacks.
+func NewService() *Service {
+	return &Service{stacks: make(map[string]*servedStack)}
+}
+
+// Register makes a stack available by the provided name, which must not be empty and
+// must not already be registered.
+func (service *Service) Register(name string, s *stack.Stack) error {
+	if name == "" {
+		return fmt.Errorf("stack name must not be empty")
+	}
+
+	if s == nil {
+		return fmt.Errorf("stack must not be nil")
+	}
+
+	service.stacksMutex.Lock()
+	defer service.stacksMutex.Unlock()
+
+	if _, nameIsRegistered := service.stacks[name]; nameIsRegistered {
+		return fmt.Errorf("a stack named (%s) is already registered", name)
+	}
+
+	service.stacks[name] = &servedStack{stack: s, watchers: make(map[chan *StackEvent]struct{})}
+	return nil
+}
+
+// Unregister removes the stack with the provided name, if there is one.  Any Watch
+// streams for it are ended.
+func (service *Service) Unregister(name string) {
+	service.stacksMutex.Lock()
+	served, nameIsRegistered := service.stacks[name]
+	delete(service.stacks, name)
+	service.stacksMutex.Unlock()
+
+	if !nameIsRegistered {
+		return
+	}
+
+	served.mutex.Lock()
+	defer served.mutex.Unlock()
+
+	served.wasUnregistered = true
+	for watcher := range served.watchers {
+		close(watcher)
+		delete(served.watchers, watcher)
+	}
+}
+
+func (service *Service) Push(ctx context.Context, request *PushRequest) (*PushResponse, error) {
+	served, err := service.stackNamed(request.GetStackName())
+	if err != nil {
+		return nil, err
+	}
+
+	served.mutex.Lock()
+	defer served.mutex.Unlock()
+
+	cannotPush := served.stack.Push(string(request.GetValue()))
+	if !cannotPush || served.stack.IsDiscarding() {
+		served.notify(&StackEvent{Operation: Operation_OPERATION_PUSH, Value: request.GetValue(), Depth: uint64(served.stack.Depth())})
+	}
+
+	return &PushResponse{CannotPushBecauseStackIsFull: cannotPush}, nil
+}
+
+func (service *Service) Pop(ctx context.Context, request *PopRequest) (*PopResponse, error) {
+	served, err := service.stackNamed(request.GetStackName())
+	if err != nil {
+		return nil, err
+	}
+
+	served.mutex.Lock()
+	defer served.mutex.Unlock()
+
+	value, stackWasEmpty := served.stack.Pop()
+	if stackWasEmpty {
+		return &PopResponse{StackWasEmptyBeforePop: true}, nil
+	}
+
+	valueAsBytes := bytesFrom(value)
+	served.notify(&StackEvent{Operation: Operation_OPERATION_POP, Value: valueAsBytes, Depth: uint64(served.stack.Depth())})
+
+	return &PopResponse{Value: valueAsBytes}, nil
+}
+
+func (service *Service) Peek(ctx context.Context, request *PeekRequest) (*PeekResponse, error) {
+	served, err := service.stackNamed(request.GetStackName())
+	if err != nil {
+		return nil, err
+	}
+
+	value, stackIsEmpty := served.stack.Peek()
+	if stackIsEmpty {
+		return &PeekResponse{StackIsEmpty: true}, nil
+	}
+
+	return &PeekResponse{Value: bytesFrom(value)}, nil
+}
+
+func (service *Service) Depth(ctx context.Context, request *DepthRequest) (*DepthResponse, error) {
+	served, err := service.stackNamed(request.GetStackName())
+	if err != nil {
+		return nil, err
+	}
+
+	return &DepthResponse{Depth: uint64(served.stack.Depth())}, nil
+}
+
+func (service *Service) Reset(ctx context.Context, request *ResetRequest) (*ResetResponse, error) {
+	served, err := service.stackNamed(request.GetStackName())
+	if err != nil {
+		return nil, err
+	}
+
+	served.mutex.Lock()
+	defer served.mutex.Unlock()
+
+	served.stack.ResetToEmpty()
+	served.notify(&StackEvent{Operation: Operation_OPERATION_RESET})
+
+	return &ResetResponse{}, nil
+}
+
+func (service *Service) SetMaximumDepth(ctx context.Context, request *SetMaximumDepthRequest) (response *SetMaximumDepthResponse, err error) {
+	served, err := service.stackNamed(request.GetStackName())
+	if err != nil {
+		return nil, err
+	}
+
+	maximumDepth := uint(request.GetMaximumDepth())
+	if uint64(maximumDepth) != request.GetMaximumDepth() {
+		return nil, status.Errorf(codes.InvalidArgument, "maximum depth %d is too large", request.GetMaximumDepth())
+	}
+
+	served.mutex.Lock()
+	defer served.mutex.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			response, err = nil, status.Errorf(codes.FailedPrecondition, "%v", r)
+		}
+	}()
+
+	if maximumDepth == 0 {
+		served.stack.RemoveMaximumDepth()
+	} else {
+		served.stack.SetMaximumDepthTo(maximumDepth)
+	}
+
+	served.notify(&StackEvent{Operation: Operation_OPERATION_SET_MAXIMUM_DEPTH, Depth: uint64(served.stack.Depth()), MaximumDepth: request.GetMaximumDepth()})
+
+	return &SetMaximumDepthResponse{}, nil
+}
+
+// Watch streams events for the operations performed on a stack through the service.
+// Operations performed on the stack directly, rather than through the service, are not
+// reported.  The stream ends when the client cancels it, with Aborted when the stack is
+// unregistered, or with ResourceExhausted when the stream falls more than WatchBufferSize
+// events behind.
+func (service *Service) Watch(request *WatchRequest, stream StackService_WatchServer) error {
+	served, err := service.stackNamed(request.GetStackName())
+	if err != nil {
+		return err
+	}
+
+	events := make(chan *StackEvent, WatchBufferSize)
+
+	served.mutex.Lock()
+	if served.wasUnregistered {
+		served.mutex.Unlock()
+		return status.Errorf(codes.NotFound, "no stack named (%s)", request.GetStackName())
+	}
+	served.watchers[events] = struct{}{}
+	events <- &StackEvent{Operation: Operation_OPERATION_WATCH_STARTED, Depth: uint64(served.stack.Depth())}
+	served.mutex.Unlock()
+
+	defer served.stopNotifying(events)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+
+		case event, streamIsOpen := <-events:
+			if !streamIsOpen {
+				if served.isUnregistered() {
+					return status.Errorf(codes.Aborted, "watch stream for stack (%s) ended because the stack was unregistered", request.GetStackName())
+				}
+				return status.Errorf(codes.ResourceExhausted, "watch stream for stack (%s) ended because it fell behind", request.GetStackName())
+			}
+
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// notify sends an event to each watcher.  A watcher whose buffer is full is removed and
+// its channel closed.  The mutex must be held.
+func (served *servedStack) notify(event *StackEvent) {
+	for watcher := range served.watchers {
+		select {
+		case watcher <- event:
+		default:
+			close(watcher)
+			delete(served.watchers, watcher)
+		}
+	}
+}
+
+func (served *servedStack) isUnregistered() bool {
+	served.mutex.Lock()
+	defer served.mutex.Unlock()
+
+	return served.wasUnregistered
+}
+
+func (served *servedStack) stopNotifying(watcher chan *StackEvent) {
+	served.mutex.Lock()
+	defer served.mutex.Unlock()
+
+	if _, isWatching := served.watchers[watcher]; isWatching {
+		close(watcher)
+		delete(served.watchers, watcher)
+	}
+}
+
+func (service *Service) stackNamed(name string) (*servedStack, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, status.Error(codes.InvalidArgument, "stack name must not be empty")
+	}
+
+	service.stacksMutex.RLock()
+	defer service.stacksMutex.RUnlock()
+
+	served, nameIsRegistered := service.stacks[name]
+	if !nameIsRegistered {
+		return nil, status.Errorf(codes.NotFound, "no stack named (%s)", name)
+	}
+
+	return served, nil
+}
+
+func bytesFrom(value interface{}) []byte {
+	switch v := value.(type) {
+	case string:
+		return []byte(v)
+	case []byte:
+		return v
+	default:
+		return []byte(fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,280 @@
+package stackgrpc_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/blorticus-go/stack"
+	stackgrpc "github.com/blorticus-go/stack/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startService serves the stacks over an in-process bufconn listener and returns a
+// client connected to it.
+func startService(t *testing.T, stacks map[string]*stack.Stack) (stackgrpc.StackServiceClient, *stackgrpc.Service) {
+	service := stackgrpc.NewService()
+	for name, s := range stacks {
+		if err := service.Register(name, s); err != nil {
+			t.Fatalf("unexpected error on Register(%s): %s", name, err)
+		}
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	stackgrpc.RegisterStackServiceServer(server, service)
+	go server.Serve(listener)
+
+	connection, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("unable to create client: %s", err)
+	}
+
+	t.Cleanup(func() {
+		connection.Close()
+		server.Stop()
+	})
+
+	return stackgrpc.NewStackServiceClient(connection), service
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	client, _ := startService(t, map[string]*stack.Stack{
+		"s":      stack.NewStack(),
+		"window": stack.NewBoundedDiscardingStack(2),
+	})
+
+	for _, value := range []string{"a", "b", "c"} {
+		response, err := client.Push(ctx, &stackgrpc.PushRequest{StackName: "s", Value: []byte(value)})
+		if err != nil || response.GetCannotPushBecauseStackIsFull() {
+			t.Fatalf("unexpected result of Push(%s): (%v, %v)", value, response, err)
+		}
+	}
+
+	if response, err := client.Depth(ctx, &stackgrpc.DepthRequest{StackName: "s"}); err != nil || response.GetDepth() != 3 {
+		t.Errorf("expected depth 3, got (%v, %v)", response, err)
+	}
+
+	if response, err := client.Peek(ctx, &stackgrpc.PeekRequest{StackName: "s"}); err != nil || string(response.GetValue()) != "c" || response.GetStackIsEmpty() {
+		t.Errorf("expected Peek() to return c, got (%v, %v)", response, err)
+	}
+
+	if _, err := client.SetMaximumDepth(ctx, &stackgrpc.SetMaximumDepthRequest{StackName: "s", MaximumDepth: 2}); err != nil {
+		t.Errorf("unexpected error on SetMaximumDepth: %s", err)
+	}
+
+	if response, _ := client.Push(ctx, &stackgrpc.PushRequest{StackName: "s", Value: []byte("d")}); !response.GetCannotPushBecauseStackIsFull() {
+		t.Errorf("expected Push() on full stack to report it full")
+	}
+
+	if response, err := client.Pop(ctx, &stackgrpc.PopRequest{StackName: "s"}); err != nil || string(response.GetValue()) != "b" {
+		t.Errorf("expected Pop() to return b, got (%v, %v)", response, err)
+	}
+
+	if _, err := client.SetMaximumDepth(ctx, &stackgrpc.SetMaximumDepthRequest{StackName: "s", MaximumDepth: 0}); err != nil {
+		t.Errorf("unexpected error on SetMaximumDepth(0): %s", err)
+	}
+
+	if _, err := client.Reset(ctx, &stackgrpc.ResetRequest{StackName: "s"}); err != nil {
+		t.Errorf("unexpected error on Reset: %s", err)
+	}
+
+	if response, err := client.Pop(ctx, &stackgrpc.PopRequest{StackName: "s"}); err != nil || !response.GetStackWasEmptyBeforePop() {
+		t.Errorf("expected Pop() on empty stack to report it empty, got (%v, %v)", response, err)
+	}
+
+	if response, err := client.Peek(ctx, &stackgrpc.PeekRequest{StackName: "s"}); err != nil || !response.GetStackIsEmpty() {
+		t.Errorf("expected Peek() on empty stack to report it empty, got (%v, %v)", response, err)
+	}
+
+	for _, testCase := range []struct {
+		description  string
+		call         func() error
+		expectedCode codes.Code
+	}{
+		{"unknown stack", func() error {
+			_, err := client.Depth(ctx, &stackgrpc.DepthRequest{StackName: "missing"})
+			return err
+		}, codes.NotFound},
+		{"empty name", func() error {
+			_, err := client.Pop(ctx, &stackgrpc.PopRequest{})
+			return err
+		}, codes.InvalidArgument},
+		{"maximum depth of discarding stack", func() error {
+			_, err := client.SetMaximumDepth(ctx, &stackgrpc.SetMaximumDepthRequest{StackName: "window", MaximumDepth: 5})
+			return err
+		}, codes.FailedPrecondition},
+	} {
+		if code := status.Code(testCase.call()); code != testCase.expectedCode {
+			t.Errorf("[%s] expected code %s, got %s", testCase.description, testCase.expectedCode, code)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	s := stack.NewStack()
+	s.Push("already there")
+	client, service := startService(t, map[string]*stack.Stack{"s": s})
+
+	stream, err := client.Watch(ctx, &stackgrpc.WatchRequest{StackName: "s"})
+	if err != nil {
+		t.Fatalf("unexpected error on Watch: %s", err)
+	}
+
+	expectEvent := func(expectedEvent *stackgrpc.StackEvent) {
+		t.Helper()
+
+		event, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("unexpected error on Recv: %s", err)
+		}
+
+		if event.GetOperation() != expectedEvent.GetOperation() || string(event.GetValue()) != string(expectedEvent.GetValue()) ||
+			event.GetDepth() != expectedEvent.GetDepth() || event.GetMaximumDepth() != expectedEvent.GetMaximumDepth() {
+			t.Errorf("expected event (%v), got (%v)", expectedEvent, event)
+		}
+	}
+
+	expectEvent(&stackgrpc.StackEvent{Operation: stackgrpc.Operation_OPERATION_WATCH_STARTED, Depth: 1})
+
+	client.Push(ctx, &stackgrpc.PushRequest{StackName: "s", Value: []byte("x")})
+	expectEvent(&stackgrpc.StackEvent{Operation: stackgrpc.Operation_OPERATION_PUSH, Value: []byte("x"), Depth: 2})
+
+	client.Pop(ctx, &stackgrpc.PopRequest{StackName: "s"})
+	expectEvent(&stackgrpc.StackEvent{Operation: stackgrpc.Operation_OPERATION_POP, Value: []byte("x"), Depth: 1})
+
+	client.SetMaximumDepth(ctx, &stackgrpc.SetMaximumDepthRequest{StackName: "s", MaximumDepth: 1})
+	expectEvent(&stackgrpc.StackEvent{Operation: stackgrpc.Operation_OPERATION_SET_MAXIMUM_DEPTH, Depth: 1, MaximumDepth: 1})
+
+	client.Push(ctx, &stackgrpc.PushRequest{StackName: "s", Value: []byte("rejected")})
+	client.Reset(ctx, &stackgrpc.ResetRequest{StackName: "s"})
+	expectEvent(&stackgrpc.StackEvent{Operation: stackgrpc.Operation_OPERATION_RESET})
+
+	service.Unregister("s")
+	if _, err := stream.Recv(); status.Code(err) != codes.Aborted {
+		t.Errorf("expected stream to end with Aborted after Unregister, got (%v)", err)
+	}
+}
+
+// directWatchStream is a Watch stream that passes each event sent on it to a channel
+// and blocks until the event is received, so that a test controls how far the stream
+// falls behind.
+type directWatchStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events chan *stackgrpc.StackEvent
+}
+
+func (stream *directWatchStream) Context() context.Context { return stream.ctx }
+
+func (stream *directWatchStream) Send(event *stackgrpc.StackEvent) error {
+	select {
+	case stream.events <- event:
+		return nil
+	case <-stream.ctx.Done():
+		return stream.ctx.Err()
+	}
+}
+
+// watchDirectly calls Watch without a gRPC connection, and returns the stream on which
+// events are sent and a channel that receives the error that Watch returns.
+func watchDirectly(ctx context.Context, service *stackgrpc.Service, stackName string) (*directWatchStream, <-chan error) {
+	stream := &directWatchStream{ctx: ctx, events: make(chan *stackgrpc.StackEvent)}
+	result := make(chan error, 1)
+	go func() { result <- service.Watch(&stackgrpc.WatchRequest{StackName: stackName}, stream) }()
+
+	return stream, result
+}
+
+func TestWatchThatFallsBehind(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	service := stackgrpc.NewService()
+	service.Register("s", stack.NewStack())
+
+	stream, result := watchDirectly(ctx, service, "s")
+	if event := <-stream.events; event.GetOperation() != stackgrpc.Operation_OPERATION_WATCH_STARTED {
+		t.Fatalf("expected WATCH_STARTED event, got (%v)", event)
+	}
+
+	for i := 0; i <= stackgrpc.WatchBufferSize+1; i++ {
+		service.Push(ctx, &stackgrpc.PushRequest{StackName: "s", Value: []byte("x")})
+	}
+
+	numberOfEventsSent := 0
+	for {
+		select {
+		case <-stream.events:
+			numberOfEventsSent++
+			continue
+		case err := <-result:
+			if status.Code(err) != codes.ResourceExhausted {
+				t.Errorf("expected stream to end with ResourceExhausted, got (%v)", err)
+			}
+		}
+		break
+	}
+
+	if numberOfEventsSent > stackgrpc.WatchBufferSize+1 {
+		t.Errorf("expected no more than %d events after falling behind, got (%d)", stackgrpc.WatchBufferSize+1, numberOfEventsSent)
+	}
+}
+
+func TestWatchRacingUnregister(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	service := stackgrpc.NewService()
+
+	for i := 0; i < 200; i++ {
+		service.Register("s", stack.NewStack())
+
+		stream, result := watchDirectly(ctx, service, "s")
+		go service.Unregister("s")
+
+		var err error
+		for watchIsRunning := true; watchIsRunning; {
+			select {
+			case <-stream.events:
+			case err = <-result:
+				watchIsRunning = false
+			}
+		}
+
+		if code := status.Code(err); code != codes.NotFound && code != codes.Aborted {
+			t.Fatalf("expected Watch racing Unregister to end with NotFound or Aborted, got (%v)", err)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	service := stackgrpc.NewService()
+
+	if err := service.Register("", stack.NewStack()); err == nil {
+		t.Errorf("expected error on Register with empty name")
+	}
+
+	if err := service.Register("s", nil); err == nil {
+		t.Errorf("expected error on Register with nil stack")
+	}
+
+	if err := service.Register("s", stack.NewStack()); err != nil {
+		t.Fatalf("unexpected error on Register: %s", err)
+	}
+
+	if err := service.Register("s", stack.NewStack()); err == nil {
+		t.Errorf("expected error on Register of duplicate name")
+	}
+}
@@ -0,0 +1,1145 @@
+// The StackService exposes named stacks for remote operation.  Its operations mirror
+// the methods of a stack.Stack.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: stack.proto
+
+package stackgrpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Operation int32
+
+const (
+	Operation_OPERATION_UNSPECIFIED       Operation = 0
+	Operation_OPERATION_WATCH_STARTED     Operation = 1
+	Operation_OPERATION_PUSH              Operation = 2
+	Operation_OPERATION_POP               Operation = 3
+	Operation_OPERATION_RESET             Operation = 4
+	Operation_OPERATION_SET_MAXIMUM_DEPTH Operation = 5
+)
+
+// Enum value maps for Operation.
+var (
+	Operation_name = map[int32]string{
+		0: "OPERATION_UNSPECIFIED",
+		1: "OPERATION_WATCH_STARTED",
+		2: "OPERATION_PUSH",
+		3: "OPERATION_POP",
+		4: "OPERATION_RESET",
+		5: "OPERATION_SET_MAXIMUM_DEPTH",
+	}
+	Operation_value = map[string]int32{
+		"OPERATION_UNSPECIFIED":       0,
+		"OPERATION_WATCH_STARTED":     1,
+		"OPERATION_PUSH":              2,
+		"OPERATION_POP":               3,
+		"OPERATION_RESET":             4,
+		"OPERATION_SET_MAXIMUM_DEPTH": 5,
+	}
+)
+
+func (x Operation) Enum() *Operation {
+	p := new(Operation)
+	*p = x
+	return p
+}
+
+func (x Operation) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Operation) Descriptor() protoreflect.EnumDescriptor {
+	return file_stack_proto_enumTypes[0].Descriptor()
+}
+
+func (Operation) Type() protoreflect.EnumType {
+	return &file_stack_proto_enumTypes[0]
+}
+
+func (x Operation) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Operation.Descriptor instead.
+func (Operation) EnumDescriptor() ([]byte, []int) {
+	return file_stack_proto_rawDescGZIP(), []int{0}
+}
+
+type PushRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StackName string `protobuf:"bytes,1,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
+	Value     []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *PushRequest) Reset() {
+	*x = PushRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stack_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PushRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushRequest) ProtoMessage() {}
+
+func (x *PushRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushRequest.ProtoReflect.Descriptor instead.
+func (*PushRequest) Descriptor() ([]byte, []int) {
+	return file_stack_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PushRequest) GetStackName() string {
+	if x != nil {
+		return x.StackName
+	}
+	return ""
+}
+
+func (x *PushRequest) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type PushResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// cannot_push_because_stack_is_full is the value returned by Push() on the stack.
+	CannotPushBecauseStackIsFull bool `protobuf:"varint,1,opt,name=cannot_push_because_stack_is_full,json=cannotPushBecauseStackIsFull,proto3" json:"cannot_push_because_stack_is_full,omitempty"`
+}
+
+func (x *PushResponse) Reset() {
+	*x = PushResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stack_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PushResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushResponse) ProtoMessage() {}
+
+func (x *PushResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushResponse.ProtoReflect.Descriptor instead.
+func (*PushResponse) Descriptor() ([]byte, []int) {
+	return file_stack_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PushResponse) GetCannotPushBecauseStackIsFull() bool {
+	if x != nil {
+		return x.CannotPushBecauseStackIsFull
+	}
+	return false
+}
+
+type PopRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StackName string `protobuf:"bytes,1,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
+}
+
+func (x *PopRequest) Reset() {
+	*x = PopRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stack_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PopRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PopRequest) ProtoMessage() {}
+
+func (x *PopRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PopRequest.ProtoReflect.Descriptor instead.
+func (*PopRequest) Descriptor() ([]byte, []int) {
+	return file_stack_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PopRequest) GetStackName() string {
+	if x != nil {
+		return x.StackName
+	}
+	return ""
+}
+
+type PopResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Value                  []byte `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	StackWasEmptyBeforePop bool   `protobuf:"varint,2,opt,name=stack_was_empty_before_pop,json=stackWasEmptyBeforePop,proto3" json:"stack_was_empty_before_pop,omitempty"`
+}
+
+func (x *PopResponse) Reset() {
+	*x = PopResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stack_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PopResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PopResponse) ProtoMessage() {}
+
+func (x *PopResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PopResponse.ProtoReflect.Descriptor instead.
+func (*PopResponse) Descriptor() ([]byte, []int) {
+	return file_stack_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PopResponse) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *PopResponse) GetStackWasEmptyBeforePop() bool {
+	if x != nil {
+		return x.StackWasEmptyBeforePop
+	}
+	return false
+}
+
+type PeekRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StackName string `protobuf:"bytes,1,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
+}
+
+func (x *PeekRequest) Reset() {
+	*x = PeekRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stack_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PeekRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeekRequest) ProtoMessage() {}
+
+func (x *PeekRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeekRequest.ProtoReflect.Descriptor instead.
+func (*PeekRequest) Descriptor() ([]byte, []int) {
+	return file_stack_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PeekRequest) GetStackName() string {
+	if x != nil {
+		return x.StackName
+	}
+	return ""
+}
+
+type PeekResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Value        []byte `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	StackIsEmpty bool   `protobuf:"varint,2,opt,name=stack_is_empty,json=stackIsEmpty,proto3" json:"stack_is_empty,omitempty"`
+}
+
+func (x *PeekResponse) Reset() {
+	*x = PeekResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stack_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PeekResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeekResponse) ProtoMessage() {}
+
+func (x *PeekResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeekResponse.ProtoReflect.Descriptor instead.
+func (*PeekResponse) Descriptor() ([]byte, []int) {
+	return file_stack_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PeekResponse) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *PeekResponse) GetStackIsEmpty() bool {
+	if x != nil {
+		return x.StackIsEmpty
+	}
+	return false
+}
+
+type DepthRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StackName string `protobuf:"bytes,1,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
+}
+
+func (x *DepthRequest) Reset() {
+	*x = DepthRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stack_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DepthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DepthRequest) ProtoMessage() {}
+
+func (x *DepthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DepthRequest.ProtoReflect.Descriptor instead.
+func (*DepthRequest) Descriptor() ([]byte, []int) {
+	return file_stack_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DepthRequest) GetStackName() string {
+	if x != nil {
+		return x.StackName
+	}
+	return ""
+}
+
+type DepthResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Depth uint64 `protobuf:"varint,1,opt,name=depth,proto3" json:"depth,omitempty"`
+}
+
+func (x *DepthResponse) Reset() {
+	*x = DepthResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stack_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DepthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DepthResponse) ProtoMessage() {}
+
+func (x *DepthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DepthResponse.ProtoReflect.Descriptor instead.
+func (*DepthResponse) Descriptor() ([]byte, []int) {
+	return file_stack_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DepthResponse) GetDepth() uint64 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+type ResetRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StackName string `protobuf:"bytes,1,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
+}
+
+func (x *ResetRequest) Reset() {
+	*x = ResetRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stack_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetRequest) ProtoMessage() {}
+
+func (x *ResetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetRequest.ProtoReflect.Descriptor instead.
+func (*ResetRequest) Descriptor() ([]byte, []int) {
+	return file_stack_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ResetRequest) GetStackName() string {
+	if x != nil {
+		return x.StackName
+	}
+	return ""
+}
+
+type ResetResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ResetResponse) Reset() {
+	*x = ResetResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stack_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetResponse) ProtoMessage() {}
+
+func (x *ResetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetResponse.ProtoReflect.Descriptor instead.
+func (*ResetResponse) Descriptor() ([]byte, []int) {
+	return file_stack_proto_rawDescGZIP(), []int{9}
+}
+
+type SetMaximumDepthRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StackName    string `protobuf:"bytes,1,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
+	MaximumDepth uint64 `protobuf:"varint,2,opt,name=maximum_depth,json=maximumDepth,proto3" json:"maximum_depth,omitempty"`
+}
+
+func (x *SetMaximumDepthRequest) Reset() {
+	*x = SetMaximumDepthRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stack_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetMaximumDepthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetMaximumDepthRequest) ProtoMessage() {}
+
+func (x *SetMaximumDepthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetMaximumDepthRequest.ProtoReflect.Descriptor instead.
+func (*SetMaximumDepthRequest) Descriptor() ([]byte, []int) {
+	return file_stack_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SetMaximumDepthRequest) GetStackName() string {
+	if x != nil {
+		return x.StackName
+	}
+	return ""
+}
+
+func (x *SetMaximumDepthRequest) GetMaximumDepth() uint64 {
+	if x != nil {
+		return x.MaximumDepth
+	}
+	return 0
+}
+
+type SetMaximumDepthResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *SetMaximumDepthResponse) Reset() {
+	*x = SetMaximumDepthResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stack_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetMaximumDepthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetMaximumDepthResponse) ProtoMessage() {}
+
+func (x *SetMaximumDepthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetMaximumDepthResponse.ProtoReflect.Descriptor instead.
+func (*SetMaximumDepthResponse) Descriptor() ([]byte, []int) {
+	return file_stack_proto_rawDescGZIP(), []int{11}
+}
+
+type WatchRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StackName string `protobuf:"bytes,1,opt,name=stack_name,json=stackName,proto3" json:"stack_name,omitempty"`
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stack_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_stack_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *WatchRequest) GetStackName() string {
+	if x != nil {
+		return x.StackName
+	}
+	return ""
+}
+
+type StackEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Operation Operation `protobuf:"varint,1,opt,name=operation,proto3,enum=blorticus.stack.v1.Operation" json:"operation,omitempty"`
+	// value is the value pushed or popped, if any.
+	Value []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// depth is the depth of the stack after the operation.
+	Depth uint64 `protobuf:"varint,3,opt,name=depth,proto3" json:"depth,omitempty"`
+	// maximum_depth is the maximum depth set by OPERATION_SET_MAXIMUM_DEPTH.
+	MaximumDepth uint64 `protobuf:"varint,4,opt,name=maximum_depth,json=maximumDepth,proto3" json:"maximum_depth,omitempty"`
+}
+
+func (x *StackEvent) Reset() {
+	*x = StackEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stack_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StackEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StackEvent) ProtoMessage() {}
+
+func (x *StackEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_stack_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StackEvent.ProtoReflect.Descriptor instead.
+func (*StackEvent) Descriptor() ([]byte, []int) {
+	return file_stack_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *StackEvent) GetOperation() Operation {
+	if x != nil {
+		return x.Operation
+	}
+	return Operation_OPERATION_UNSPECIFIED
+}
+
+func (x *StackEvent) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *StackEvent) GetDepth() uint64 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+func (x *StackEvent) GetMaximumDepth() uint64 {
+	if x != nil {
+		return x.MaximumDepth
+	}
+	return 0
+}
+
+var File_stack_proto protoreflect.FileDescriptor
+
+var file_stack_proto_rawDesc = []byte{
+	0x0a, 0x0b, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x12, 0x62,
+	0x6c, 0x6f, 0x72, 0x74, 0x69, 0x63, 0x75, 0x73, 0x2e, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x2e, 0x76,
+	0x31, 0x22, 0x42, 0x0a, 0x0b, 0x50, 0x75, 0x73, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x4e, 0x61, 0x6d, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x57, 0x0a, 0x0c, 0x50, 0x75, 0x73, 0x68, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x47, 0x0a, 0x21, 0x63, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x5f,
+	0x70, 0x75, 0x73, 0x68, 0x5f, 0x62, 0x65, 0x63, 0x61, 0x75, 0x73, 0x65, 0x5f, 0x73, 0x74, 0x61,
+	0x63, 0x6b, 0x5f, 0x69, 0x73, 0x5f, 0x66, 0x75, 0x6c, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x1c, 0x63, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x50, 0x75, 0x73, 0x68, 0x42, 0x65, 0x63, 0x61,
+	0x75, 0x73, 0x65, 0x53, 0x74, 0x61, 0x63, 0x6b, 0x49, 0x73, 0x46, 0x75, 0x6c, 0x6c, 0x22, 0x2b,
+	0x0a, 0x0a, 0x50, 0x6f, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a,
+	0x73, 0x74, 0x61, 0x63, 0x6b, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0x5f, 0x0a, 0x0b, 0x50,
+	0x6f, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x12, 0x3a, 0x0a, 0x1a, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x5f, 0x77, 0x61, 0x73, 0x5f, 0x65, 0x6d,
+	0x70, 0x74, 0x79, 0x5f, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x5f, 0x70, 0x6f, 0x70, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x16, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x57, 0x61, 0x73, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x50, 0x6f, 0x70, 0x22, 0x2c, 0x0a, 0x0b,
+	0x50, 0x65, 0x65, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73,
+	0x74, 0x61, 0x63, 0x6b, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0x4a, 0x0a, 0x0c, 0x50, 0x65,
+	0x65, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x12, 0x24, 0x0a, 0x0e, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x5f, 0x69, 0x73, 0x5f, 0x65, 0x6d, 0x70,
+	0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x49,
+	0x73, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x2d, 0x0a, 0x0c, 0x44, 0x65, 0x70, 0x74, 0x68, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x5f,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x63,
+	0x6b, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0x25, 0x0a, 0x0d, 0x44, 0x65, 0x70, 0x74, 0x68, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x64, 0x65, 0x70, 0x74, 0x68, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x64, 0x65, 0x70, 0x74, 0x68, 0x22, 0x2d, 0x0a, 0x0c,
+	0x52, 0x65, 0x73, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a,
+	0x73, 0x74, 0x61, 0x63, 0x6b, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0x0f, 0x0a, 0x0d, 0x52,
+	0x65, 0x73, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x5c, 0x0a, 0x16,
+	0x53, 0x65, 0x74, 0x4d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x44, 0x65, 0x70, 0x74, 0x68, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x5f,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x63,
+	0x6b, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d,
+	0x5f, 0x64, 0x65, 0x70, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x6d, 0x61,
+	0x78, 0x69, 0x6d, 0x75, 0x6d, 0x44, 0x65, 0x70, 0x74, 0x68, 0x22, 0x19, 0x0a, 0x17, 0x53, 0x65,
+	0x74, 0x4d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x44, 0x65, 0x70, 0x74, 0x68, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x2d, 0x0a, 0x0c, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x5f, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x63, 0x6b,
+	0x4e, 0x61, 0x6d, 0x65, 0x22, 0x9a, 0x01, 0x0a, 0x0a, 0x53, 0x74, 0x61, 0x63, 0x6b, 0x45, 0x76,
+	0x65, 0x6e, 0x74, 0x12, 0x3b, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1d, 0x2e, 0x62, 0x6c, 0x6f, 0x72, 0x74, 0x69, 0x63,
+	0x75, 0x73, 0x2e, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x70, 0x65, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x64, 0x65, 0x70, 0x74, 0x68, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x64, 0x65, 0x70, 0x74, 0x68, 0x12, 0x23, 0x0a, 0x0d,
+	0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x5f, 0x64, 0x65, 0x70, 0x74, 0x68, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0c, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x44, 0x65, 0x70, 0x74,
+	0x68, 0x2a, 0xa0, 0x01, 0x0a, 0x09, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x19, 0x0a, 0x15, 0x4f, 0x50, 0x45, 0x52, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x55, 0x4e, 0x53,
+	0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x1b, 0x0a, 0x17, 0x4f, 0x50,
+	0x45, 0x52, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x57, 0x41, 0x54, 0x43, 0x48, 0x5f, 0x53, 0x54,
+	0x41, 0x52, 0x54, 0x45, 0x44, 0x10, 0x01, 0x12, 0x12, 0x0a, 0x0e, 0x4f, 0x50, 0x45, 0x52, 0x41,
+	0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x50, 0x55, 0x53, 0x48, 0x10, 0x02, 0x12, 0x11, 0x0a, 0x0d, 0x4f,
+	0x50, 0x45, 0x52, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x50, 0x4f, 0x50, 0x10, 0x03, 0x12, 0x13,
+	0x0a, 0x0f, 0x4f, 0x50, 0x45, 0x52, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x52, 0x45, 0x53, 0x45,
+	0x54, 0x10, 0x04, 0x12, 0x1f, 0x0a, 0x1b, 0x4f, 0x50, 0x45, 0x52, 0x41, 0x54, 0x49, 0x4f, 0x4e,
+	0x5f, 0x53, 0x45, 0x54, 0x5f, 0x4d, 0x41, 0x58, 0x49, 0x4d, 0x55, 0x4d, 0x5f, 0x44, 0x45, 0x50,
+	0x54, 0x48, 0x10, 0x05, 0x32, 0xc1, 0x04, 0x0a, 0x0c, 0x53, 0x74, 0x61, 0x63, 0x6b, 0x53, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x49, 0x0a, 0x04, 0x50, 0x75, 0x73, 0x68, 0x12, 0x1f, 0x2e,
+	0x62, 0x6c, 0x6f, 0x72, 0x74, 0x69, 0x63, 0x75, 0x73, 0x2e, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x2e,
+	0x76, 0x31, 0x2e, 0x50, 0x75, 0x73, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20,
+	0x2e, 0x62, 0x6c, 0x6f, 0x72, 0x74, 0x69, 0x63, 0x75, 0x73, 0x2e, 0x73, 0x74, 0x61, 0x63, 0x6b,
+	0x2e, 0x76, 0x31, 0x2e, 0x50, 0x75, 0x73, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x46, 0x0a, 0x03, 0x50, 0x6f, 0x70, 0x12, 0x1e, 0x2e, 0x62, 0x6c, 0x6f, 0x72, 0x74, 0x69,
+	0x63, 0x75, 0x73, 0x2e, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x70,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x62, 0x6c, 0x6f, 0x72, 0x74, 0x69,
+	0x63, 0x75, 0x73, 0x2e, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x70,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x49, 0x0a, 0x04, 0x50, 0x65, 0x65, 0x6b,
+	0x12, 0x1f, 0x2e, 0x62, 0x6c, 0x6f, 0x72, 0x74, 0x69, 0x63, 0x75, 0x73, 0x2e, 0x73, 0x74, 0x61,
+	0x63, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x65, 0x65, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x20, 0x2e, 0x62, 0x6c, 0x6f, 0x72, 0x74, 0x69, 0x63, 0x75, 0x73, 0x2e, 0x73, 0x74,
+	0x61, 0x63, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x65, 0x65, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x4c, 0x0a, 0x05, 0x44, 0x65, 0x70, 0x74, 0x68, 0x12, 0x20, 0x2e, 0x62,
+	0x6c, 0x6f, 0x72, 0x74, 0x69, 0x63, 0x75, 0x73, 0x2e, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x2e, 0x76,
+	0x31, 0x2e, 0x44, 0x65, 0x70, 0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21,
+	0x2e, 0x62, 0x6c, 0x6f, 0x72, 0x74, 0x69, 0x63, 0x75, 0x73, 0x2e, 0x73, 0x74, 0x61, 0x63, 0x6b,
+	0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x70, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x4c, 0x0a, 0x05, 0x52, 0x65, 0x73, 0x65, 0x74, 0x12, 0x20, 0x2e, 0x62, 0x6c, 0x6f,
+	0x72, 0x74, 0x69, 0x63, 0x75, 0x73, 0x2e, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x2e, 0x76, 0x31, 0x2e,
+	0x52, 0x65, 0x73, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x62,
+	0x6c, 0x6f, 0x72, 0x74, 0x69, 0x63, 0x75, 0x73, 0x2e, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x2e, 0x76,
+	0x31, 0x2e, 0x52, 0x65, 0x73, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x6a, 0x0a, 0x0f, 0x53, 0x65, 0x74, 0x4d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x44, 0x65, 0x70,
+	0x74, 0x68, 0x12, 0x2a, 0x2e, 0x62, 0x6c, 0x6f, 0x72, 0x74, 0x69, 0x63, 0x75, 0x73, 0x2e, 0x73,
+	0x74, 0x61, 0x63, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x74, 0x4d, 0x61, 0x78, 0x69, 0x6d,
+	0x75, 0x6d, 0x44, 0x65, 0x70, 0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2b,
+	0x2e, 0x62, 0x6c, 0x6f, 0x72, 0x74, 0x69, 0x63, 0x75, 0x73, 0x2e, 0x73, 0x74, 0x61, 0x63, 0x6b,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x74, 0x4d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x44, 0x65,
+	0x70, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4b, 0x0a, 0x05, 0x57,
+	0x61, 0x74, 0x63, 0x68, 0x12, 0x20, 0x2e, 0x62, 0x6c, 0x6f, 0x72, 0x74, 0x69, 0x63, 0x75, 0x73,
+	0x2e, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x62, 0x6c, 0x6f, 0x72, 0x74, 0x69, 0x63,
+	0x75, 0x73, 0x2e, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x63,
+	0x6b, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x42, 0x2e, 0x5a, 0x2c, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x62, 0x6c, 0x6f, 0x72, 0x74, 0x69, 0x63, 0x75, 0x73,
+	0x2d, 0x67, 0x6f, 0x2f, 0x73, 0x74, 0x61, 0x63, 0x6b, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x3b, 0x73,
+	0x74, 0x61, 0x63, 0x6b, 0x67, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_stack_proto_rawDescOnce sync.Once
+	file_stack_proto_rawDescData = file_stack_proto_rawDesc
+)
+
+func file_stack_proto_rawDescGZIP() []byte {
+	file_stack_proto_rawDescOnce.Do(func() {
+		file_stack_proto_rawDescData = protoimpl.X.CompressGZIP(file_stack_proto_rawDescData)
+	})
+	return file_stack_proto_rawDescData
+}
+
+var file_stack_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_stack_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_stack_proto_goTypes = []any{
+	(Operation)(0),                  // 0: blorticus.stack.v1.Operation
+	(*PushRequest)(nil),             // 1: blorticus.stack.v1.PushRequest
+	(*PushResponse)(nil),            // 2: blorticus.stack.v1.PushResponse
+	(*PopRequest)(nil),              // 3: blorticus.stack.v1.PopRequest
+	(*PopResponse)(nil),             // 4: blorticus.stack.v1.PopResponse
+	(*PeekRequest)(nil),             // 5: blorticus.stack.v1.PeekRequest
+	(*PeekResponse)(nil),            // 6: blorticus.stack.v1.PeekResponse
+	(*DepthRequest)(nil),            // 7: blorticus.stack.v1.DepthRequest
+	(*DepthResponse)(nil),           // 8: blorticus.stack.v1.DepthResponse
+	(*ResetRequest)(nil),            // 9: blorticus.stack.v1.ResetRequest
+	(*ResetResponse)(nil),           // 10: blorticus.stack.v1.ResetResponse
+	(*SetMaximumDepthRequest)(nil),  // 11: blorticus.stack.v1.SetMaximumDepthRequest
+	(*SetMaximumDepthResponse)(nil), // 12: blorticus.stack.v1.SetMaximumDepthResponse
+	(*WatchRequest)(nil),            // 13: blorticus.stack.v1.WatchRequest
+	(*StackEvent)(nil),              // 14: blorticus.stack.v1.StackEvent
+}
+var file_stack_proto_depIdxs = []int32{
+	0,  // 0: blorticus.stack.v1.StackEvent.operation:type_name -> blorticus.stack.v1.Operation
+	1,  // 1: blorticus.stack.v1.StackService.Push:input_type -> blorticus.stack.v1.PushRequest
+	3,  // 2: blorticus.stack.v1.StackService.Pop:input_type -> blorticus.stack.v1.PopRequest
+	5,  // 3: blorticus.stack.v1.StackService.Peek:input_type -> blorticus.stack.v1.PeekRequest
+	7,  // 4: blorticus.stack.v1.StackService.Depth:input_type -> blorticus.stack.v1.DepthRequest
+	9,  // 5: blorticus.stack.v1.StackService.Reset:input_type -> blorticus.stack.v1.ResetRequest
+	11, // 6: blorticus.stack.v1.StackService.SetMaximumDepth:input_type -> blorticus.stack.v1.SetMaximumDepthRequest
+	13, // 7: blorticus.stack.v1.StackService.Watch:input_type -> blorticus.stack.v1.WatchRequest
+	2,  // 8: blorticus.stack.v1.StackService.Push:output_type -> blorticus.stack.v1.PushResponse
+	4,  // 9: blorticus.stack.v1.StackService.Pop:output_type -> blorticus.stack.v1.PopResponse
+	6,  // 10: blorticus.stack.v1.StackService.Peek:output_type -> blorticus.stack.v1.PeekResponse
+	8,  // 11: blorticus.stack.v1.StackService.Depth:output_type -> blorticus.stack.v1.DepthResponse
+	10, // 12: blorticus.stack.v1.StackService.Reset:output_type -> blorticus.stack.v1.ResetResponse
+	12, // 13: blorticus.stack.v1.StackService.SetMaximumDepth:output_type -> blorticus.stack.v1.SetMaximumDepthResponse
+	14, // 14: blorticus.stack.v1.StackService.Watch:output_type -> blorticus.stack.v1.StackEvent
+	8,  // [8:15] is the sub-list for method output_type
+	1,  // [1:8] is the sub-list for method input_type
+	1,  // [1:1] is the sub-list for extension type_name
+	1,  // [1:1] is the sub-list for extension extendee
+	0,  // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_stack_proto_init() }
+func file_stack_proto_init() {
+	if File_stack_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_stack_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*PushRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stack_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*PushResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stack_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*PopRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stack_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*PopResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stack_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*PeekRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stack_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*PeekResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stack_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*DepthRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stack_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*DepthResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stack_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*ResetRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stack_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*ResetResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stack_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*SetMaximumDepthRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stack_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*SetMaximumDepthResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stack_proto_msgTypes[12].Exporter = func(v any, i int) any {
+			switch v := v.(*WatchRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stack_proto_msgTypes[13].Exporter = func(v any, i int) any {
+			switch v := v.(*StackEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_stack_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_stack_proto_goTypes,
+		DependencyIndexes: file_stack_proto_depIdxs,
+		EnumInfos:         file_stack_proto_enumTypes,
+		MessageInfos:      file_stack_proto_msgTypes,
+	}.Build()
+	File_stack_proto = out.File
+	file_stack_proto_rawDesc = nil
+	file_stack_proto_goTypes = nil
+	file_stack_proto_depIdxs = nil
+}
@@ -0,0 +1,374 @@
+// The StackService exposes named stacks for remote operation.  Its operations mirror
+// the methods of a stack.Stack.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: stack.proto
+
+package stackgrpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	StackService_Push_FullMethodName            = "/blorticus.stack.v1.StackService/Push"
+	StackService_Pop_FullMethodName             = "/blorticus.stack.v1.StackService/Pop"
+	StackService_Peek_FullMethodName            = "/blorticus.stack.v1.StackService/Peek"
+	StackService_Depth_FullMethodName           = "/blorticus.stack.v1.StackService/Depth"
+	StackService_Reset_FullMethodName           = "/blorticus.stack.v1.StackService/Reset"
+	StackService_SetMaximumDepth_FullMethodName = "/blorticus.stack.v1.StackService/SetMaximumDepth"
+	StackService_Watch_FullMethodName           = "/blorticus.stack.v1.StackService/Watch"
+)
+
+// StackServiceClient is the client API for StackService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type StackServiceClient interface {
+	// Push pushes a value to the top of a stack.
+	Push(ctx context.Context, in *PushRequest, opts ...grpc.CallOption) (*PushResponse, error)
+	// Pop removes the value at the top of a stack and returns it.
+	Pop(ctx context.Context, in *PopRequest, opts ...grpc.CallOption) (*PopResponse, error)
+	// Peek returns the value at the top of a stack without removing it.
+	Peek(ctx context.Context, in *PeekRequest, opts ...grpc.CallOption) (*PeekResponse, error)
+	// Depth returns the number of values on a stack.
+	Depth(ctx context.Context, in *DepthRequest, opts ...grpc.CallOption) (*DepthResponse, error)
+	// Reset discards all values on a stack.
+	Reset(ctx context.Context, in *ResetRequest, opts ...grpc.CallOption) (*ResetResponse, error)
+	// SetMaximumDepth sets the maximum depth of a stack, or removes it if the maximum
+	// depth is 0.
+	SetMaximumDepth(ctx context.Context, in *SetMaximumDepthRequest, opts ...grpc.CallOption) (*SetMaximumDepthResponse, error)
+	// Watch streams an event for each operation performed on a stack through the
+	// service, beginning with a WATCH_STARTED event that reports the current depth.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StackEvent], error)
+}
+
+type stackServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewStackServiceClient(cc grpc.ClientConnInterface) StackServiceClient {
+	return &stackServiceClient{cc}
+}
+
+func (c *stackServiceClient) Push(ctx context.Context, in *PushRequest, opts ...grpc.CallOption) (*PushResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PushResponse)
+	err := c.cc.Invoke(ctx, StackService_Push_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stackServiceClient) Pop(ctx context.Context, in *PopRequest, opts ...grpc.CallOption) (*PopResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PopResponse)
+	err := c.cc.Invoke(ctx, StackService_Pop_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stackServiceClient) Peek(ctx context.Context, in *PeekRequest, opts ...grpc.CallOption) (*PeekResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PeekResponse)
+	err := c.cc.Invoke(ctx, StackService_Peek_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stackServiceClient) Depth(ctx context.Context, in *DepthRequest, opts ...grpc.CallOption) (*DepthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DepthResponse)
+	err := c.cc.Invoke(ctx, StackService_Depth_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stackServiceClient) Reset(ctx context.Context, in *ResetRequest, opts ...grpc.CallOption) (*ResetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResetResponse)
+	err := c.cc.Invoke(ctx, StackService_Reset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stackServiceClient) SetMaximumDepth(ctx context.Context, in *SetMaximumDepthRequest, opts ...grpc.CallOption) (*SetMaximumDepthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetMaximumDepthResponse)
+	err := c.cc.Invoke(ctx, StackService_SetMaximumDepth_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stackServiceClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StackEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &StackService_ServiceDesc.Streams[0], StackService_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, StackEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StackService_WatchClient = grpc.ServerStreamingClient[StackEvent]
+
+// StackServiceServer is the server API for StackService service.
+// All implementations must embed UnimplementedStackServiceServer
+// for forward compatibility.
+type StackServiceServer interface {
+	// Push pushes a value to the top of a stack.
+	Push(context.Context, *PushRequest) (*PushResponse, error)
+	// Pop removes the value at the top of a stack and returns it.
+	Pop(context.Context, *PopRequest) (*PopResponse, error)
+	// Peek returns the value at the top of a stack without removing it.
+	Peek(context.Context, *PeekRequest) (*PeekResponse, error)
+	// Depth returns the number of values on a stack.
+	Depth(context.Context, *DepthRequest) (*DepthResponse, error)
+	// Reset discards all values on a stack.
+	Reset(context.Context, *ResetRequest) (*ResetResponse, error)
+	// SetMaximumDepth sets the maximum depth of a stack, or removes it if the maximum
+	// depth is 0.
+	SetMaximumDepth(context.Context, *SetMaximumDepthRequest) (*SetMaximumDepthResponse, error)
+	// Watch streams an event for each operation performed on a stack through the
+	// service, beginning with a WATCH_STARTED event that reports the current depth.
+	Watch(*WatchRequest, grpc.ServerStreamingServer[StackEvent]) error
+	mustEmbedUnimplementedStackServiceServer()
+}
+
+// UnimplementedStackServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedStackServiceServer struct{}
+
+func (UnimplementedStackServiceServer) Push(context.Context, *PushRequest) (*PushResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Push not implemented")
+}
+func (UnimplementedStackServiceServer) Pop(context.Context, *PopRequest) (*PopResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pop not implemented")
+}
+func (UnimplementedStackServiceServer) Peek(context.Context, *PeekRequest) (*PeekResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Peek not implemented")
+}
+func (UnimplementedStackServiceServer) Depth(context.Context, *DepthRequest) (*DepthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Depth not implemented")
+}
+func (UnimplementedStackServiceServer) Reset(context.Context, *ResetRequest) (*ResetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reset not implemented")
+}
+func (UnimplementedStackServiceServer) SetMaximumDepth(context.Context, *SetMaximumDepthRequest) (*SetMaximumDepthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetMaximumDepth not implemented")
+}
+func (UnimplementedStackServiceServer) Watch(*WatchRequest, grpc.ServerStreamingServer[StackEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedStackServiceServer) mustEmbedUnimplementedStackServiceServer() {}
+func (UnimplementedStackServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeStackServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to StackServiceServer will
+// result in compilation errors.
+type UnsafeStackServiceServer interface {
+	mustEmbedUnimplementedStackServiceServer()
+}
+
+func RegisterStackServiceServer(s grpc.ServiceRegistrar, srv StackServiceServer) {
+	// If the following call pancis, it indicates UnimplementedStackServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&StackService_ServiceDesc, srv)
+}
+
+func _StackService_Push_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PushRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StackServiceServer).Push(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StackService_Push_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StackServiceServer).Push(ctx, req.(*PushRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StackService_Pop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PopRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StackServiceServer).Pop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StackService_Pop_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StackServiceServer).Pop(ctx, req.(*PopRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StackService_Peek_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PeekRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StackServiceServer).Peek(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StackService_Peek_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StackServiceServer).Peek(ctx, req.(*PeekRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StackService_Depth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DepthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StackServiceServer).Depth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StackService_Depth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StackServiceServer).Depth(ctx, req.(*DepthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StackService_Reset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StackServiceServer).Reset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StackService_Reset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StackServiceServer).Reset(ctx, req.(*ResetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StackService_SetMaximumDepth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetMaximumDepthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StackServiceServer).SetMaximumDepth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StackService_SetMaximumDepth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StackServiceServer).SetMaximumDepth(ctx, req.(*SetMaximumDepthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StackService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StackServiceServer).Watch(m, &grpc.GenericServerStream[WatchRequest, StackEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StackService_WatchServer = grpc.ServerStreamingServer[StackEvent]
+
+// StackService_ServiceDesc is the grpc.ServiceDesc for StackService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var StackService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "blorticus.stack.v1.StackService",
+	HandlerType: (*StackServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Push",
+			Handler:    _StackService_Push_Handler,
+		},
+		{
+			MethodName: "Pop",
+			Handler:    _StackService_Pop_Handler,
+		},
+		{
+			MethodName: "Peek",
+			Handler:    _StackService_Peek_Handler,
+		},
+		{
+			MethodName: "Depth",
+			Handler:    _StackService_Depth_Handler,
+		},
+		{
+			MethodName: "Reset",
+			Handler:    _StackService_Reset_Handler,
+		},
+		{
+			MethodName: "SetMaximumDepth",
+			Handler:    _StackService_SetMaximumDepth_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _StackService_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "stack.proto",
+}
@@ -0,0 +1,195 @@
+package stack
+
+// ImmutableStack is a persistent LIFO stack of arbitrary, untyped values.  An
+// ImmutableStack is never changed.  Instead, Push() and Pop() return a new version
+// of the stack that shares all unchanged elements with the version from which it was
+// made, so keeping a snapshot of a stack costs nothing more than keeping a reference
+// to it.  Because it is never changed, an ImmutableStack is safe for concurrent use.
+type ImmutableStack struct {
+	top          *immutableStackNode
+	depth        uint
+	maximumDepth uint
+}
+
+type immutableStackNode struct {
+	value interface{}
+	below *immutableStackNode
+}
+
+// NewImmutableStack returns an empty immutable stack.
+func NewImmutableStack() *ImmutableStack {
+	return &ImmutableStack{}
+}
+
+// NewImmutableStackFrom returns an immutable stack with the same contents and maximum
+// depth as the provided stack.  The stack cannot be changed while its contents are
+// copied.  An immutable stack cannot discard elements, so the maximum depth of a
+// discarding stack becomes an ordinary maximum depth.
+func NewImmutableStackFrom(stack *Stack) *ImmutableStack {
+	release := stack.holdManipulator()
+	defer release()
+
+	immutableStack := &ImmutableStack{maximumDepth: stack.manipulator.maximumStackDepth}
+
+	backend := stack.manipulator.backend
+	for distanceFromTop := backend.Depth(); distanceFromTop > 0; distanceFromTop-- {
+		immutableStack = immutableStack.pushedWith(backend.ElementAt(distanceFromTop - 1))
+	}
+
+	return immutableStack
+}
+
+// ToStack returns a new (mutable) Stack with the same contents and maximum depth
+// as this immutable stack.
+func (immutableStack *ImmutableStack) ToStack() *Stack {
+	backend := NewRingBackend(immutableStack.depth)
+	for _, value := range immutableStack.valuesFromBottom() {
+		backend.PushOnTop(value)
+	}
+
+	options := []Option{WithBackend(backend)}
+	if immutableStack.maximumDepth > 0 {
+		options = append(options, WithMaxDepth(immutableStack.maximumDepth))
+	}
+
+	return mustNew(options...)
+}
+
+// WithAMaximumDepthOf returns a version of this stack that allows no more than the
+// specified number of elements.  If this stack has more elements than that, the
+// returned version omits the elements between the top of the stack and the new
+// maximum.  This method will panic if an attempt is made to set a maximum depth
+// of zero.
+func (immutableStack *ImmutableStack) WithAMaximumDepthOf(maximumNumberOfAllowedElements uint) *ImmutableStack {
+	if maximumNumberOfAllowedElements < 1 {
+		panic("stack size must be at least 1")
+	}
+
+	bounded := &ImmutableStack{
+		top:          immutableStack.top,
+		depth:        immutableStack.depth,
+		maximumDepth: maximumNumberOfAllowedElements,
+	}
+
+	for bounded.depth > maximumNumberOfAllowedElements {
+		bounded.top = bounded.top.below
+		bounded.depth--
+	}
+
+	return bounded
+}
+
+// RemoveMaximumDepth returns a version of this stack without a maximum depth.
+func (immutableStack *ImmutableStack) RemoveMaximumDepth() *ImmutableStack {
+	return &ImmutableStack{
+		top:          immutableStack.top,
+		depth:        immutableStack.depth,
+		maximumDepth: 0,
+	}
+}
+
+// Push returns a version of this stack with the value added to the top.  If this
+// stack has a maximum depth and is full, Push() returns this stack unchanged and true.
+// Otherwise, it returns the new version and false.
+func (immutableStack *ImmutableStack) Push(value interface{}) (pushedStack *ImmutableStack, cannotPushBecauseStackIsFull bool) {
+	if immutableStack.maximumDepth > 0 && immutableStack.depth >= immutableStack.maximumDepth {
+		return immutableStack, true
+	}
+
+	return immutableStack.pushedWith(value), false
+}
+
+// Pop returns the value at the top of this stack and a version of the stack without
+// that value.  If this stack is empty, Pop returns an undefined value, this stack
+// and true.  Otherwise, the last returned value is false.
+func (immutableStack *ImmutableStack) Pop() (value interface{}, poppedStack *ImmutableStack, stackWasEmptyBeforePop bool) {
+	if immutableStack.depth == 0 {
+		return nil, immutableStack, true
+	}
+
+	return immutableStack.top.value, &ImmutableStack{
+		top:          immutableStack.top.below,
+		depth:        immutableStack.depth - 1,
+		maximumDepth: immutableStack.maximumDepth,
+	}, false
+}
+
+// Peek returns the value at the top of this stack.  If this stack is empty, Peek
+// returns an undefined value and true.  Otherwise, it returns the value and false.
+func (immutableStack *ImmutableStack) Peek() (value interface{}, stackIsEmpty bool) {
+	if immutableStack.depth == 0 {
+		return nil, true
+	}
+
+	return immutableStack.top.value, false
+}
+
+// PopUint is a convenience function that will typecast the returned value as a uint.
+// Naturally, if the element isn't really a uint, a runtime error will be raised.
+func (immutableStack *ImmutableStack) PopUint() (uint, *ImmutableStack, bool) {
+	v, s, b := immutableStack.Pop()
+	if v == nil {
+		return 0, s, b
+	}
+	return v.(uint), s, b
+}
+
+// PopInt is a convenience function that will typecast the returned value as an int.
+// Naturally, if the element isn't really an int, a runtime error will be raised.
+func (immutableStack *ImmutableStack) PopInt() (int, *ImmutableStack, bool) {
+	v, s, b := immutableStack.Pop()
+	if v == nil {
+		return 0, s, b
+	}
+	return v.(int), s, b
+}
+
+// PopByte is a convenience function that will typecast the returned value as a byte.
+// Naturally, if the element isn't really a byte, a runtime error will be raised.
+func (immutableStack *ImmutableStack) PopByte() (byte, *ImmutableStack, bool) {
+	v, s, b := immutableStack.Pop()
+	if v == nil {
+		return 0, s, b
+	}
+	return v.(byte), s, b
+}
+
+// PopString is a convenience function that will typecast the returned value as a string.
+// Naturally, if the element isn't really a string, a runtime error will be raised.
+func (immutableStack *ImmutableStack) PopString() (string, *ImmutableStack, bool) {
+	v, s, b := immutableStack.Pop()
+	if v == nil {
+		return "", s, b
+	}
+	return v.(string), s, b
+}
+
+// Depth returns the number of values on this stack.
+func (immutableStack *ImmutableStack) Depth() uint {
+	return immutableStack.depth
+}
+
+// IsEmpty returns true if this stack is empty (i.e., the depth is 0), or false otherwise.
+func (immutableStack *ImmutableStack) IsEmpty() bool {
+	return immutableStack.depth == 0
+}
+
+func (immutableStack *ImmutableStack) pushedWith(value interface{}) *ImmutableStack {
+	return &ImmutableStack{
+		top:          &immutableStackNode{value: value, below: immutableStack.top},
+		depth:        immutableStack.depth + 1,
+		maximumDepth: immutableStack.maximumDepth,
+	}
+}
+
+func (immutableStack *ImmutableStack) valuesFromBottom() []interface{} {
+	values := make([]interface{}, immutableStack.depth)
+
+	node := immutableStack.top
+	for i := len(values) - 1; i >= 0; i-- {
+		values[i] = node.value
+		node = node.below
+	}
+
+	return values
+}
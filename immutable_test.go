@@ -0,0 +1,101 @@
+package stack_test
+
+import (
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	. "github.com/onsi/gomega"
+)
+
+func TestImmutableStack(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	empty := stack.NewImmutableStack()
+	g.Expect(empty.IsEmpty()).To(BeTrue())
+
+	v, s, stackWasEmpty := empty.Pop()
+	g.Expect(v).To(BeNil())
+	g.Expect(s).To(Equal(empty))
+	g.Expect(stackWasEmpty).To(BeTrue())
+
+	one, stackWasFull := empty.Push("first")
+	g.Expect(stackWasFull).To(BeFalse())
+	two, _ := one.Push("second")
+	branch, _ := one.Push("branch")
+
+	g.Expect(empty.Depth()).To(Equal(uint(0)))
+	g.Expect(one.Depth()).To(Equal(uint(1)))
+	g.Expect(two.Depth()).To(Equal(uint(2)))
+	g.Expect(branch.Depth()).To(Equal(uint(2)))
+
+	top, stackIsEmpty := two.Peek()
+	g.Expect(top).To(Equal("second"))
+	g.Expect(stackIsEmpty).To(BeFalse())
+
+	value, afterPop, stackWasEmpty := two.PopString()
+	g.Expect(value).To(Equal("second"))
+	g.Expect(stackWasEmpty).To(BeFalse())
+	g.Expect(afterPop.Depth()).To(Equal(uint(1)))
+
+	value, _, _ = branch.PopString()
+	g.Expect(value).To(Equal("branch"))
+
+	value, _, _ = afterPop.PopString()
+	g.Expect(value).To(Equal("first"))
+}
+
+func TestImmutableStackMaximumDepth(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s := stack.NewImmutableStack()
+	for _, v := range []int{1, 2, 3, 4} {
+		s, _ = s.Push(v)
+	}
+
+	bounded := s.WithAMaximumDepthOf(2)
+	g.Expect(bounded.Depth()).To(Equal(uint(2)))
+	g.Expect(s.Depth()).To(Equal(uint(4)))
+
+	unchanged, stackWasFull := bounded.Push(5)
+	g.Expect(stackWasFull).To(BeTrue())
+	g.Expect(unchanged).To(BeIdenticalTo(bounded))
+
+	value, _, _ := bounded.PopInt()
+	g.Expect(value).To(Equal(2))
+
+	unbounded, stackWasFull := bounded.RemoveMaximumDepth().Push(5)
+	g.Expect(stackWasFull).To(BeFalse())
+	g.Expect(unbounded.Depth()).To(Equal(uint(3)))
+
+	if functionDidPanic := testForPanic(func() { s.WithAMaximumDepthOf(0) }); !functionDidPanic {
+		t.Errorf("On ImmutableStack WithAMaximumDepthOf 0 expected panic, did not panic")
+	}
+}
+
+func TestImmutableStackConversion(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	mutable := stack.NewStack().WithAMaximumDepthOf(3)
+	mutable.Push("first")
+	mutable.Push("second")
+
+	immutable := stack.NewImmutableStackFrom(mutable)
+	mutable.Push("third")
+
+	g.Expect(immutable.Depth()).To(Equal(uint(2)))
+	value, _, _ := immutable.PopString()
+	g.Expect(value).To(Equal("second"))
+
+	converted := immutable.ToStack()
+	for _, testCase := range []*stackOperationTestCase{
+		{testname: "Converted Push third", operation: "push", valueToPush: "third", expectStackToHaveBeenFull: false, expectedStackDepthAfterOperation: 3},
+		{testname: "Converted Push fourth", operation: "push", valueToPush: "fourth", expectStackToHaveBeenFull: true, expectedStackDepthAfterOperation: 3},
+		{testname: "Converted First Pop", operation: "pop", expectedPopValue: "third", expectStackToHaveBeenEmpty: false, expectedStackDepthAfterOperation: 2},
+		{testname: "Converted Second Pop", operation: "pop", expectedPopValue: "second", expectStackToHaveBeenEmpty: false, expectedStackDepthAfterOperation: 1},
+		{testname: "Converted Third Pop", operation: "pop", expectedPopValue: "first", expectStackToHaveBeenEmpty: false, expectedStackDepthAfterOperation: 0},
+	} {
+		testCase.evaluateAgainstStack(converted, g)
+	}
+
+	g.Expect(stack.NewImmutableStackFrom(stack.NewStack()).ToStack().IsEmpty()).To(BeTrue())
+}
@@ -0,0 +1,267 @@
+package linearize
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Specification describes the sequential behavior of the stack against which a history
+// is checked.  The zero value is an unbounded stack.
+type Specification struct {
+	// MaximumDepth is the maximum number of elements allowed in the stack, or 0 if there
+	// is no maximum.
+	MaximumDepth uint
+
+	// DiscardsOldest is true for a discarding stack, which evicts its bottom element
+	// when a value is pushed while it is full.  It requires a MaximumDepth.
+	DiscardsOldest bool
+
+	// InitialContents are the elements on the stack before the history begins, from the
+	// bottom of the stack to the top.
+	InitialContents []interface{}
+}
+
+// Check returns true if the operations, which may be in any order, are linearizable
+// with respect to the specification.  If they are, it also returns the operations in a
+// sequential order that the specification allows and that respects their real-time
+// order.  Values are compared with reflect.DeepEqual().
+func Check(specification Specification, operations []Operation) (linearization []Operation, historyIsLinearizable bool) {
+	checker := newChecker(specification, operations)
+	if !checker.search() {
+		return nil, false
+	}
+
+	linearization = make([]Operation, len(checker.linearizedCalls))
+	for i, call := range checker.linearizedCalls {
+		linearization[i] = operations[call.entry.operationIndex]
+	}
+
+	return linearization, true
+}
+
+// historyEntry is the invocation or the response of an operation.  The entries form a
+// doubly-linked list, in time order, from which an invocation and its response are
+// removed together when the operation is linearized, and to which they are restored
+// when the search backtracks.
+type historyEntry struct {
+	operationIndex int
+	isInvocation   bool
+	time           int64
+	response       *historyEntry
+	previous       *historyEntry
+	next           *historyEntry
+}
+
+type linearizedCall struct {
+	entry         *historyEntry
+	previousState []interface{}
+}
+
+type cachedConfiguration struct {
+	linearized bitset
+	state      []interface{}
+}
+
+type checker struct {
+	specification   Specification
+	operations      []Operation
+	head            *historyEntry
+	linearizedCalls []linearizedCall
+	cache           map[uint64][]cachedConfiguration
+}
+
+func newChecker(specification Specification, operations []Operation) *checker {
+	entries := make([]*historyEntry, 0, 2*len(operations))
+	for i, operation := range operations {
+		response := &historyEntry{operationIndex: i, time: int64(operation.Response)}
+		invocation := &historyEntry{operationIndex: i, isInvocation: true, time: int64(operation.Invocation), response: response}
+		entries = append(entries, invocation, response)
+	}
+
+	// When an invocation and a response have the same time, the invocation is placed
+	// first, so that the operations are treated as concurrent.
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].time != entries[j].time {
+			return entries[i].time < entries[j].time
+		}
+		return entries[i].isInvocation && !entries[j].isInvocation
+	})
+
+	head := &historyEntry{}
+	previous := head
+	for _, entry := range entries {
+		entry.previous = previous
+		previous.next = entry
+		previous = entry
+	}
+
+	return &checker{
+		specification: specification,
+		operations:    operations,
+		head:          head,
+		cache:         make(map[uint64][]cachedConfiguration),
+	}
+}
+
+// search performs the Wing and Gong search.  At each step, it tries to linearize the
+// earliest operation that has not yet been linearized and whose invocation precedes
+// every remaining response.  If no such operation can be linearized, it backtracks.
+func (checker *checker) search() bool {
+	state := append([]interface{}(nil), checker.specification.InitialContents...)
+	linearized := newBitset(uint(len(checker.operations)))
+
+	entry := checker.head.next
+	for checker.head.next != nil {
+		if entry.isInvocation {
+			nextState, operationIsAllowed := checker.step(state, checker.operations[entry.operationIndex])
+			if operationIsAllowed {
+				nextLinearized := linearized.clone().set(uint(entry.operationIndex))
+				if checker.cacheIfNew(nextLinearized, nextState) {
+					checker.linearizedCalls = append(checker.linearizedCalls, linearizedCall{entry, state})
+					state, linearized = nextState, nextLinearized
+					lift(entry)
+					entry = checker.head.next
+					continue
+				}
+			}
+
+			entry = entry.next
+			continue
+		}
+
+		// The earliest remaining response belongs to an operation that could not be
+		// linearized, so the most recent choice must be undone.
+		if len(checker.linearizedCalls) == 0 {
+			return false
+		}
+
+		call := checker.linearizedCalls[len(checker.linearizedCalls)-1]
+		checker.linearizedCalls = checker.linearizedCalls[:len(checker.linearizedCalls)-1]
+
+		state = call.previousState
+		linearized = linearized.clone().clear(uint(call.entry.operationIndex))
+		unlift(call.entry)
+		entry = call.entry.next
+	}
+
+	return true
+}
+
+// cacheIfNew records that the search has reached a configuration, and returns true if
+// it had not reached the configuration before.
+func (checker *checker) cacheIfNew(linearized bitset, state []interface{}) bool {
+	hash := linearized.hash()
+	for _, cached := range checker.cache[hash] {
+		if cached.linearized.equals(linearized) && reflect.DeepEqual(cached.state, state) {
+			return false
+		}
+	}
+
+	checker.cache[hash] = append(checker.cache[hash], cachedConfiguration{linearized, state})
+	return true
+}
+
+// step applies an operation to a state, which holds the stack contents from the bottom.
+// It returns the resulting state and true if the specification allows the operation,
+// with the result that it had, in that state.  The provided state is not changed.
+func (checker *checker) step(state []interface{}, operation Operation) (nextState []interface{}, operationIsAllowed bool) {
+	maximumDepth := checker.specification.MaximumDepth
+	depth := uint(len(state))
+
+	switch operation.Kind {
+	case Push:
+		if checker.specification.DiscardsOldest {
+			nextState = append([]interface{}(nil), state...)
+			if depth >= maximumDepth {
+				nextState = nextState[1:]
+			}
+			nextState = append(nextState, operation.Value)
+			return nextState, operation.StackIsEmptyOrFull == (uint(len(nextState)) >= maximumDepth)
+		}
+
+		if maximumDepth > 0 && depth >= maximumDepth {
+			return state, operation.StackIsEmptyOrFull
+		}
+
+		nextState = append(append([]interface{}(nil), state...), operation.Value)
+		return nextState, !operation.StackIsEmptyOrFull
+
+	case Pop:
+		if depth == 0 {
+			return state, operation.StackIsEmptyOrFull
+		}
+
+		if operation.StackIsEmptyOrFull || !reflect.DeepEqual(state[depth-1], operation.Value) {
+			return nil, false
+		}
+
+		return state[: depth-1 : depth-1], true
+
+	case Depth:
+		return state, operation.Depth == depth
+
+	default:
+		return nil, false
+	}
+}
+
+func lift(invocation *historyEntry) {
+	invocation.previous.next = invocation.next
+	invocation.next.previous = invocation.previous
+
+	response := invocation.response
+	response.previous.next = response.next
+	if response.next != nil {
+		response.next.previous = response.previous
+	}
+}
+
+func unlift(invocation *historyEntry) {
+	response := invocation.response
+	response.previous.next = response
+	if response.next != nil {
+		response.next.previous = response
+	}
+
+	invocation.previous.next = invocation
+	invocation.next.previous = invocation
+}
+
+// bitset records which operations have been linearized.
+type bitset []uint64
+
+func newBitset(numberOfBits uint) bitset {
+	return make(bitset, (numberOfBits+63)/64)
+}
+
+func (b bitset) clone() bitset {
+	return append(bitset(nil), b...)
+}
+
+func (b bitset) set(bit uint) bitset {
+	b[bit/64] |= 1 << (bit % 64)
+	return b
+}
+
+func (b bitset) clear(bit uint) bitset {
+	b[bit/64] &^= 1 << (bit % 64)
+	return b
+}
+
+func (b bitset) equals(other bitset) bool {
+	for i := range b {
+		if b[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (b bitset) hash() uint64 {
+	hash := uint64(14695981039346656037)
+	for _, word := range b {
+		hash ^= word
+		hash *= 1099511628211
+	}
+	return hash
+}
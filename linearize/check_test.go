@@ -0,0 +1,171 @@
+package linearize_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blorticus-go/stack/linearize"
+)
+
+// op returns an operation that was invoked and returned at the provided times.
+func op(kind linearize.OperationKind, value interface{}, stackIsEmptyOrFull bool, depth uint, invocation, response time.Duration) linearize.Operation {
+	return linearize.Operation{Kind: kind, Value: value, StackIsEmptyOrFull: stackIsEmptyOrFull, Depth: depth, Invocation: invocation, Response: response}
+}
+
+func TestCheck(t *testing.T) {
+	for _, testCase := range []struct {
+		testName                 string
+		specification            linearize.Specification
+		operations               []linearize.Operation
+		expectLinearizable       bool
+		expectedLinearizedValues []interface{}
+	}{
+		{
+			testName:           "empty history",
+			expectLinearizable: true,
+		},
+		{
+			testName: "sequential push and pop",
+			operations: []linearize.Operation{
+				op(linearize.Push, "a", false, 0, 0, 1),
+				op(linearize.Push, "b", false, 0, 2, 3),
+				op(linearize.Pop, "b", false, 0, 4, 5),
+				op(linearize.Depth, nil, false, 1, 6, 7),
+				op(linearize.Pop, "a", false, 0, 8, 9),
+				op(linearize.Pop, nil, true, 0, 10, 11),
+			},
+			expectLinearizable:       true,
+			expectedLinearizedValues: []interface{}{"a", "b", "b", nil, "a", nil},
+		},
+		{
+			testName: "overlapping pushes may be ordered either way",
+			operations: []linearize.Operation{
+				op(linearize.Push, "a", false, 0, 0, 10),
+				op(linearize.Push, "b", false, 0, 1, 9),
+				op(linearize.Pop, "a", false, 0, 11, 12),
+				op(linearize.Pop, "b", false, 0, 13, 14),
+			},
+			expectLinearizable:       true,
+			expectedLinearizedValues: []interface{}{"b", "a", "a", "b"},
+		},
+		{
+			testName: "pop that overlaps a push may see it",
+			operations: []linearize.Operation{
+				op(linearize.Pop, "a", false, 0, 0, 10),
+				op(linearize.Push, "a", false, 0, 5, 6),
+			},
+			expectLinearizable: true,
+		},
+		{
+			testName: "pop returns a value pushed earlier than the top",
+			operations: []linearize.Operation{
+				op(linearize.Push, "a", false, 0, 0, 1),
+				op(linearize.Push, "b", false, 0, 2, 3),
+				op(linearize.Pop, "a", false, 0, 4, 5),
+			},
+		},
+		{
+			testName: "pop returns a value that was never pushed",
+			operations: []linearize.Operation{
+				op(linearize.Push, "a", false, 0, 0, 1),
+				op(linearize.Pop, "z", false, 0, 2, 3),
+			},
+		},
+		{
+			testName: "pop of a value pushed after the pop returned",
+			operations: []linearize.Operation{
+				op(linearize.Pop, "a", false, 0, 0, 1),
+				op(linearize.Push, "a", false, 0, 2, 3),
+			},
+		},
+		{
+			testName: "pop reports empty stack when it cannot be",
+			operations: []linearize.Operation{
+				op(linearize.Push, "a", false, 0, 0, 1),
+				op(linearize.Pop, nil, true, 0, 2, 3),
+			},
+		},
+		{
+			testName: "value popped twice",
+			operations: []linearize.Operation{
+				op(linearize.Push, "a", false, 0, 0, 1),
+				op(linearize.Pop, "a", false, 0, 2, 5),
+				op(linearize.Pop, "a", false, 0, 3, 4),
+			},
+		},
+		{
+			testName: "wrong depth",
+			operations: []linearize.Operation{
+				op(linearize.Push, "a", false, 0, 0, 1),
+				op(linearize.Depth, nil, false, 2, 2, 3),
+			},
+		},
+		{
+			testName:      "bounded stack rejects push when full",
+			specification: linearize.Specification{MaximumDepth: 1, InitialContents: []interface{}{"x"}},
+			operations: []linearize.Operation{
+				op(linearize.Push, "a", true, 0, 0, 1),
+				op(linearize.Pop, "x", false, 0, 2, 3),
+				op(linearize.Push, "b", false, 0, 4, 5),
+			},
+			expectLinearizable: true,
+		},
+		{
+			testName:      "bounded stack accepts push when full",
+			specification: linearize.Specification{MaximumDepth: 1, InitialContents: []interface{}{"x"}},
+			operations: []linearize.Operation{
+				op(linearize.Push, "a", false, 0, 0, 1),
+			},
+		},
+		{
+			testName:      "discarding stack evicts its bottom",
+			specification: linearize.Specification{MaximumDepth: 2, DiscardsOldest: true},
+			operations: []linearize.Operation{
+				op(linearize.Push, "a", false, 0, 0, 1),
+				op(linearize.Push, "b", true, 0, 2, 3),
+				op(linearize.Push, "c", true, 0, 4, 5),
+				op(linearize.Pop, "c", false, 0, 6, 7),
+				op(linearize.Pop, "b", false, 0, 8, 9),
+				op(linearize.Pop, nil, true, 0, 10, 11),
+			},
+			expectLinearizable: true,
+		},
+		{
+			testName:      "discarding stack keeps its bottom",
+			specification: linearize.Specification{MaximumDepth: 2, DiscardsOldest: true},
+			operations: []linearize.Operation{
+				op(linearize.Push, "a", false, 0, 0, 1),
+				op(linearize.Push, "b", true, 0, 2, 3),
+				op(linearize.Push, "c", true, 0, 4, 5),
+				op(linearize.Depth, nil, false, 3, 6, 7),
+			},
+		},
+	} {
+		linearization, isLinearizable := linearize.Check(testCase.specification, testCase.operations)
+
+		if isLinearizable != testCase.expectLinearizable {
+			t.Errorf("[%s] expected linearizable (%t), got (%t)", testCase.testName, testCase.expectLinearizable, isLinearizable)
+			continue
+		}
+
+		if !isLinearizable {
+			if linearization != nil {
+				t.Errorf("[%s] expected no linearization, got (%v)", testCase.testName, linearization)
+			}
+			continue
+		}
+
+		if len(linearization) != len(testCase.operations) {
+			t.Errorf("[%s] expected linearization of (%d) operations, got (%d)", testCase.testName, len(testCase.operations), len(linearization))
+			continue
+		}
+
+		if testCase.expectedLinearizedValues != nil {
+			for i, operation := range linearization {
+				if operation.Value != testCase.expectedLinearizedValues[i] {
+					t.Errorf("[%s] expected operation (%d) of linearization to have value (%v), got (%s)", testCase.testName, i, testCase.expectedLinearizedValues[i], operation)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Package linearize checks that a stack implementation is linearizable, which is to say
+// that every concurrent history of operations on it is equivalent to some sequential
+// history of the same operations that respects their real-time order and is allowed by
+// the sequential specification of a stack.
+//
+// A History records Push(), Pop() and Depth() operations, with the times at which each
+// was invoked and returned, as goroutines perform them on a stack.  Check() then
+// searches for a valid sequential order using the algorithm of Wing and Gong, with the
+// memoization of visited states described by Lowe, as in the Porcupine checker.  The
+// search is exponential in the worst case, so histories should be kept to a few hundred
+// operations, and pushed values should be distinct, which greatly reduces the number of
+// orders that must be considered.
+package linearize
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Stack is the subset of stack operations that a History records.  A stack.Stack
+// satisfies it, and other stacks can be adapted to it.
+type Stack interface {
+	Push(value interface{}) (cannotPushBecauseStackIsFull bool)
+	Pop() (value interface{}, stackWasEmptyBeforePop bool)
+	Depth() uint
+}
+
+// OperationKind identifies an operation in a History.
+type OperationKind int
+
+const (
+	Push OperationKind = iota
+	Pop
+	Depth
+)
+
+func (kind OperationKind) String() string {
+	switch kind {
+	case Push:
+		return "Push"
+	case Pop:
+		return "Pop"
+	case Depth:
+		return "Depth"
+	default:
+		return fmt.Sprintf("OperationKind(%d)", int(kind))
+	}
+}
+
+// Operation is a single operation in a History.
+type Operation struct {
+	// Client identifies the goroutine, or other caller, that performed the operation.
+	Client int
+
+	Kind OperationKind
+
+	// Value is the value pushed by a Push, or returned by a Pop.
+	Value interface{}
+
+	// StackIsEmptyOrFull is the boolean returned by a Push or a Pop to indicate that the
+	// stack was full or empty.
+	StackIsEmptyOrFull bool
+
+	// Depth is the depth returned by a Depth.
+	Depth uint
+
+	// Invocation and Response are the times at which the operation was invoked and at
+	// which it returned, measured from the creation of the History.
+	Invocation time.Duration
+	Response   time.Duration
+}
+
+func (operation Operation) String() string {
+	switch operation.Kind {
+	case Push:
+		return fmt.Sprintf("client %d: Push(%v) -> %t", operation.Client, operation.Value, operation.StackIsEmptyOrFull)
+	case Pop:
+		return fmt.Sprintf("client %d: Pop() -> (%v, %t)", operation.Client, operation.Value, operation.StackIsEmptyOrFull)
+	default:
+		return fmt.Sprintf("client %d: %s() -> %d", operation.Client, operation.Kind, operation.Depth)
+	}
+}
+
+// History records operations performed on a stack.  Its methods may be called
+// concurrently.
+type History struct {
+	mutex       sync.Mutex
+	timeOfStart time.Time
+	operations  []Operation
+}
+
+// NewHistory returns a History with no operations.
+func NewHistory() *History {
+	return &History{timeOfStart: time.Now()}
+}
+
+// Push pushes a value onto the stack on behalf of the client, records the operation and
+// returns what the stack returned.
+func (history *History) Push(s Stack, client int, value interface{}) (cannotPushBecauseStackIsFull bool) {
+	invocation := time.Since(history.timeOfStart)
+	cannotPushBecauseStackIsFull = s.Push(value)
+	response := time.Since(history.timeOfStart)
+
+	history.add(Operation{Client: client, Kind: Push, Value: value, StackIsEmptyOrFull: cannotPushBecauseStackIsFull, Invocation: invocation, Response: response})
+
+	return cannotPushBecauseStackIsFull
+}
+
+// Pop pops a value from the stack on behalf of the client, records the operation and
+// returns what the stack returned.
+func (history *History) Pop(s Stack, client int) (value interface{}, stackWasEmptyBeforePop bool) {
+	invocation := time.Since(history.timeOfStart)
+	value, stackWasEmptyBeforePop = s.Pop()
+	response := time.Since(history.timeOfStart)
+
+	if stackWasEmptyBeforePop {
+		value = nil
+	}
+
+	history.add(Operation{Client: client, Kind: Pop, Value: value, StackIsEmptyOrFull: stackWasEmptyBeforePop, Invocation: invocation, Response: response})
+
+	return value, stackWasEmptyBeforePop
+}
+
+// Depth gets the depth of the stack on behalf of the client, records the operation and
+// returns the depth.
+func (history *History) Depth(s Stack, client int) uint {
+	invocation := time.Since(history.timeOfStart)
+	depth := s.Depth()
+	response := time.Since(history.timeOfStart)
+
+	history.add(Operation{Client: client, Kind: Depth, Depth: depth, Invocation: invocation, Response: response})
+
+	return depth
+}
+
+// Add adds an operation that was recorded by other means.
+func (history *History) Add(operation Operation) {
+	history.add(operation)
+}
+
+// Operations returns the recorded operations, in the order in which they were invoked.
+func (history *History) Operations() []Operation {
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+
+	operations := append([]Operation(nil), history.operations...)
+	sort.SliceStable(operations, func(i, j int) bool { return operations[i].Invocation < operations[j].Invocation })
+
+	return operations
+}
+
+func (history *History) add(operation Operation) {
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+
+	history.operations = append(history.operations, operation)
+}
@@ -0,0 +1,216 @@
+package linearize_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	"github.com/blorticus-go/stack/linearize"
+)
+
+// exercise has each of a number of clients perform a mix of pushes, pops and depths on
+// the stack concurrently, and returns the recorded operations.  Each pushed value is
+// distinct, and is made by valueFor() from a distinct integer.
+func exercise(s linearize.Stack, numberOfClients int, valueFor func(n int) interface{}) []linearize.Operation {
+	history := linearize.NewHistory()
+
+	var waitGroup sync.WaitGroup
+	for client := 0; client < numberOfClients; client++ {
+		waitGroup.Add(1)
+		go func(client int) {
+			defer waitGroup.Done()
+			for i := 0; i < 30; i++ {
+				switch i % 5 {
+				case 0, 1, 3:
+					history.Push(s, client, valueFor(client*1000+i))
+				case 2:
+					history.Pop(s, client)
+				case 4:
+					history.Depth(s, client)
+				}
+
+				if i%3 == 0 {
+					history.Pop(s, client)
+				}
+			}
+		}(client)
+	}
+	waitGroup.Wait()
+
+	return history.Operations()
+}
+
+func intValue(n int) interface{} { return n }
+
+func TestHistory(t *testing.T) {
+	s := stack.NewStack()
+	history := linearize.NewHistory()
+
+	history.Push(s, 1, "a")
+	history.Depth(s, 2)
+	history.Pop(s, 1)
+	history.Pop(s, 2)
+
+	operations := history.Operations()
+
+	expected := []string{
+		"client 1: Push(a) -> false",
+		"client 2: Depth() -> 1",
+		"client 1: Pop() -> (a, false)",
+		"client 2: Pop() -> (<nil>, true)",
+	}
+
+	if len(operations) != len(expected) {
+		t.Fatalf("expected (%d) operations, got (%d)", len(expected), len(operations))
+	}
+
+	for i, operation := range operations {
+		if operation.String() != expected[i] {
+			t.Errorf("expected operation (%d) to be (%s), got (%s)", i, expected[i], operation)
+		}
+
+		if operation.Response < operation.Invocation || (i > 0 && operation.Invocation < operations[i-1].Response) {
+			t.Errorf("expected operation (%d) to have been invoked after the previous response and to respond after its invocation", i)
+		}
+	}
+}
+
+type aggregatingStackAdapter struct {
+	*stack.AggregatingStack
+}
+
+func (adapter aggregatingStackAdapter) Push(value interface{}) bool {
+	return adapter.AggregatingStack.Push(value.(float64))
+}
+
+func (adapter aggregatingStackAdapter) Pop() (interface{}, bool) {
+	value, stackWasEmpty := adapter.AggregatingStack.Pop()
+	return value, stackWasEmpty
+}
+
+// queue is a broken stack, which pops from the bottom.
+type queue struct {
+	mutex    sync.Mutex
+	elements []interface{}
+}
+
+func (q *queue) Push(value interface{}) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.elements = append(q.elements, value)
+	return false
+}
+
+func (q *queue) Pop() (interface{}, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if len(q.elements) == 0 {
+		return nil, true
+	}
+
+	value := q.elements[0]
+	q.elements = q.elements[1:]
+	return value, false
+}
+
+func (q *queue) Depth() uint {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return uint(len(q.elements))
+}
+
+type stackUnderTest struct {
+	name          string
+	newStack      func(t *testing.T) linearize.Stack
+	specification linearize.Specification
+	valueFor      func(n int) interface{}
+}
+
+func stacksUnderTest() []stackUnderTest {
+	return []stackUnderTest{
+		{
+			name:     "standard stack",
+			newStack: func(t *testing.T) linearize.Stack { return stack.NewStack() },
+		},
+		{
+			name: "bounded stack",
+			newStack: func(t *testing.T) linearize.Stack {
+				s, _ := stack.New(stack.WithMaxDepth(4))
+				return s
+			},
+			specification: linearize.Specification{MaximumDepth: 4},
+		},
+		{
+			name:          "discarding stack",
+			newStack:      func(t *testing.T) linearize.Stack { return stack.NewBoundedDiscardingStack(4) },
+			specification: linearize.Specification{MaximumDepth: 4, DiscardsOldest: true},
+		},
+		{
+			name: "spilling backend",
+			newStack: func(t *testing.T) linearize.Stack {
+				backend, err := stack.NewSpillingBackend(stack.SpillConfiguration{ElementsInMemory: 4, Directory: t.TempDir()})
+				if err != nil {
+					t.Fatalf("unexpected error on NewSpillingBackend(): %s", err)
+				}
+				t.Cleanup(func() { backend.Close() })
+
+				s, _ := stack.New(stack.WithBackend(backend))
+				return s
+			},
+		},
+		{
+			name: "minmax stack",
+			newStack: func(t *testing.T) linearize.Stack {
+				s, _ := stack.NewMinMaxStack(func(a, b interface{}) bool { return a.(int) < b.(int) }, stack.WithMaxDepth(6), stack.WithDiscardOldest())
+				return s
+			},
+			specification: linearize.Specification{MaximumDepth: 6, DiscardsOldest: true},
+		},
+		{
+			name: "aggregating stack",
+			newStack: func(t *testing.T) linearize.Stack {
+				s, _ := stack.NewAggregatingStack()
+				return aggregatingStackAdapter{s}
+			},
+			valueFor: func(n int) interface{} { return float64(n) },
+		},
+	}
+}
+
+func TestStacksAreLinearizable(t *testing.T) {
+	for _, underTest := range stacksUnderTest() {
+		valueFor := underTest.valueFor
+		if valueFor == nil {
+			valueFor = intValue
+		}
+
+		for round := 0; round < 5; round++ {
+			operations := exercise(underTest.newStack(t), 4, valueFor)
+
+			if _, isLinearizable := linearize.Check(underTest.specification, operations); !isLinearizable {
+				t.Errorf("[%s] expected history to be linearizable, but it is not:\n%s", underTest.name, describe(operations))
+				break
+			}
+		}
+	}
+}
+
+func TestBrokenStackIsNotLinearizable(t *testing.T) {
+	operations := exercise(&queue{}, 1, intValue)
+
+	if linearization, isLinearizable := linearize.Check(linearize.Specification{}, operations); isLinearizable {
+		t.Errorf("expected history of queue not to be linearizable, got linearization:\n%s", describe(linearization))
+	}
+}
+
+func describe(operations []linearize.Operation) string {
+	description := ""
+	for _, operation := range operations {
+		description += fmt.Sprintf("  [%d, %d] %s\n", operation.Invocation, operation.Response, operation)
+	}
+	return description
+}
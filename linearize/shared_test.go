@@ -0,0 +1,47 @@
+//go:build unix
+
+package linearize_test
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	"github.com/blorticus-go/stack/linearize"
+)
+
+type sharedStackAdapter struct {
+	*stack.SharedStack
+}
+
+func (adapter sharedStackAdapter) Push(value interface{}) bool {
+	return adapter.SharedStack.Push([]byte(value.(string)))
+}
+
+func (adapter sharedStackAdapter) Pop() (interface{}, bool) {
+	value, stackWasEmpty := adapter.SharedStack.Pop()
+	if stackWasEmpty {
+		return nil, true
+	}
+	return string(value), false
+}
+
+func TestSharedStackIsLinearizable(t *testing.T) {
+	for _, discardsOldest := range []bool{false, true} {
+		layout := stack.SharedStackLayout{MaximumDepth: 4, MaximumElementSize: 16, DiscardsOldest: discardsOldest}
+
+		s, err := stack.OpenSharedStack(filepath.Join(t.TempDir(), "shared"), layout)
+		if err != nil {
+			t.Fatalf("unexpected error on OpenSharedStack(): %s", err)
+		}
+		defer s.Close()
+
+		operations := exercise(sharedStackAdapter{s}, 4, func(n int) interface{} { return strconv.Itoa(n) })
+
+		specification := linearize.Specification{MaximumDepth: 4, DiscardsOldest: discardsOldest}
+		if _, isLinearizable := linearize.Check(specification, operations); !isLinearizable {
+			t.Errorf("[discarding %t] expected history to be linearizable, but it is not:\n%s", discardsOldest, describe(operations))
+		}
+	}
+}
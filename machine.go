@@ -0,0 +1,150 @@
+package stack
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrStackUnderflow is returned, possibly wrapped, when an operation requires more
+// elements than the stack contains.
+var ErrStackUnderflow = errors.New("stack underflow")
+
+// ErrStackOverflow is returned, possibly wrapped, when an operation would add elements
+// to a stack that has a maximum depth and does not have room for them.
+var ErrStackOverflow = errors.New("stack overflow")
+
+// The following are the primitive operations of a stack machine, such as the data stack
+// of a Forth interpreter.  Each is performed as a single operation, so no other
+// operation can change the stack while it is underway.  If the stack does not have
+// enough elements for an operation, an error wrapping ErrStackUnderflow is returned.
+// If this is a standard stack with a maximum depth and an operation would exceed it,
+// an error wrapping ErrStackOverflow is returned.  In either case, the stack is not
+// changed.  If this is a discarding stack, an operation that exceeds the maximum
+// depth discards elements from the bottom of the stack, as Push() does.
+
+// Dup pushes a copy of the value at the top of the stack: ( a -- a a ).
+func (stack *Stack) Dup() error {
+	return stack.sendStackMachineOperation(pick, 0)
+}
+
+// Over pushes a copy of the value just below the top of the stack: ( a b -- a b a ).
+func (stack *Stack) Over() error {
+	return stack.sendStackMachineOperation(pick, 1)
+}
+
+// Pick pushes a copy of the value that is n elements below the top of the stack, so
+// that Pick(0) is the same as Dup() and Pick(1) is the same as Over():
+// ( xn ... x1 x0 -- xn ... x1 x0 xn ).
+func (stack *Stack) Pick(n uint) error {
+	return stack.sendStackMachineOperation(pick, n)
+}
+
+// Swap exchanges the two values at the top of the stack: ( a b -- b a ).
+func (stack *Stack) Swap() error {
+	return stack.sendStackMachineOperation(roll, 1)
+}
+
+// Rot moves the third value from the top of the stack to the top: ( a b c -- b c a ).
+func (stack *Stack) Rot() error {
+	return stack.sendStackMachineOperation(roll, 2)
+}
+
+// Roll moves the value that is n elements below the top of the stack to the top, so
+// that Roll(1) is the same as Swap() and Roll(2) is the same as Rot():
+// ( xn xn-1 ... x0 -- xn-1 ... x0 xn ).  Roll(0) does nothing.
+func (stack *Stack) Roll(n uint) error {
+	return stack.sendStackMachineOperation(roll, n)
+}
+
+// Drop silently discards the n values at the top of the stack: ( xn-1 ... x0 -- ).
+func (stack *Stack) Drop(n uint) error {
+	return stack.sendStackMachineOperation(drop, n)
+}
+
+func (stack *Stack) sendStackMachineOperation(operation stackOperation, n uint) error {
+	responseChannel := make(chan *stackManipulationResponse)
+	stack.send(&stackManipulationMessage{
+		operation:       operation,
+		depth:           n,
+		responseChannel: responseChannel,
+	})
+
+	response := stack.receive(responseChannel)
+
+	return response.operationError
+}
+
+func (manipulator *stackManipulator) pick(n uint) error {
+	if err := manipulator.requireElementAtDistanceFromTop(n); err != nil {
+		return err
+	}
+
+	if err := manipulator.requireRoomForOneMoreElement(); err != nil {
+		return err
+	}
+
+	manipulator.push(manipulator.backend.ElementAt(n))
+
+	return nil
+}
+
+func (manipulator *stackManipulator) roll(n uint) error {
+	if err := manipulator.requireElementAtDistanceFromTop(n); err != nil {
+		return err
+	}
+
+	elementsAboveRolledElement := make([]interface{}, n)
+	for i := range elementsAboveRolledElement {
+		elementsAboveRolledElement[i] = manipulator.backend.PopFromTop()
+	}
+
+	rolledElement := manipulator.backend.PopFromTop()
+
+	for i := len(elementsAboveRolledElement) - 1; i >= 0; i-- {
+		manipulator.backend.PushOnTop(elementsAboveRolledElement[i])
+	}
+
+	manipulator.backend.PushOnTop(rolledElement)
+
+	return nil
+}
+
+func (manipulator *stackManipulator) drop(n uint) error {
+	if err := manipulator.requireElements(n); err != nil {
+		return err
+	}
+
+	for i := uint(0); i < n; i++ {
+		manipulator.backend.PopFromTop()
+	}
+
+	return nil
+}
+
+func (manipulator *stackManipulator) requireElements(numberOfRequiredElements uint) error {
+	if depth := manipulator.backend.Depth(); depth < numberOfRequiredElements {
+		return fmt.Errorf("%w: operation requires %d elements but stack has %d", ErrStackUnderflow, numberOfRequiredElements, depth)
+	}
+
+	return nil
+}
+
+func (manipulator *stackManipulator) requireElementAtDistanceFromTop(distanceFromTop uint) error {
+	if depth := manipulator.backend.Depth(); depth <= distanceFromTop {
+		return fmt.Errorf("%w: operation requires %d elements but stack has %d", ErrStackUnderflow, uint64(distanceFromTop)+1, depth)
+	}
+
+	return nil
+}
+
+func (manipulator *stackManipulator) requireRoomForOneMoreElement() error {
+	if manipulator.discardsFIFOAfterMaxSize || manipulator.maximumStackDepth == 0 {
+		return nil
+	}
+
+	if manipulator.backend.Depth() >= manipulator.maximumStackDepth {
+		return fmt.Errorf("%w: stack already has its maximum of %d elements", ErrStackOverflow, manipulator.maximumStackDepth)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package stack_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	. "github.com/onsi/gomega"
+)
+
+func stackOf(values ...interface{}) *stack.Stack {
+	s := stack.NewStack()
+	for _, v := range values {
+		s.Push(v)
+	}
+
+	return s
+}
+
+func contentsFromTopOf(s *stack.Stack) []interface{} {
+	contents := make([]interface{}, 0, s.Depth())
+	for {
+		value, stackWasEmpty := s.Pop()
+		if stackWasEmpty {
+			return contents
+		}
+		contents = append(contents, value)
+	}
+}
+
+func TestStackMachineOperations(t *testing.T) {
+	for _, testCase := range []struct {
+		testname                  string
+		initialContentsFromBottom []interface{}
+		operation                 func(s *stack.Stack) error
+		expectedContentsFromTop   []interface{}
+		expectedError             error
+	}{
+		{"Dup", []interface{}{1, 2}, (*stack.Stack).Dup, []interface{}{2, 2, 1}, nil},
+		{"Dup empty", []interface{}{}, (*stack.Stack).Dup, []interface{}{}, stack.ErrStackUnderflow},
+		{"Over", []interface{}{1, 2}, (*stack.Stack).Over, []interface{}{1, 2, 1}, nil},
+		{"Over one element", []interface{}{1}, (*stack.Stack).Over, []interface{}{1}, stack.ErrStackUnderflow},
+		{"Swap", []interface{}{1, 2, 3}, (*stack.Stack).Swap, []interface{}{2, 3, 1}, nil},
+		{"Swap one element", []interface{}{1}, (*stack.Stack).Swap, []interface{}{1}, stack.ErrStackUnderflow},
+		{"Rot", []interface{}{1, 2, 3}, (*stack.Stack).Rot, []interface{}{1, 3, 2}, nil},
+		{"Rot two elements", []interface{}{1, 2}, (*stack.Stack).Rot, []interface{}{2, 1}, stack.ErrStackUnderflow},
+		{"Pick 2", []interface{}{1, 2, 3}, func(s *stack.Stack) error { return s.Pick(2) }, []interface{}{1, 3, 2, 1}, nil},
+		{"Pick 3", []interface{}{1, 2, 3}, func(s *stack.Stack) error { return s.Pick(3) }, []interface{}{3, 2, 1}, stack.ErrStackUnderflow},
+		{"Pick max", []interface{}{1}, func(s *stack.Stack) error { return s.Pick(^uint(0)) }, []interface{}{1}, stack.ErrStackUnderflow},
+		{"Roll 0", []interface{}{1, 2}, func(s *stack.Stack) error { return s.Roll(0) }, []interface{}{2, 1}, nil},
+		{"Roll 3", []interface{}{1, 2, 3, 4}, func(s *stack.Stack) error { return s.Roll(3) }, []interface{}{1, 4, 3, 2}, nil},
+		{"Roll 4", []interface{}{1, 2, 3, 4}, func(s *stack.Stack) error { return s.Roll(4) }, []interface{}{4, 3, 2, 1}, stack.ErrStackUnderflow},
+		{"Drop 2", []interface{}{1, 2, 3}, func(s *stack.Stack) error { return s.Drop(2) }, []interface{}{1}, nil},
+		{"Drop 0", []interface{}{1}, func(s *stack.Stack) error { return s.Drop(0) }, []interface{}{1}, nil},
+		{"Drop 4", []interface{}{1, 2, 3}, func(s *stack.Stack) error { return s.Drop(4) }, []interface{}{3, 2, 1}, stack.ErrStackUnderflow},
+	} {
+		s := stackOf(testCase.initialContentsFromBottom...)
+
+		err := testCase.operation(s)
+		if testCase.expectedError == nil && err != nil {
+			t.Errorf("[%s] expected no error, got (%s)", testCase.testname, err)
+		} else if testCase.expectedError != nil && !errors.Is(err, testCase.expectedError) {
+			t.Errorf("[%s] expected error (%s), got (%v)", testCase.testname, testCase.expectedError, err)
+		}
+
+		NewGomegaWithT(t).Expect(contentsFromTopOf(s)).To(Equal(testCase.expectedContentsFromTop), testCase.testname)
+	}
+}
+
+func TestStackMachineOperationsOnBoundedStacks(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	bounded := stack.NewStack().WithAMaximumDepthOf(2)
+	bounded.Push(1)
+	g.Expect(bounded.Dup()).To(Succeed())
+
+	err := bounded.Over()
+	g.Expect(errors.Is(err, stack.ErrStackOverflow)).To(BeTrue())
+	g.Expect(bounded.Swap()).To(Succeed())
+	g.Expect(bounded.Depth()).To(Equal(uint(2)))
+
+	discarding := stack.NewBoundedDiscardingStack(2)
+	discarding.Push(1)
+	discarding.Push(2)
+	g.Expect(discarding.Over()).To(Succeed())
+	g.Expect(contentsFromTopOf(discarding)).To(Equal([]interface{}{1, 2}))
+}
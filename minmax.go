@@ -0,0 +1,214 @@
+package stack
+
+import "fmt"
+
+// MinMaxStack is a Stack that also tracks the minimum and maximum of its contents, so
+// that Min() and Max() take constant time.  The ordering of elements is defined by a
+// comparison function.  All Stack operations may be used, including on a discarding
+// MinMaxStack, which evicts elements from its bottom.
+//
+// The familiar technique of keeping, alongside each element, the minimum of that element
+// and all elements below it does not survive eviction from the bottom of the stack, so a
+// MinMaxStack instead stores its elements as two halves, one holding the top of the stack
+// and the other the bottom, each tracking the extremes from the middle of the stack
+// outwards.  When a pop or an eviction empties one half, the remaining elements are split
+// evenly between the halves again, so every operation takes amortized constant time.
+type MinMaxStack struct {
+	*Stack
+}
+
+// NewMinMaxStack returns an empty MinMaxStack ordered by the provided function, which
+// returns true if a is less than b.  Options are applied as they are by New(), except
+// that WithBackend() may not be used.
+func NewMinMaxStack(less func(a, b interface{}) bool, options ...Option) (*MinMaxStack, error) {
+	if less == nil {
+		return nil, fmt.Errorf("comparison function must not be nil")
+	}
+
+	configuration, err := newConfigurationFrom(options)
+	if err != nil {
+		return nil, err
+	}
+
+	if configuration.backend != nil {
+		return nil, fmt.Errorf("a backend may not be set for a MinMaxStack")
+	}
+
+	configuration.backend = newMinMaxBackend(less, configuration.initialCapacity)
+
+	return &MinMaxStack{newStackUsingManipulator(configuration.manipulator())}, nil
+}
+
+// Min returns the smallest value on the stack.  If the stack is empty, Min returns an
+// undefined value and true.  Otherwise, it returns the smallest value and false.
+func (stack *MinMaxStack) Min() (value interface{}, stackIsEmpty bool) {
+	release := stack.holdManipulator()
+	defer release()
+
+	return stack.manipulator.backend.(*minMaxBackend).extreme(func(e *minMaxEntry) interface{} { return e.minimumFromMiddle }, false)
+}
+
+// Max returns the largest value on the stack.  If the stack is empty, Max returns an
+// undefined value and true.  Otherwise, it returns the largest value and false.
+func (stack *MinMaxStack) Max() (value interface{}, stackIsEmpty bool) {
+	release := stack.holdManipulator()
+	defer release()
+
+	return stack.manipulator.backend.(*minMaxBackend).extreme(func(e *minMaxEntry) interface{} { return e.maximumFromMiddle }, true)
+}
+
+// Clone returns a new MinMaxStack that is independent of this one but has identical
+// contents and configuration.
+func (stack *MinMaxStack) Clone() *MinMaxStack {
+	return &MinMaxStack{stack.Stack.Clone()}
+}
+
+type minMaxEntry struct {
+	value             interface{}
+	minimumFromMiddle interface{}
+	maximumFromMiddle interface{}
+}
+
+// minMaxBackend stores the stack as two halves.  The last entry of topHalf is the top
+// of the stack, and the last entry of bottomHalf is the bottom of the stack, so the
+// first entry of each is nearest the middle.  Each entry records the extremes of itself
+// and the entries between it and the middle.
+type minMaxBackend struct {
+	less       func(a, b interface{}) bool
+	topHalf    []*minMaxEntry
+	bottomHalf []*minMaxEntry
+}
+
+func newMinMaxBackend(less func(a, b interface{}) bool, initialCapacity uint) *minMaxBackend {
+	return &minMaxBackend{
+		less:       less,
+		topHalf:    make([]*minMaxEntry, 0, initialCapacity),
+		bottomHalf: make([]*minMaxEntry, 0, initialCapacity/2),
+	}
+}
+
+func (backend *minMaxBackend) PushOnTop(value interface{}) {
+	backend.topHalf = backend.appendedWith(backend.topHalf, value)
+}
+
+func (backend *minMaxBackend) PopFromTop() interface{} {
+	if len(backend.topHalf) == 0 {
+		backend.rebalance()
+	}
+
+	top := backend.topHalf[len(backend.topHalf)-1]
+	backend.topHalf[len(backend.topHalf)-1] = nil
+	backend.topHalf = backend.topHalf[:len(backend.topHalf)-1]
+
+	return top.value
+}
+
+func (backend *minMaxBackend) RemoveFromBottom() interface{} {
+	if len(backend.bottomHalf) == 0 {
+		backend.rebalance()
+	}
+
+	bottom := backend.bottomHalf[len(backend.bottomHalf)-1]
+	backend.bottomHalf[len(backend.bottomHalf)-1] = nil
+	backend.bottomHalf = backend.bottomHalf[:len(backend.bottomHalf)-1]
+
+	return bottom.value
+}
+
+func (backend *minMaxBackend) ElementAt(distanceFromTop uint) interface{} {
+	if distanceFromTop < uint(len(backend.topHalf)) {
+		return backend.topHalf[uint(len(backend.topHalf))-1-distanceFromTop].value
+	}
+
+	return backend.bottomHalf[distanceFromTop-uint(len(backend.topHalf))].value
+}
+
+func (backend *minMaxBackend) Depth() uint {
+	return uint(len(backend.topHalf) + len(backend.bottomHalf))
+}
+
+func (backend *minMaxBackend) Clear() {
+	for i := range backend.topHalf {
+		backend.topHalf[i] = nil
+	}
+	for i := range backend.bottomHalf {
+		backend.bottomHalf[i] = nil
+	}
+
+	backend.topHalf = backend.topHalf[:0]
+	backend.bottomHalf = backend.bottomHalf[:0]
+}
+
+func (backend *minMaxBackend) Clone() Backend {
+	return &minMaxBackend{
+		less:       backend.less,
+		topHalf:    append([]*minMaxEntry(nil), backend.topHalf...),
+		bottomHalf: append([]*minMaxEntry(nil), backend.bottomHalf...),
+	}
+}
+
+func (backend *minMaxBackend) appendedWith(half []*minMaxEntry, value interface{}) []*minMaxEntry {
+	entry := &minMaxEntry{value: value, minimumFromMiddle: value, maximumFromMiddle: value}
+
+	if len(half) > 0 {
+		previous := half[len(half)-1]
+		if backend.less(previous.minimumFromMiddle, value) {
+			entry.minimumFromMiddle = previous.minimumFromMiddle
+		}
+		if backend.less(value, previous.maximumFromMiddle) {
+			entry.maximumFromMiddle = previous.maximumFromMiddle
+		}
+	}
+
+	return append(half, entry)
+}
+
+// rebalance splits the elements evenly between the halves.  If there is an odd number
+// of elements, the extra one goes to whichever half is empty, so that the operation
+// that needed an element from that half can proceed.
+func (backend *minMaxBackend) rebalance() {
+	valuesFromBottom := make([]interface{}, 0, backend.Depth())
+	for i := len(backend.bottomHalf) - 1; i >= 0; i-- {
+		valuesFromBottom = append(valuesFromBottom, backend.bottomHalf[i].value)
+	}
+	for _, entry := range backend.topHalf {
+		valuesFromBottom = append(valuesFromBottom, entry.value)
+	}
+
+	sizeOfBottomHalf := len(valuesFromBottom) / 2
+	if len(backend.bottomHalf) == 0 {
+		sizeOfBottomHalf = (len(valuesFromBottom) + 1) / 2
+	}
+
+	backend.bottomHalf = backend.bottomHalf[:0]
+	for i := sizeOfBottomHalf - 1; i >= 0; i-- {
+		backend.bottomHalf = backend.appendedWith(backend.bottomHalf, valuesFromBottom[i])
+	}
+
+	backend.topHalf = backend.topHalf[:0]
+	for _, value := range valuesFromBottom[sizeOfBottomHalf:] {
+		backend.topHalf = backend.appendedWith(backend.topHalf, value)
+	}
+}
+
+// extreme returns the minimum or maximum of the stack, given a function that selects
+// the corresponding extreme from an entry.
+func (backend *minMaxBackend) extreme(extremeOf func(entry *minMaxEntry) interface{}, findMaximum bool) (value interface{}, stackIsEmpty bool) {
+	switch {
+	case len(backend.topHalf) == 0 && len(backend.bottomHalf) == 0:
+		return nil, true
+	case len(backend.bottomHalf) == 0:
+		return extremeOf(backend.topHalf[len(backend.topHalf)-1]), false
+	case len(backend.topHalf) == 0:
+		return extremeOf(backend.bottomHalf[len(backend.bottomHalf)-1]), false
+	}
+
+	extremeOfTopHalf := extremeOf(backend.topHalf[len(backend.topHalf)-1])
+	extremeOfBottomHalf := extremeOf(backend.bottomHalf[len(backend.bottomHalf)-1])
+
+	if backend.less(extremeOfTopHalf, extremeOfBottomHalf) == findMaximum {
+		return extremeOfBottomHalf, false
+	}
+
+	return extremeOfTopHalf, false
+}
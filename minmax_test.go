@@ -0,0 +1,136 @@
+package stack_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	. "github.com/onsi/gomega"
+)
+
+func intLess(a, b interface{}) bool {
+	return a.(int) < b.(int)
+}
+
+func TestMinMaxStack(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s, err := stack.NewMinMaxStack(intLess)
+	g.Expect(err).To(BeNil())
+
+	_, stackIsEmpty := s.Min()
+	g.Expect(stackIsEmpty).To(BeTrue())
+	_, stackIsEmpty = s.Max()
+	g.Expect(stackIsEmpty).To(BeTrue())
+
+	for _, testCase := range []struct {
+		operation       string
+		value           int
+		expectedMinimum int
+		expectedMaximum int
+	}{
+		{"push", 5, 5, 5},
+		{"push", 3, 3, 5},
+		{"push", 8, 3, 8},
+		{"push", 1, 1, 8},
+		{"pop", 0, 3, 8},
+		{"pop", 0, 3, 5},
+		{"push", 9, 3, 9},
+		{"pop", 0, 3, 5},
+		{"pop", 0, 5, 5},
+	} {
+		if testCase.operation == "push" {
+			s.Push(testCase.value)
+		} else {
+			s.Pop()
+		}
+
+		minimum, _ := s.Min()
+		maximum, _ := s.Max()
+		g.Expect(minimum).To(Equal(testCase.expectedMinimum))
+		g.Expect(maximum).To(Equal(testCase.expectedMaximum))
+	}
+
+	_, err = stack.NewMinMaxStack(nil)
+	g.Expect(err).ToNot(BeNil())
+
+	_, err = stack.NewMinMaxStack(intLess, stack.WithBackend(stack.NewRingBackend(1)))
+	g.Expect(err).ToNot(BeNil())
+}
+
+func TestMinMaxStackWithBottomEviction(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s, err := stack.NewMinMaxStack(intLess, stack.WithMaxDepth(3), stack.WithDiscardOldest())
+	g.Expect(err).To(BeNil())
+
+	for _, testCase := range []struct {
+		valueToPush     int
+		expectedMinimum int
+		expectedMaximum int
+	}{
+		{1, 1, 1},
+		{9, 1, 9},
+		{5, 1, 9},
+		{6, 5, 9},
+		{7, 5, 7},
+		{2, 2, 7},
+	} {
+		s.Push(testCase.valueToPush)
+
+		minimum, _ := s.Min()
+		maximum, _ := s.Max()
+		g.Expect(minimum).To(Equal(testCase.expectedMinimum), "after push of %d", testCase.valueToPush)
+		g.Expect(maximum).To(Equal(testCase.expectedMaximum), "after push of %d", testCase.valueToPush)
+	}
+}
+
+func TestMinMaxStackAgainstBruteForce(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	random := rand.New(rand.NewSource(37))
+
+	s, err := stack.NewMinMaxStack(intLess, stack.WithMaxDepth(20), stack.WithDiscardOldest())
+	g.Expect(err).To(BeNil())
+
+	model := []int{}
+	for i := 0; i < 5000; i++ {
+		switch operation := random.Intn(10); {
+		case operation < 6:
+			value := random.Intn(1000)
+			s.Push(value)
+			model = append(model, value)
+			if len(model) > 20 {
+				model = model[1:]
+			}
+		case operation < 9:
+			value, stackWasEmpty := s.Pop()
+			g.Expect(stackWasEmpty).To(Equal(len(model) == 0))
+			if len(model) > 0 {
+				g.Expect(value).To(Equal(model[len(model)-1]))
+				model = model[:len(model)-1]
+			}
+		default:
+			if clone := s.Clone(); random.Intn(2) == 0 {
+				s = clone
+			}
+		}
+
+		minimum, stackIsEmpty := s.Min()
+		maximum, _ := s.Max()
+		g.Expect(stackIsEmpty).To(Equal(len(model) == 0))
+		if len(model) > 0 {
+			expectedMinimum, expectedMaximum := model[0], model[0]
+			for _, v := range model {
+				if v < expectedMinimum {
+					expectedMinimum = v
+				}
+				if v > expectedMaximum {
+					expectedMaximum = v
+				}
+			}
+			g.Expect(minimum).To(Equal(expectedMinimum))
+			g.Expect(maximum).To(Equal(expectedMaximum))
+		}
+	}
+}
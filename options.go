@@ -0,0 +1,160 @@
+package stack
+
+import "fmt"
+
+// Option configures a Stack created by New().
+type Option func(configuration *stackConfiguration) error
+
+type stackConfiguration struct {
+	initialCapacity          uint
+	initialCapacityWasSet    bool
+	maximumDepth             uint
+	maximumDepthWasSet       bool
+	discardsOldest           bool
+	backend                  Backend
+	discardedElementCallback func(discardedValue interface{})
+	recorder                 *Recorder
+	tracer                   Tracer
+}
+
+// New returns an empty stack configured by the provided options.  With no options, it
+// is the same as NewStack().  The options are validated together before the stack is
+// created, and an error is returned if any option is invalid or if two options cannot
+// be combined.  For example:
+//		s, err := stack.New(stack.WithMaxDepth(10), stack.WithDiscardOldest())
+// returns the same kind of stack as stack.NewBoundedDiscardingStack(10).
+func New(options ...Option) (*Stack, error) {
+	configuration, err := newConfigurationFrom(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return newStackUsingManipulator(configuration.manipulator()), nil
+}
+
+func newConfigurationFrom(options []Option) (*stackConfiguration, error) {
+	configuration := &stackConfiguration{initialCapacity: 100}
+
+	for _, option := range options {
+		if err := option(configuration); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := configuration.validate(); err != nil {
+		return nil, err
+	}
+
+	return configuration, nil
+}
+
+// WithInitialCapacity sets the number of elements for which storage is initially
+// allocated.  The storage grows as needed.  It may not be combined with WithBackend().
+func WithInitialCapacity(numberOfElements uint) Option {
+	return func(configuration *stackConfiguration) error {
+		configuration.initialCapacity = numberOfElements
+		configuration.initialCapacityWasSet = true
+		return nil
+	}
+}
+
+// WithMaxDepth sets the maximum number of elements allowed in the stack, as
+// WithAMaximumDepthOf() does.  The maximum must be at least 1.
+func WithMaxDepth(maximumNumberOfAllowedElements uint) Option {
+	return func(configuration *stackConfiguration) error {
+		if maximumNumberOfAllowedElements < 1 {
+			return fmt.Errorf("stack size must be at least 1")
+		}
+
+		configuration.maximumDepth = maximumNumberOfAllowedElements
+		configuration.maximumDepthWasSet = true
+		return nil
+	}
+}
+
+// WithDiscardOldest makes the stack a discarding stack, as NewBoundedDiscardingStack()
+// does.  It must be combined with WithMaxDepth().
+func WithDiscardOldest() Option {
+	return func(configuration *stackConfiguration) error {
+		configuration.discardsOldest = true
+		return nil
+	}
+}
+
+// WithBackend sets the Backend used to store stack elements.  The Backend may already
+// contain elements, in which case they become the initial stack contents.  It may not
+// be combined with WithInitialCapacity().
+func WithBackend(backend Backend) Option {
+	return func(configuration *stackConfiguration) error {
+		if backend == nil {
+			return fmt.Errorf("backend must not be nil")
+		}
+
+		configuration.backend = backend
+		return nil
+	}
+}
+
+// WithOnDiscard sets a function that is called with each element the stack silently
+// discards, which happens when a discarding stack evicts the element at its bottom and
+// when a reduced maximum depth drops elements from its top.  It is not called for
+// a value rejected by Push() on a full stack, nor for elements removed by ResetToEmpty().
+// The function is called from the goroutine that serializes stack operations, so it
+// must not itself operate on the stack.
+func WithOnDiscard(callback func(discardedValue interface{})) Option {
+	return func(configuration *stackConfiguration) error {
+		if callback == nil {
+			return fmt.Errorf("discard callback must not be nil")
+		}
+
+		configuration.discardedElementCallback = callback
+		return nil
+	}
+}
+
+func (configuration *stackConfiguration) validate() error {
+	if configuration.discardsOldest && !configuration.maximumDepthWasSet {
+		return fmt.Errorf("a discarding stack requires a maximum depth")
+	}
+
+	if configuration.backend != nil {
+		if configuration.initialCapacityWasSet {
+			return fmt.Errorf("an initial capacity may not be set with a backend")
+		}
+
+		if configuration.maximumDepthWasSet && configuration.backend.Depth() > configuration.maximumDepth {
+			return fmt.Errorf("backend contains %d elements, which exceeds the maximum depth of %d", configuration.backend.Depth(), configuration.maximumDepth)
+		}
+	}
+
+	return nil
+}
+
+func (configuration *stackConfiguration) manipulator() *stackManipulator {
+	backend := configuration.backend
+	if backend == nil {
+		backend = NewRingBackend(configuration.initialCapacity)
+	}
+
+	m := newStackManipulator(backend)
+
+	if configuration.discardsOldest {
+		m.whichDiscardsAtSize(configuration.maximumDepth)
+	} else if configuration.maximumDepthWasSet {
+		m.whichIsBoundedAtSize(configuration.maximumDepth)
+	}
+
+	if configuration.discardedElementCallback != nil {
+		m.whichCallsOnDiscard(configuration.discardedElementCallback)
+	}
+
+	if configuration.recorder != nil {
+		m.whichRecordsWith(configuration.recorder)
+	}
+
+	if configuration.tracer != nil {
+		m.whichTracesWith(configuration.tracer)
+	}
+
+	return m
+}
@@ -0,0 +1,112 @@
+package stack_test
+
+import (
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	. "github.com/onsi/gomega"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s, err := stack.New()
+	g.Expect(err).To(BeNil())
+
+	for _, testCase := range []*stackOperationTestCase{
+		{testname: "New Clear Stack Initial Check", operation: "check", expectedStackDepthAfterOperation: 0},
+		{testname: "Push first value", operation: "push", valueToPush: "first", expectStackToHaveBeenFull: false, expectedStackDepthAfterOperation: 1},
+		{testname: "Pop first value", operation: "pop", expectedPopValue: "first", expectStackToHaveBeenEmpty: false, expectedStackDepthAfterOperation: 0},
+	} {
+		testCase.evaluateAgainstStack(s, g)
+	}
+
+	s, err = stack.New(stack.WithInitialCapacity(1), stack.WithMaxDepth(2))
+	g.Expect(err).To(BeNil())
+
+	for _, testCase := range []*stackOperationTestCase{
+		{testname: "Bounded Push first value", operation: "push", valueToPush: "first", expectStackToHaveBeenFull: false, expectedStackDepthAfterOperation: 1},
+		{testname: "Bounded Push second value", operation: "push", valueToPush: "second", expectStackToHaveBeenFull: false, expectedStackDepthAfterOperation: 2},
+		{testname: "Bounded Push third value", operation: "push", valueToPush: "third", expectStackToHaveBeenFull: true, expectedStackDepthAfterOperation: 2},
+		{testname: "Bounded Pop", operation: "pop", expectedPopValue: "second", expectStackToHaveBeenEmpty: false, expectedStackDepthAfterOperation: 1},
+	} {
+		testCase.evaluateAgainstStack(s, g)
+	}
+
+	discardedValues := make([]interface{}, 0, 2)
+	s, err = stack.New(stack.WithMaxDepth(2), stack.WithDiscardOldest(), stack.WithOnDiscard(func(v interface{}) {
+		discardedValues = append(discardedValues, v)
+	}))
+	g.Expect(err).To(BeNil())
+
+	for _, testCase := range []*stackOperationTestCase{
+		{testname: "Discarding Push first value", operation: "push", valueToPush: "first", expectStackToHaveBeenFull: false, expectedStackDepthAfterOperation: 1},
+		{testname: "Discarding Push second value", operation: "push", valueToPush: "second", expectStackToHaveBeenFull: true, expectedStackDepthAfterOperation: 2},
+		{testname: "Discarding Push third value", operation: "push", valueToPush: "third", expectStackToHaveBeenFull: true, expectedStackDepthAfterOperation: 2},
+		{testname: "Discarding Push fourth value", operation: "push", valueToPush: "fourth", expectStackToHaveBeenFull: true, expectedStackDepthAfterOperation: 2},
+		{testname: "Discarding Pop", operation: "pop", expectedPopValue: "fourth", expectStackToHaveBeenEmpty: false, expectedStackDepthAfterOperation: 1},
+	} {
+		testCase.evaluateAgainstStack(s, g)
+	}
+
+	g.Expect(discardedValues).To(Equal([]interface{}{"first", "second"}))
+
+	backend := stack.NewRingBackend(2)
+	backend.PushOnTop("first")
+	backend.PushOnTop("second")
+
+	s, err = stack.New(stack.WithBackend(backend))
+	g.Expect(err).To(BeNil())
+
+	for _, testCase := range []*stackOperationTestCase{
+		{testname: "Backend Initial Check", operation: "check", expectedStackDepthAfterOperation: 2},
+		{testname: "Backend Push third value", operation: "push", valueToPush: "third", expectStackToHaveBeenFull: false, expectedStackDepthAfterOperation: 3},
+		{testname: "Backend Pop third value", operation: "pop", expectedPopValue: "third", expectStackToHaveBeenEmpty: false, expectedStackDepthAfterOperation: 2},
+		{testname: "Backend Pop second value", operation: "pop", expectedPopValue: "second", expectStackToHaveBeenEmpty: false, expectedStackDepthAfterOperation: 1},
+	} {
+		testCase.evaluateAgainstStack(s, g)
+	}
+}
+
+func TestNewOptionsShrinkingMaximumCallsOnDiscard(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	discardedValues := make([]interface{}, 0, 2)
+	s, err := stack.New(stack.WithOnDiscard(func(v interface{}) {
+		discardedValues = append(discardedValues, v)
+	}))
+	g.Expect(err).To(BeNil())
+
+	for _, v := range []string{"first", "second", "third", "fourth"} {
+		s.Push(v)
+	}
+
+	s.SetMaximumDepthTo(2)
+
+	g.Expect(s.Depth()).To(Equal(uint(2)))
+	g.Expect(discardedValues).To(Equal([]interface{}{"fourth", "third"}))
+}
+
+func TestNewOptionErrors(t *testing.T) {
+	nonEmptyBackend := stack.NewRingBackend(2)
+	nonEmptyBackend.PushOnTop("first")
+	nonEmptyBackend.PushOnTop("second")
+
+	for _, testCase := range []struct {
+		testname string
+		options  []stack.Option
+	}{
+		{"maximum depth of zero", []stack.Option{stack.WithMaxDepth(0)}},
+		{"discarding without maximum depth", []stack.Option{stack.WithDiscardOldest()}},
+		{"nil backend", []stack.Option{stack.WithBackend(nil)}},
+		{"backend with initial capacity", []stack.Option{stack.WithBackend(stack.NewRingBackend(10)), stack.WithInitialCapacity(10)}},
+		{"backend deeper than maximum depth", []stack.Option{stack.WithBackend(nonEmptyBackend), stack.WithMaxDepth(1)}},
+		{"nil discard callback", []stack.Option{stack.WithOnDiscard(nil)}},
+	} {
+		if s, err := stack.New(testCase.options...); err == nil {
+			t.Errorf("[%s] expected error, got none", testCase.testname)
+		} else if s != nil {
+			t.Errorf("[%s] expected nil stack on error, got non-nil", testCase.testname)
+		}
+	}
+}
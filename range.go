@@ -0,0 +1,78 @@
+package stack
+
+// Range returns the elements from the start position through the stop position, in order
+// from the top of the stack, as of a single moment.  Positions are counted from the top
+// of the stack, so position 0 is the top.  A negative position is counted from the bottom
+// of the stack instead, so position -1 is the bottom.  Positions beyond either end of the
+// stack are treated as the end.  If the start position is below the stop position, or the
+// stack is empty, an empty slice is returned.  For example, Range(0, -1) returns every
+// element, and Range(0, 2) returns the top three elements.
+func (stack *Stack) Range(start, stop int) []interface{} {
+	release := stack.holdManipulator()
+	defer release()
+
+	backend := stack.manipulator.backend
+
+	distanceFromTopOfFirst, distanceFromTopOfLast, rangeIsEmpty := positionsWithin(backend.Depth(), start, stop)
+	if rangeIsEmpty {
+		return []interface{}{}
+	}
+
+	elements := make([]interface{}, 0, distanceFromTopOfLast-distanceFromTopOfFirst+1)
+	for distanceFromTop := distanceFromTopOfFirst; distanceFromTop <= distanceFromTopOfLast; distanceFromTop++ {
+		elements = append(elements, backend.ElementAt(distanceFromTop))
+	}
+
+	return elements
+}
+
+// Trim silently discards every element that is not in the range from the start position
+// through the stop position, which are interpreted as they are by Range().  If the range
+// is empty, all elements are discarded.  The discard callback is not called.
+func (stack *Stack) Trim(start, stop int) {
+	release := stack.holdManipulator()
+	defer release()
+
+	backend := stack.manipulator.backend
+	depth := backend.Depth()
+
+	distanceFromTopOfFirst, distanceFromTopOfLast, rangeIsEmpty := positionsWithin(depth, start, stop)
+	if rangeIsEmpty {
+		backend.Clear()
+		return
+	}
+
+	for i := uint(0); i < distanceFromTopOfFirst; i++ {
+		backend.PopFromTop()
+	}
+
+	for i := distanceFromTopOfLast + 1; i < depth; i++ {
+		backend.RemoveFromBottom()
+	}
+}
+
+// positionsWithin converts the start and stop positions of Range() to distances from the
+// top of a stack of the provided depth.
+func positionsWithin(depth uint, start, stop int) (distanceFromTopOfFirst, distanceFromTopOfLast uint, rangeIsEmpty bool) {
+	if start < 0 {
+		start += int(depth)
+	}
+
+	if stop < 0 {
+		stop += int(depth)
+	}
+
+	if start < 0 {
+		start = 0
+	}
+
+	if stop >= int(depth) {
+		stop = int(depth) - 1
+	}
+
+	if start > stop {
+		return 0, 0, true
+	}
+
+	return uint(start), uint(stop), false
+}
@@ -0,0 +1,75 @@
+package stack_test
+
+import (
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	. "github.com/onsi/gomega"
+)
+
+func TestRange(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s := stackOf(1, 2, 3, 4, 5)
+
+	for _, testCase := range []struct {
+		start            int
+		stop             int
+		expectedElements []interface{}
+	}{
+		{0, -1, []interface{}{5, 4, 3, 2, 1}},
+		{0, 0, []interface{}{5}},
+		{1, 2, []interface{}{4, 3}},
+		{-2, -1, []interface{}{2, 1}},
+		{-100, 1, []interface{}{5, 4}},
+		{3, 100, []interface{}{2, 1}},
+		{3, 1, []interface{}{}},
+		{5, 10, []interface{}{}},
+		{-1, -2, []interface{}{}},
+	} {
+		g.Expect(s.Range(testCase.start, testCase.stop)).To(Equal(testCase.expectedElements), "Range(%d, %d)", testCase.start, testCase.stop)
+	}
+
+	g.Expect(stack.NewStack().Range(0, -1)).To(Equal([]interface{}{}))
+}
+
+func TestTrim(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for _, testCase := range []struct {
+		start                   int
+		stop                    int
+		expectedContentsFromTop []interface{}
+	}{
+		{0, -1, []interface{}{5, 4, 3, 2, 1}},
+		{0, 2, []interface{}{5, 4, 3}},
+		{1, 2, []interface{}{4, 3}},
+		{-2, -1, []interface{}{2, 1}},
+		{3, 1, []interface{}{}},
+		{10, 20, []interface{}{}},
+	} {
+		s := stackOf(1, 2, 3, 4, 5)
+		s.Trim(testCase.start, testCase.stop)
+		g.Expect(contentsFromTopOf(s)).To(Equal(testCase.expectedContentsFromTop), "Trim(%d, %d)", testCase.start, testCase.stop)
+	}
+
+	discarded := []interface{}{}
+	s, _ := stack.New(stack.WithMaxDepth(3), stack.WithDiscardOldest(), stack.WithOnDiscard(func(v interface{}) { discarded = append(discarded, v) }))
+	s.Push(1)
+	s.Push(2)
+	s.Push(3)
+	s.Trim(0, 0)
+	s.Push(4)
+	s.Push(5)
+	g.Expect(contentsFromTopOf(s)).To(Equal([]interface{}{5, 4, 3}))
+	g.Expect(discarded).To(BeEmpty())
+}
+
+func TestIsDiscarding(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(stack.NewStack().IsDiscarding()).To(BeFalse())
+	g.Expect(stack.NewStack().WithAMaximumDepthOf(3).IsDiscarding()).To(BeFalse())
+	g.Expect(stack.NewBoundedDiscardingStack(3).IsDiscarding()).To(BeTrue())
+	g.Expect(stack.NewBoundedDiscardingStack(3).Clone().IsDiscarding()).To(BeTrue())
+}
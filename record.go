@@ -0,0 +1,472 @@
+package stack
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Recorder writes a trace of every operation performed on a stack, so that a sequence of
+// concurrent operations that produced an unexpected result can later be reproduced with
+// Replay().  A Recorder is attached to a stack with WithRecorder() and should record only
+// that stack.  The trace is written as one JSON-encoded OperationRecord per line, from
+// the goroutine that serializes stack operations, so a slow writer slows the stack.
+//
+// Values are recorded as JSON.  A value that cannot be encoded as JSON is recorded as the
+// string that fmt.Sprint() produces for it, and a replayed stack holds values as
+// encoding/json decodes them into an interface{}, so a trace reproduces the sequence of
+// operations, but not necessarily the Go types of the values.
+type Recorder struct {
+	mutex             sync.Mutex
+	writeRecord       func(record *OperationRecord) error
+	timeOfStart       time.Time
+	nextSequence      uint64
+	identifiesCallers bool
+	firstError        error
+}
+
+// OperationRecord is a single line of a trace written by a Recorder.
+type OperationRecord struct {
+	// Sequence is the position of the operation in the order in which the stack performed
+	// operations, starting at 0 for the record made when the stack starts.
+	Sequence uint64 `json:"sequence"`
+
+	// ElapsedNanoseconds is the monotonic time from the creation of the Recorder to the
+	// moment the stack performed the operation.
+	ElapsedNanoseconds int64 `json:"elapsedNanoseconds"`
+
+	// Goroutine identifies the goroutine that requested the operation, as in "goroutine
+	// 17".  It is empty for the record made when the stack starts.
+	Goroutine string `json:"goroutine,omitempty"`
+
+	// Operation is the name of the operation, such as "push" or "popIf".  The first
+	// record of a trace has the operation "start" and describes the stack as it was when
+	// it started.  An operation named "hold" is exclusive access to the stack by one of
+	// its methods, such as Range() or Trim(), and records the stack contents afterward.
+	Operation string `json:"operation"`
+
+	// Value is the value pushed by "push" or "pushIf", or the new value for
+	// "compareAndSwapTop".
+	Value json.RawMessage `json:"value,omitempty"`
+
+	// ValueToCompare is the value compared with the top of the stack by
+	// "compareAndSwapTop".
+	ValueToCompare json.RawMessage `json:"valueToCompare,omitempty"`
+
+	// Argument is the maximum depth for "setMaximumDepth", or the number of elements
+	// for "pick", "roll" and "drop".
+	Argument uint `json:"argument,omitempty"`
+
+	Result OperationResult `json:"result"`
+}
+
+// OperationResult is the outcome of an operation in an OperationRecord.
+type OperationResult struct {
+	// Value is the value returned by "pop", "peek" or "popIf", if the stack was not empty.
+	Value json.RawMessage `json:"value,omitempty"`
+
+	// StackIsEmptyOrFull is the boolean returned by the operation to indicate that the
+	// stack was empty or full.
+	StackIsEmptyOrFull bool `json:"stackIsEmptyOrFull,omitempty"`
+
+	// ConditionWasSatisfied is true if the condition of "popIf" or "pushIf", or the
+	// comparison of "compareAndSwapTop", was satisfied.
+	ConditionWasSatisfied bool `json:"conditionWasSatisfied,omitempty"`
+
+	// Error is the error returned by the operation, or the panic raised by the condition
+	// of "popIf" or "pushIf".
+	Error string `json:"error,omitempty"`
+
+	// Depth is the depth of the stack after the operation.
+	Depth uint `json:"depth"`
+
+	// MaximumDepth is the maximum depth of the stack after the operation, or 0 if there is
+	// none.
+	MaximumDepth uint `json:"maximumDepth,omitempty"`
+
+	// IsDiscarding is true for the "start" record of a discarding stack.
+	IsDiscarding bool `json:"isDiscarding,omitempty"`
+
+	// ElementsFromBottom are the stack contents after a "start" or "hold", from the bottom
+	// of the stack to the top.
+	ElementsFromBottom []json.RawMessage `json:"elementsFromBottom,omitempty"`
+}
+
+// Divergence is an operation for which Replay() produced a different result than the
+// one recorded in the trace.
+type Divergence struct {
+	Sequence  uint64
+	Operation string
+	Recorded  OperationResult
+	Replayed  OperationResult
+}
+
+func (divergence Divergence) String() string {
+	recorded, _ := json.Marshal(divergence.Recorded)
+	replayed, _ := json.Marshal(divergence.Replayed)
+	return fmt.Sprintf("operation %d (%s) recorded %s but replayed %s", divergence.Sequence, divergence.Operation, recorded, replayed)
+}
+
+// NewRecorder returns a Recorder that writes a trace to the provided writer.
+func NewRecorder(trace io.Writer) *Recorder {
+	encoder := json.NewEncoder(trace)
+	return newRecorderUsing(func(record *OperationRecord) error { return encoder.Encode(record) }, true)
+}
+
+func newRecorderUsing(writeRecord func(record *OperationRecord) error, identifiesCallers bool) *Recorder {
+	return &Recorder{
+		writeRecord:       writeRecord,
+		timeOfStart:       time.Now(),
+		identifiesCallers: identifiesCallers,
+	}
+}
+
+// Err returns the first error encountered while writing the trace, or nil if there has
+// been none.  Once there has been an error, nothing more is written.
+func (recorder *Recorder) Err() error {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	return recorder.firstError
+}
+
+// WithRecorder attaches a Recorder, which records every operation performed on the stack.
+// A clone of the stack does not record its operations.
+func WithRecorder(recorder *Recorder) Option {
+	return func(configuration *stackConfiguration) error {
+		if recorder == nil {
+			return fmt.Errorf("recorder must not be nil")
+		}
+
+		configuration.recorder = recorder
+		return nil
+	}
+}
+
+func (recorder *Recorder) write(record *OperationRecord) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	if recorder.firstError != nil {
+		return
+	}
+
+	record.Sequence = recorder.nextSequence
+	record.ElapsedNanoseconds = int64(time.Since(recorder.timeOfStart))
+	recorder.nextSequence++
+
+	recorder.firstError = recorder.writeRecord(record)
+}
+
+// identifyCaller returns the identity of the calling goroutine, if the recorder needs it.
+func (recorder *Recorder) identifyCaller() string {
+	if !recorder.identifiesCallers {
+		return ""
+	}
+
+	// The first line of a goroutine's stack trace is "goroutine <id> [<state>]:".
+	buffer := make([]byte, 64)
+	buffer = buffer[:runtime.Stack(buffer, false)]
+	if end := bytes.IndexByte(buffer, '['); end > 0 {
+		buffer = buffer[:end]
+	}
+
+	return string(bytes.TrimSpace(buffer))
+}
+
+// recordStart records the stack as it is when the manipulator starts.
+func (manipulator *stackManipulator) recordStart() {
+	manipulator.recorder.write(&OperationRecord{
+		Operation: "start",
+		Result: OperationResult{
+			Depth:              manipulator.backend.Depth(),
+			MaximumDepth:       manipulator.maximumStackDepth,
+			IsDiscarding:       manipulator.discardsFIFOAfterMaxSize,
+			ElementsFromBottom: manipulator.encodedContentsFromBottom(),
+		},
+	})
+}
+
+// record records an operation that the manipulator has performed, with its response.
+func (manipulator *stackManipulator) record(request *stackManipulationMessage, response *stackManipulationResponse) {
+	record := &OperationRecord{
+		Goroutine: request.callerGoroutine,
+		Operation: namesOfOperations[request.operation],
+		Result: OperationResult{
+			StackIsEmptyOrFull:    response.stackIsEmptyOrFullBeforeOperation,
+			ConditionWasSatisfied: response.conditionWasSatisfied,
+			Depth:                 manipulator.backend.Depth(),
+			MaximumDepth:          manipulator.maximumStackDepth,
+		},
+	}
+
+	switch request.operation {
+	case push, pushIf:
+		record.Value = encodedValueFrom(request.valueToPush)
+
+	case compareAndSwapTop:
+		record.Value = encodedValueFrom(request.valueToPush)
+		record.ValueToCompare = encodedValueFrom(request.valueToCompare)
+
+	case setMaximumDepth, pick, roll, drop:
+		record.Argument = request.depth
+
+	case holdForExclusiveAccess:
+		record.Result.ElementsFromBottom = manipulator.encodedContentsFromBottom()
+	}
+
+	switch request.operation {
+	case pop, peek, popIf:
+		if !response.stackIsEmptyOrFullBeforeOperation {
+			record.Result.Value = encodedValueFrom(response.poppedValueOrCurrentDepth)
+		}
+	}
+
+	if response.operationError != nil {
+		record.Result.Error = response.operationError.Error()
+	} else if response.recoveredPanic != nil {
+		record.Result.Error = fmt.Sprint(response.recoveredPanic)
+	}
+
+	manipulator.recorder.write(record)
+}
+
+func (manipulator *stackManipulator) encodedContentsFromBottom() []json.RawMessage {
+	depth := manipulator.backend.Depth()
+	contents := make([]json.RawMessage, depth)
+	for i := range contents {
+		contents[i] = encodedValueFrom(manipulator.backend.ElementAt(depth - 1 - uint(i)))
+	}
+
+	return contents
+}
+
+func encodedValueFrom(value interface{}) json.RawMessage {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		encoded, _ = json.Marshal(fmt.Sprint(value))
+	}
+
+	return encoded
+}
+
+func decodedValueFrom(encoded json.RawMessage) (interface{}, error) {
+	var value interface{}
+	if err := json.Unmarshal(encoded, &value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// Replay reads a trace written by a Recorder and performs the recorded operations, in
+// the recorded order, on a new stack with the recorded starting configuration and
+// contents.  It returns the new stack and each operation whose result differs from the
+// recorded result.  The conditions of "popIf" and "pushIf" are not recorded, so each
+// is replayed with the recorded outcome of its condition.  A condition that panicked is
+// replayed as one that was not satisfied and raised the recorded error, which is compared
+// like any other result rather than raised again in the caller of Replay.  A "hold" cannot
+// be replayed, so the stack contents are instead set to those recorded after it.  The
+// returned stack does not record its operations.  An error is returned if the trace
+// cannot be read or is not a valid trace.
+func Replay(trace io.Reader) (replayed *Stack, divergences []Divergence, err error) {
+	records, err := readTrace(trace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(records) == 0 || records[0].Operation != "start" {
+		return nil, nil, fmt.Errorf("trace does not begin with a start record")
+	}
+
+	replayedRecords := make(chan *OperationRecord, 1)
+	recorder := newRecorderUsing(func(record *OperationRecord) error {
+		replayedRecords <- record
+		return nil
+	}, false)
+
+	replaying, err := newStackForReplayOf(records[0], recorder)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer replaying.Close()
+
+	divergences = appendDivergenceIfAny(divergences, records[0], <-replayedRecords)
+
+	for _, record := range records[1:] {
+		message, setContents, err := messageForReplayOf(record)
+		if err != nil {
+			return nil, nil, fmt.Errorf("operation %d: %w", record.Sequence, err)
+		}
+
+		if err := replaying.replay(message, setContents); err != nil {
+			return nil, nil, fmt.Errorf("operation %d: %w", record.Sequence, err)
+		}
+
+		divergences = appendDivergenceIfAny(divergences, record, <-replayedRecords)
+	}
+
+	// a clone does not record its operations, so the caller may use it freely once
+	// nothing is left to receive the records of the replaying stack
+	return replaying.Clone(), divergences, nil
+}
+
+func readTrace(trace io.Reader) ([]*OperationRecord, error) {
+	records := []*OperationRecord{}
+
+	scanner := bufio.NewScanner(trace)
+	scanner.Buffer(nil, 1<<30)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		record := &OperationRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			return nil, fmt.Errorf("trace line %d is not a valid record: %w", lineNumber, err)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
+
+func newStackForReplayOf(start *OperationRecord, recorder *Recorder) (*Stack, error) {
+	backend := NewRingBackend(uint(len(start.Result.ElementsFromBottom)))
+	for _, encoded := range start.Result.ElementsFromBottom {
+		value, err := decodedValueFrom(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("start record has an invalid element: %w", err)
+		}
+		backend.PushOnTop(value)
+	}
+
+	options := []Option{WithBackend(backend), WithRecorder(recorder)}
+	if start.Result.MaximumDepth > 0 {
+		options = append(options, WithMaxDepth(start.Result.MaximumDepth))
+	}
+	if start.Result.IsDiscarding {
+		options = append(options, WithDiscardOldest())
+	}
+
+	return New(options...)
+}
+
+// messageForReplayOf returns the message that performs a recorded operation.  For a
+// "hold", it instead returns the contents to which the stack should be set.
+func messageForReplayOf(record *OperationRecord) (message *stackManipulationMessage, contentsForHold []interface{}, err error) {
+	message = &stackManipulationMessage{depth: record.Argument}
+
+	operationIsKnown := false
+	for operation, name := range namesOfOperations {
+		if name == record.Operation {
+			message.operation, operationIsKnown = operation, true
+			break
+		}
+	}
+
+	if !operationIsKnown {
+		return nil, nil, fmt.Errorf("unknown operation (%s)", record.Operation)
+	}
+
+	switch message.operation {
+	case push, pushIf:
+		message.valueToPush, err = decodedValueFrom(record.Value)
+
+	case compareAndSwapTop:
+		if message.valueToPush, err = decodedValueFrom(record.Value); err == nil {
+			message.valueToCompare, err = decodedValueFrom(record.ValueToCompare)
+		}
+
+	case holdForExclusiveAccess:
+		contentsForHold = make([]interface{}, len(record.Result.ElementsFromBottom))
+		for i, encoded := range record.Result.ElementsFromBottom {
+			if contentsForHold[i], err = decodedValueFrom(encoded); err != nil {
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("operation (%s) has an invalid value: %w", record.Operation, err)
+	}
+
+	recordedOutcome := func() bool {
+		if record.Result.Error != "" {
+			panic(record.Result.Error)
+		}
+		return record.Result.ConditionWasSatisfied
+	}
+
+	message.popCondition = func(top interface{}) bool { return recordedOutcome() }
+	message.pushCondition = func(depth uint, top interface{}) bool { return recordedOutcome() }
+
+	return message, contentsForHold, nil
+}
+
+// replay sends a message to the manipulator and waits for the response.  A panic raised
+// while the operation is performed is recorded, but not raised again.  For a hold, the
+// stack contents are set to contentsForHold before the hold is released.
+func (stack *Stack) replay(message *stackManipulationMessage, contentsForHold []interface{}) error {
+	responseChannel := make(chan *stackManipulationResponse)
+	message.responseChannel = responseChannel
+
+	if message.operation != holdForExclusiveAccess {
+		stack.send(message)
+		<-responseChannel
+		return nil
+	}
+
+	releaseChannel := make(chan struct{})
+	message.releaseChannel = releaseChannel
+
+	stack.send(message)
+	<-responseChannel
+
+	if !stack.manipulator.discardsFIFOAfterMaxSize && stack.manipulator.maximumStackDepth > 0 && uint(len(contentsForHold)) > stack.manipulator.maximumStackDepth {
+		close(releaseChannel)
+		return fmt.Errorf("hold has %d elements, which exceeds the maximum depth of %d", len(contentsForHold), stack.manipulator.maximumStackDepth)
+	}
+
+	stack.manipulator.backend.Clear()
+	for _, value := range contentsForHold {
+		stack.manipulator.backend.PushOnTop(value)
+	}
+
+	close(releaseChannel)
+
+	return nil
+}
+
+func appendDivergenceIfAny(divergences []Divergence, recorded *OperationRecord, replayed *OperationRecord) []Divergence {
+	if recorded.Operation == replayed.Operation && reflect.DeepEqual(comparableFormOf(recorded.Result), comparableFormOf(replayed.Result)) {
+		return divergences
+	}
+
+	return append(divergences, Divergence{
+		Sequence:  recorded.Sequence,
+		Operation: recorded.Operation,
+		Recorded:  recorded.Result,
+		Replayed:  replayed.Result,
+	})
+}
+
+// comparableFormOf returns a result as encoding/json decodes it into an interface{}.  A
+// replayed stack holds decoded values, so a recorded value that cannot be decoded exactly,
+// such as a large integer, is compared in its decoded form.
+func comparableFormOf(result OperationResult) interface{} {
+	encoded, _ := json.Marshal(result)
+
+	var decoded interface{}
+	json.Unmarshal(encoded, &decoded)
+
+	return decoded
+}
@@ -0,0 +1,252 @@
+package stack_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	. "github.com/onsi/gomega"
+)
+
+func recordsIn(g *WithT, trace []byte) []*stack.OperationRecord {
+	records := []*stack.OperationRecord{}
+	for _, line := range bytes.Split(bytes.TrimSpace(trace), []byte("\n")) {
+		record := &stack.OperationRecord{}
+		g.Expect(json.Unmarshal(line, record)).To(Succeed())
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func TestRecorderWritesEveryOperation(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var trace bytes.Buffer
+	recorder := stack.NewRecorder(&trace)
+
+	backend := stack.NewRingBackend(4)
+	backend.PushOnTop("bottom")
+
+	s, err := stack.New(stack.WithBackend(backend), stack.WithMaxDepth(3), stack.WithRecorder(recorder))
+	g.Expect(err).To(BeNil())
+
+	s.Push("a")
+	s.Push(map[string]int{"b": 2})
+	s.Push("rejected")
+	s.Pop()
+	s.PopIf(func(top interface{}) bool { return false })
+	s.Drop(5)
+	s.Range(0, -1)
+	s.Depth()
+
+	g.Expect(recorder.Err()).To(BeNil())
+
+	records := recordsIn(g, trace.Bytes())
+	g.Expect(records).To(HaveLen(9))
+
+	operations := []string{}
+	for i, record := range records {
+		operations = append(operations, record.Operation)
+		g.Expect(record.Sequence).To(Equal(uint64(i)))
+
+		if i > 0 {
+			g.Expect(record.ElapsedNanoseconds).To(BeNumerically(">=", records[i-1].ElapsedNanoseconds))
+			g.Expect(record.Goroutine).To(HavePrefix("goroutine "))
+		}
+	}
+
+	g.Expect(operations).To(Equal([]string{"start", "push", "push", "push", "pop", "popIf", "drop", "hold", "getDepth"}))
+
+	g.Expect(records[0].Result).To(Equal(stack.OperationResult{Depth: 1, MaximumDepth: 3, ElementsFromBottom: []json.RawMessage{json.RawMessage(`"bottom"`)}}))
+	g.Expect(string(records[2].Value)).To(Equal(`{"b":2}`))
+	g.Expect(records[3].Result.StackIsEmptyOrFull).To(BeTrue())
+	g.Expect(string(records[4].Result.Value)).To(Equal(`{"b":2}`))
+	g.Expect(string(records[5].Result.Value)).To(Equal(`"a"`))
+	g.Expect(records[5].Result.ConditionWasSatisfied).To(BeFalse())
+	g.Expect(records[6].Argument).To(Equal(uint(5)))
+	g.Expect(records[6].Result.Error).To(ContainSubstring("stack underflow"))
+	g.Expect(records[7].Result.ElementsFromBottom).To(Equal([]json.RawMessage{json.RawMessage(`"bottom"`), json.RawMessage(`"a"`)}))
+	g.Expect(records[8].Result.Depth).To(Equal(uint(2)))
+}
+
+func TestReplayReproducesConcurrentOperations(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var trace bytes.Buffer
+	s, err := stack.New(stack.WithMaxDepth(20), stack.WithRecorder(stack.NewRecorder(&trace)))
+	g.Expect(err).To(BeNil())
+
+	var waitGroup sync.WaitGroup
+	for worker := 0; worker < 4; worker++ {
+		waitGroup.Add(1)
+		go func(worker int) {
+			defer waitGroup.Done()
+			for i := 0; i < 50; i++ {
+				switch i % 7 {
+				case 0, 1, 2:
+					s.Push(worker*100 + i)
+				case 3:
+					s.Pop()
+				case 4:
+					s.PushIf(func(depth uint, top interface{}) bool { return depth%2 == 0 }, "even")
+				case 5:
+					s.CompareAndSwapTop("even", "swapped")
+				case 6:
+					s.Over()
+				}
+			}
+		}(worker)
+	}
+	waitGroup.Wait()
+
+	s.SetMaximumDepthTo(5)
+	s.Trim(1, 3)
+	s.Rot()
+	s.Depth()
+
+	replayed, divergences, err := stack.Replay(bytes.NewReader(trace.Bytes()))
+	g.Expect(err).To(BeNil())
+	g.Expect(divergences).To(BeEmpty())
+
+	// Pushed ints are replayed as float64, which is how encoding/json decodes numbers.
+	expectedContents := []interface{}{}
+	for _, value := range s.Range(0, -1) {
+		encoded, _ := json.Marshal(value)
+		var decoded interface{}
+		json.Unmarshal(encoded, &decoded)
+		expectedContents = append(expectedContents, decoded)
+	}
+
+	g.Expect(replayed.Range(0, -1)).To(Equal(expectedContents))
+	g.Expect(replayed.MaximumDepth()).To(Equal(uint(5)))
+}
+
+func TestReplayOfDiscardingStack(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var trace bytes.Buffer
+	s, _ := stack.New(stack.WithMaxDepth(2), stack.WithDiscardOldest(), stack.WithRecorder(stack.NewRecorder(&trace)))
+	s.Push("a")
+	s.Push("b")
+	s.Push("c")
+	s.ResetToEmpty()
+	s.Push("d")
+
+	replayed, divergences, err := stack.Replay(&trace)
+	g.Expect(err).To(BeNil())
+	g.Expect(divergences).To(BeEmpty())
+	g.Expect(replayed.IsDiscarding()).To(BeTrue())
+	g.Expect(replayed.Range(0, -1)).To(Equal([]interface{}{"d"}))
+}
+
+func TestReplayReportsDivergence(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var trace bytes.Buffer
+	s, _ := stack.New(stack.WithRecorder(stack.NewRecorder(&trace)))
+	s.Push("a")
+	s.Pop()
+	s.Pop()
+
+	tamperedTrace := strings.Replace(trace.String(), `"result":{"value":"a"`, `"result":{"value":"z"`, 1)
+
+	_, divergences, err := stack.Replay(strings.NewReader(tamperedTrace))
+	g.Expect(err).To(BeNil())
+	g.Expect(divergences).To(HaveLen(1))
+	g.Expect(divergences[0].Sequence).To(Equal(uint64(2)))
+	g.Expect(divergences[0].Operation).To(Equal("pop"))
+	g.Expect(string(divergences[0].Recorded.Value)).To(Equal(`"z"`))
+	g.Expect(string(divergences[0].Replayed.Value)).To(Equal(`"a"`))
+	g.Expect(divergences[0].String()).To(ContainSubstring(`operation 2 (pop) recorded {"value":"z"`))
+}
+
+func TestReplayOfConditionThatPanicked(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var trace bytes.Buffer
+	s, _ := stack.New(stack.WithRecorder(stack.NewRecorder(&trace)))
+	s.Push("a")
+	g.Expect(func() { s.PopIf(func(interface{}) bool { panic("condition failed") }) }).To(PanicWith("condition failed"))
+	g.Expect(func() {
+		s.PushIf(func(uint, interface{}) bool { panic("condition failed") }, "b")
+	}).To(PanicWith("condition failed"))
+
+	records := recordsIn(g, trace.Bytes())
+	g.Expect(records[2].Result.Error).To(Equal("condition failed"))
+
+	var replayed *stack.Stack
+	var divergences []stack.Divergence
+	var err error
+	g.Expect(func() { replayed, divergences, err = stack.Replay(bytes.NewReader(trace.Bytes())) }).ToNot(Panic())
+	g.Expect(err).To(BeNil())
+	g.Expect(divergences).To(BeEmpty())
+	g.Expect(replayed.Range(0, -1)).To(Equal([]interface{}{"a"}))
+
+	lines := strings.SplitAfter(trace.String(), "\n")
+	traceWithoutPush := lines[0] + strings.Join(lines[2:], "")
+	_, divergences, err = stack.Replay(strings.NewReader(traceWithoutPush))
+	g.Expect(err).To(BeNil())
+	g.Expect(divergences).ToNot(BeEmpty())
+	g.Expect(divergences[0].Operation).To(Equal("popIf"))
+	g.Expect(divergences[0].Recorded.Error).To(Equal("condition failed"))
+	g.Expect(divergences[0].Replayed.Error).To(BeEmpty())
+}
+
+func TestReplayedStackCanBeUsedAfterReplay(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var trace bytes.Buffer
+	s, _ := stack.New(stack.WithRecorder(stack.NewRecorder(&trace)))
+	s.Push("a")
+
+	replayed, _, err := stack.Replay(&trace)
+	g.Expect(err).To(BeNil())
+
+	operationsAreDone := make(chan struct{})
+	go func() {
+		defer close(operationsAreDone)
+		replayed.Push("b")
+		replayed.Push("c")
+		replayed.Pop()
+	}()
+
+	g.Eventually(operationsAreDone).Should(BeClosed())
+	g.Expect(replayed.Range(0, -1)).To(Equal([]interface{}{"b", "a"}))
+}
+
+func TestReplayOfInvalidTrace(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for _, trace := range []string{
+		"",
+		`{"operation":"push","value":"a"}`,
+		`not json`,
+		"{\"operation\":\"start\",\"result\":{\"depth\":0}}\n{\"operation\":\"frobnicate\"}",
+	} {
+		_, _, err := stack.Replay(strings.NewReader(trace))
+		g.Expect(err).ToNot(BeNil(), "trace (%s)", trace)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestRecorderWithFailingWriter(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	recorder := stack.NewRecorder(failingWriter{})
+	s, _ := stack.New(stack.WithRecorder(recorder))
+	s.Push("a")
+
+	g.Expect(recorder.Err()).To(MatchError("disk full"))
+	g.Expect(s.Depth()).To(Equal(uint(1)))
+
+	_, err := stack.New(stack.WithRecorder(nil))
+	g.Expect(err).ToNot(BeNil())
+}
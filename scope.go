@@ -0,0 +1,204 @@
+package stack
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// Scope is a named unit of work, such as a request or a tracing span, along with
+// attributes describing it.
+type Scope struct {
+	Name       string
+	Attributes []slog.Attr
+}
+
+// ScopeStack is a stack of the Scopes that are active in a chain of calls.  It is built
+// on a Stack, so it is safe for concurrent use, but scopes are pushed and popped in
+// LIFO order, so a ScopeStack should follow a single call chain.  A goroutine started
+// from within a scope should be given its own ScopeStack, using Clone().
+//
+// A ScopeStack is usually carried in a context.Context, where WithScope() finds it, and
+// from which a handler returned by NewScopeHandler() adds the active scopes to each log
+// record.  A ScopeStack should be closed when its call chain is done, as a Stack should.
+type ScopeStack struct {
+	scopes        *Stack
+	hasBeenClosed uint32
+}
+
+// NewScopeStack returns an empty scope stack.
+func NewScopeStack() *ScopeStack {
+	return &ScopeStack{scopes: NewStackWithInitialSizeHint(8)}
+}
+
+// Push makes a new scope with the provided name and attributes the innermost active
+// scope.  It returns a function that ends the scope, intended to be deferred:
+//		defer scopes.Push("lookup", slog.String("key", key))()
+// The returned function pops the scope only if it is still the innermost scope, so
+// calling it more than once, or after the scope was popped by Pop(), does nothing.
+func (scopeStack *ScopeStack) Push(name string, attributes ...slog.Attr) (endScope func()) {
+	scope := &Scope{Name: name, Attributes: attributes}
+	scopeStack.scopes.Push(scope)
+
+	return func() {
+		if scopeStack.isClosed() {
+			return
+		}
+
+		scopeStack.scopes.PopIf(func(top interface{}) bool { return top == scope })
+	}
+}
+
+// Close closes the Stack on which the scope stack is built.  A closed scope stack has no
+// active scopes, and Push() panics.  Close may be called more than once.
+func (scopeStack *ScopeStack) Close() {
+	if atomic.CompareAndSwapUint32(&scopeStack.hasBeenClosed, 0, 1) {
+		scopeStack.scopes.Close()
+	}
+}
+
+func (scopeStack *ScopeStack) isClosed() bool {
+	return atomic.LoadUint32(&scopeStack.hasBeenClosed) != 0
+}
+
+// Pop ends the innermost active scope and returns it.  If there were no active scopes,
+// Pop returns nil and true.  Otherwise, it returns the popped scope and false.
+func (scopeStack *ScopeStack) Pop() (scope *Scope, stackWasEmptyBeforePop bool) {
+	if scopeStack.isClosed() {
+		return nil, true
+	}
+
+	value, stackWasEmptyBeforePop := scopeStack.scopes.Pop()
+	if stackWasEmptyBeforePop {
+		return nil, true
+	}
+
+	return value.(*Scope), false
+}
+
+// Depth returns the number of active scopes.
+func (scopeStack *ScopeStack) Depth() uint {
+	if scopeStack.isClosed() {
+		return 0
+	}
+
+	return scopeStack.scopes.Depth()
+}
+
+// Scopes returns the active scopes, from the outermost to the innermost.
+func (scopeStack *ScopeStack) Scopes() []*Scope {
+	if scopeStack.isClosed() {
+		return []*Scope{}
+	}
+
+	contents := scopeStack.scopes.contentsFromBottom()
+
+	scopes := make([]*Scope, len(contents))
+	for i, value := range contents {
+		scopes[i] = value.(*Scope)
+	}
+
+	return scopes
+}
+
+// Attrs returns the active scopes as log attributes, from the outermost to the
+// innermost.  Each scope becomes a group, named for the scope, containing the
+// scope's attributes.
+func (scopeStack *ScopeStack) Attrs() []slog.Attr {
+	scopes := scopeStack.Scopes()
+
+	attributes := make([]slog.Attr, len(scopes))
+	for i, scope := range scopes {
+		attributes[i] = slog.Attr{Key: scope.Name, Value: slog.GroupValue(scope.Attributes...)}
+	}
+
+	return attributes
+}
+
+// Clone returns a new scope stack with the same active scopes.  Scopes pushed to or
+// popped from either stack afterwards do not affect the other, and each must be closed
+// separately.
+func (scopeStack *ScopeStack) Clone() *ScopeStack {
+	if scopeStack.isClosed() {
+		return NewScopeStack()
+	}
+
+	return &ScopeStack{scopes: scopeStack.scopes.Clone()}
+}
+
+type scopeStackContextKey struct{}
+
+// ContextWithScopeStack returns a copy of the context that carries the scope stack.
+func ContextWithScopeStack(ctx context.Context, scopeStack *ScopeStack) context.Context {
+	return context.WithValue(ctx, scopeStackContextKey{}, scopeStack)
+}
+
+// ScopeStackFromContext returns the scope stack carried by the context.  If the context
+// does not carry one, it returns nil and false.
+func ScopeStackFromContext(ctx context.Context) (scopeStack *ScopeStack, contextCarriesScopeStack bool) {
+	scopeStack, contextCarriesScopeStack = ctx.Value(scopeStackContextKey{}).(*ScopeStack)
+	return scopeStack, contextCarriesScopeStack
+}
+
+// WithScope pushes a new scope onto the scope stack carried by the context, as
+// ScopeStack.Push() does.  If the context does not carry a scope stack, or carries one
+// that has been closed, a new one is created, and the returned context carries it.
+// Ending the scope then also closes the new scope stack, since the scope was its
+// outermost.  Otherwise, the returned context is the provided context.  For example:
+//		ctx, endScope := stack.WithScope(ctx, "request", slog.String("id", id))
+//		defer endScope()
+func WithScope(ctx context.Context, name string, attributes ...slog.Attr) (scopedContext context.Context, endScope func()) {
+	scopeStack, contextCarriesScopeStack := ScopeStackFromContext(ctx)
+	if contextCarriesScopeStack && !scopeStack.isClosed() {
+		return ctx, scopeStack.Push(name, attributes...)
+	}
+
+	scopeStack = NewScopeStack()
+	endOutermostScope := scopeStack.Push(name, attributes...)
+
+	return ContextWithScopeStack(ctx, scopeStack), func() {
+		endOutermostScope()
+		scopeStack.Close()
+	}
+}
+
+// ScopeHandler is a slog.Handler that adds the scopes active in the context of each
+// record, as returned by ScopeStack.Attrs(), to the record before passing it to another
+// handler.  Records logged without a context, or with a context that does not carry a
+// scope stack, are passed on unchanged.
+type ScopeHandler struct {
+	next slog.Handler
+}
+
+// NewScopeHandler returns a ScopeHandler that passes records to the provided handler.
+func NewScopeHandler(next slog.Handler) *ScopeHandler {
+	return &ScopeHandler{next: next}
+}
+
+// Enabled reports whether the next handler handles records at the level.
+func (handler *ScopeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return handler.next.Enabled(ctx, level)
+}
+
+// Handle adds the active scopes to the record and passes it to the next handler.
+func (handler *ScopeHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		if scopeStack, contextCarriesScopeStack := ScopeStackFromContext(ctx); contextCarriesScopeStack {
+			record = record.Clone()
+			record.AddAttrs(scopeStack.Attrs()...)
+		}
+	}
+
+	return handler.next.Handle(ctx, record)
+}
+
+// WithAttrs returns a ScopeHandler whose next handler has the attributes.
+func (handler *ScopeHandler) WithAttrs(attributes []slog.Attr) slog.Handler {
+	return &ScopeHandler{next: handler.next.WithAttrs(attributes)}
+}
+
+// WithGroup returns a ScopeHandler whose next handler has the group.  Scopes are then
+// added within the group.
+func (handler *ScopeHandler) WithGroup(name string) slog.Handler {
+	return &ScopeHandler{next: handler.next.WithGroup(name)}
+}
@@ -0,0 +1,111 @@
+package stack_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"runtime"
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	. "github.com/onsi/gomega"
+)
+
+func TestScopeStackPushAndEnd(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	scopes := stack.NewScopeStack()
+
+	endOuter := scopes.Push("outer", slog.String("id", "a1"))
+	func() {
+		defer scopes.Push("inner", slog.Int("n", 2))()
+		g.Expect(scopes.Depth()).To(Equal(uint(2)))
+
+		names := []string{}
+		for _, scope := range scopes.Scopes() {
+			names = append(names, scope.Name)
+		}
+		g.Expect(names).To(Equal([]string{"outer", "inner"}))
+	}()
+	g.Expect(scopes.Depth()).To(Equal(uint(1)))
+
+	clone := scopes.Clone()
+	clone.Push("only in clone")
+
+	endOuter()
+	endOuter()
+	g.Expect(scopes.Depth()).To(Equal(uint(0)))
+	g.Expect(clone.Depth()).To(Equal(uint(2)))
+
+	scope, stackWasEmpty := clone.Pop()
+	g.Expect(stackWasEmpty).To(BeFalse())
+	g.Expect(scope.Name).To(Equal("only in clone"))
+
+	_, stackWasEmpty = scopes.Pop()
+	g.Expect(stackWasEmpty).To(BeTrue())
+}
+
+func TestScopeHandlerAddsActiveScopes(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var output bytes.Buffer
+	logger := slog.New(stack.NewScopeHandler(slog.NewJSONHandler(&output, nil)))
+
+	ctx, endRequest := stack.WithScope(context.Background(), "request", slog.String("id", "r7"))
+	_, isCarried := stack.ScopeStackFromContext(ctx)
+	g.Expect(isCarried).To(BeTrue())
+
+	sameCtx, endSpan := stack.WithScope(ctx, "span", slog.String("name", "lookup"))
+	g.Expect(sameCtx).To(Equal(ctx))
+
+	logger.InfoContext(ctx, "in span", slog.Int("attempt", 1))
+	endSpan()
+	logger.InfoContext(ctx, "in request")
+	endRequest()
+	logger.InfoContext(ctx, "no scopes")
+	logger.Info("no context")
+
+	decoder := json.NewDecoder(&output)
+	records := []map[string]interface{}{}
+	for decoder.More() {
+		record := map[string]interface{}{}
+		g.Expect(decoder.Decode(&record)).To(Succeed())
+		records = append(records, record)
+	}
+
+	g.Expect(records).To(HaveLen(4))
+	g.Expect(records[0]["request"]).To(Equal(map[string]interface{}{"id": "r7"}))
+	g.Expect(records[0]["span"]).To(Equal(map[string]interface{}{"name": "lookup"}))
+	g.Expect(records[0]["attempt"]).To(Equal(float64(1)))
+	g.Expect(records[1]["request"]).To(Equal(map[string]interface{}{"id": "r7"}))
+	g.Expect(records[1]).ToNot(HaveKey("span"))
+	g.Expect(records[2]).ToNot(HaveKey("request"))
+	g.Expect(records[3]).ToNot(HaveKey("request"))
+}
+
+func TestWithScopeClosesTheScopeStackItCreates(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	numberOfGoroutinesBefore := runtime.NumGoroutine()
+
+	for i := 0; i < 100; i++ {
+		_, endScope := stack.WithScope(context.Background(), "request")
+		endScope()
+	}
+
+	g.Eventually(runtime.NumGoroutine).Should(BeNumerically("<=", numberOfGoroutinesBefore))
+
+	ctx, endScope := stack.WithScope(context.Background(), "request")
+	endScope()
+	endScope()
+
+	scopeStack, _ := stack.ScopeStackFromContext(ctx)
+	g.Expect(scopeStack.Scopes()).To(BeEmpty())
+	g.Expect(func() { scopeStack.Push("late") }).To(Panic())
+
+	reusedCtx, endReusedScope := stack.WithScope(ctx, "retry")
+	defer endReusedScope()
+	reusedScopeStack, _ := stack.ScopeStackFromContext(reusedCtx)
+	g.Expect(reusedScopeStack.Depth()).To(Equal(uint(1)))
+}
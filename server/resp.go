@@ -0,0 +1,443 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blorticus-go/stack"
+)
+
+// ServeRESP is the same as Serve(), but connections use the Redis serialization protocol
+// (RESP) rather than the line-based protocol, so that redis-cli and Redis client libraries
+// can operate on the stacks.  Each stack appears as a Redis list whose head (the left end)
+// is the top of the stack.  The supported commands are:
+//		LPUSH key value [value ...]      pushes each value in turn; replies with the depth
+//		LPOP key [count]                 pops one value, or up to count values
+//		LLEN key                         replies with the depth
+//		LRANGE key start stop            replies with the values in a range, as
+//		                                 stack.Range() returns them
+//		LTRIM key start stop             discards values outside of a range, as
+//		                                 stack.Trim() does
+//		BLPOP key [key ...] timeout      pops from the first of the stacks that has a
+//		                                 value, waiting up to timeout seconds (or forever,
+//		                                 if timeout is 0) for one to have a value
+//		PING [message], ECHO message, QUIT
+// A key is the name of a registered stack.  Unlike Redis, an unregistered key is an error
+// rather than an empty list.  If LPUSH reaches a standard stack that is full, the values
+// already pushed remain, and an error is returned.  A discarding stack accepts every
+// value, as it does for Push().  Values are pushed as strings.
+//
+// BLPOP waits by polling: every BlockingPopPollInterval, it tries to pop from each of its
+// stacks.  A registered stack may be pushed by the application as well as by clients,
+// and a Stack does not notify anyone of a push, so polling is what finds every value.
+// As a result, a value may wait on a stack for up to that interval before a blocked
+// BLPOP pops it, and each blocked BLPOP performs one Pop() per key per interval, so
+// many blocked clients, or a BLPOP of many keys, add steady load to the stacks even
+// when nothing is pushed.
+func (server *Server) ServeRESP(listener net.Listener) error {
+	return server.serve(listener, server.serveRESPConnection)
+}
+
+// ListenAndServeRESP listens on the network address, as net.Listen() does, and then calls
+// ServeRESP() with the listener.
+func (server *Server) ListenAndServeRESP(network, address string) error {
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return err
+	}
+
+	return server.ServeRESP(listener)
+}
+
+// BlockingPopPollInterval is how often a blocked BLPOP checks its stacks for a value,
+// and so the longest that a pushed value waits before a blocked BLPOP pops it.
+const BlockingPopPollInterval = 10 * time.Millisecond
+
+// maximumPreallocatedArguments limits the storage for arguments that readCommand()
+// allocates on the strength of the count sent by the client, before any argument has
+// been read.  Commands with more arguments grow the storage as the arguments arrive.
+const maximumPreallocatedArguments = 16
+
+// errQuit is returned by a command handler to close the connection after the reply.
+var errQuit = errors.New("quit")
+
+type respConnection struct {
+	server     *Server
+	connection net.Conn
+	reader     *bufio.Reader
+	writer     *bufio.Writer
+}
+
+func (server *Server) serveRESPConnection(connection net.Conn) {
+	c := &respConnection{
+		server:     server,
+		connection: connection,
+		reader:     bufio.NewReaderSize(connection, 4096),
+		writer:     bufio.NewWriter(connection),
+	}
+
+	for {
+		arguments, err := c.readCommand()
+		if err != nil {
+			var protocolError *respProtocolError
+			if errors.As(err, &protocolError) {
+				c.writeError("ERR Protocol error: " + protocolError.message)
+				c.writer.Flush()
+			}
+			return
+		}
+
+		if len(arguments) == 0 {
+			continue
+		}
+
+		err = c.execute(arguments)
+
+		// Replies to commands that were sent together are written together.
+		if c.reader.Buffered() == 0 || err != nil {
+			if flushErr := c.writer.Flush(); flushErr != nil {
+				return
+			}
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+type respProtocolError struct {
+	message string
+}
+
+func (err *respProtocolError) Error() string {
+	return err.message
+}
+
+// readCommand reads a command, which is either an array of bulk strings or, as redis-cli
+// may send when used interactively, an inline command of words separated by spaces.
+func (c *respConnection) readCommand() ([]string, error) {
+	line, err := readRequestLine(c.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+
+	numberOfArguments, err := strconv.Atoi(line[1:])
+	if err != nil || numberOfArguments > MaximumRequestLength {
+		return nil, &respProtocolError{"invalid multibulk length"}
+	}
+
+	arguments := make([]string, 0, min(max(numberOfArguments, 0), maximumPreallocatedArguments))
+	totalLength := 0
+	for i := 0; i < numberOfArguments; i++ {
+		header, err := readRequestLine(c.reader)
+		if err != nil {
+			return nil, err
+		}
+
+		if !strings.HasPrefix(header, "$") {
+			return nil, &respProtocolError{fmt.Sprintf("expected '$', got '%.1s'", header)}
+		}
+
+		length, err := strconv.Atoi(header[1:])
+		if err != nil || length < 0 || length > MaximumRequestLength-totalLength {
+			return nil, &respProtocolError{"invalid bulk length"}
+		}
+		totalLength += length
+
+		argument := make([]byte, length+2)
+		if _, err := io.ReadFull(c.reader, argument); err != nil {
+			return nil, err
+		}
+
+		if argument[length] != '\r' || argument[length+1] != '\n' {
+			return nil, &respProtocolError{"bulk string is not terminated by CRLF"}
+		}
+
+		arguments = append(arguments, string(argument[:length]))
+	}
+
+	return arguments, nil
+}
+
+// execute carries out a command and writes its reply.  An error is returned only if the
+// connection should be closed.  A panic while carrying out the command, such as one
+// raised by the Backend of a stack, is replied to as an error, as it is by the line
+// protocol, rather than ending the server.
+func (c *respConnection) execute(arguments []string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.writeError(fmt.Sprintf("ERR %v", r))
+			err = nil
+		}
+	}()
+
+	nameOfCommand := arguments[0]
+	command := strings.ToUpper(nameOfCommand)
+	arguments = arguments[1:]
+
+	switch command {
+	case "PING":
+		if len(arguments) > 1 {
+			c.writeWrongNumberOfArguments(command)
+		} else if len(arguments) == 1 {
+			c.writeBulkString(arguments[0])
+		} else {
+			c.writeSimpleString("PONG")
+		}
+
+	case "ECHO":
+		if len(arguments) != 1 {
+			c.writeWrongNumberOfArguments(command)
+		} else {
+			c.writeBulkString(arguments[0])
+		}
+
+	case "QUIT":
+		c.writeSimpleString("OK")
+		return errQuit
+
+	case "LPUSH":
+		c.executeListCommand(command, arguments, 2, -1, c.lpush)
+
+	case "LPOP":
+		c.executeListCommand(command, arguments, 1, 2, c.lpop)
+
+	case "LLEN":
+		c.executeListCommand(command, arguments, 1, 1, func(s *stack.Stack, _ []string) {
+			c.writeInteger(int64(s.Depth()))
+		})
+
+	case "LRANGE":
+		c.executeListCommand(command, arguments, 3, 3, c.lrange)
+
+	case "LTRIM":
+		c.executeListCommand(command, arguments, 3, 3, c.ltrim)
+
+	case "BLPOP":
+		return c.blpop(arguments)
+
+	default:
+		c.writeError(fmt.Sprintf("ERR unknown command '%s'", nameOfCommand))
+	}
+
+	return nil
+}
+
+// executeListCommand checks the number of arguments of a command whose first argument is
+// a key, and if it is correct and the key is registered, calls the handler for the
+// command with the stack and the rest of the arguments.  A maximum of -1 means there is
+// no maximum.
+func (c *respConnection) executeListCommand(command string, arguments []string, minimumNumberOfArguments, maximumNumberOfArguments int, handler func(s *stack.Stack, arguments []string)) {
+	if len(arguments) < minimumNumberOfArguments || (maximumNumberOfArguments >= 0 && len(arguments) > maximumNumberOfArguments) {
+		c.writeWrongNumberOfArguments(command)
+		return
+	}
+
+	s, err := c.server.stackNamed(arguments[0])
+	if err != nil {
+		c.writeError("ERR " + err.Error())
+		return
+	}
+
+	handler(s, arguments[1:])
+}
+
+func (c *respConnection) lpush(s *stack.Stack, values []string) {
+	for _, value := range values {
+		if s.Push(value) && !s.IsDiscarding() {
+			c.writeError("FULL stack is full")
+			return
+		}
+	}
+
+	c.writeInteger(int64(s.Depth()))
+}
+
+func (c *respConnection) lpop(s *stack.Stack, arguments []string) {
+	if len(arguments) == 0 {
+		value, stackWasEmpty := s.Pop()
+		if stackWasEmpty {
+			c.writeNullBulkString()
+		} else {
+			c.writeBulkString(stringFrom(value))
+		}
+		return
+	}
+
+	count, err := strconv.Atoi(arguments[0])
+	if err != nil || count < 0 {
+		c.writeError("ERR value is out of range, must be positive")
+		return
+	}
+
+	values := make([]string, 0)
+	for len(values) < count {
+		value, stackWasEmpty := s.Pop()
+		if stackWasEmpty {
+			break
+		}
+		values = append(values, stringFrom(value))
+	}
+
+	if len(values) == 0 && count > 0 {
+		c.writeNullArray()
+		return
+	}
+
+	c.writeArrayOfBulkStrings(values)
+}
+
+func (c *respConnection) lrange(s *stack.Stack, arguments []string) {
+	start, stop, err := startAndStopFrom(arguments)
+	if err != nil {
+		c.writeError("ERR " + err.Error())
+		return
+	}
+
+	elements := s.Range(start, stop)
+
+	values := make([]string, len(elements))
+	for i, element := range elements {
+		values[i] = stringFrom(element)
+	}
+
+	c.writeArrayOfBulkStrings(values)
+}
+
+func (c *respConnection) ltrim(s *stack.Stack, arguments []string) {
+	start, stop, err := startAndStopFrom(arguments)
+	if err != nil {
+		c.writeError("ERR " + err.Error())
+		return
+	}
+
+	s.Trim(start, stop)
+	c.writeSimpleString("OK")
+}
+
+func startAndStopFrom(arguments []string) (start, stop int, err error) {
+	start, startErr := strconv.Atoi(arguments[0])
+	stop, stopErr := strconv.Atoi(arguments[1])
+	if startErr != nil || stopErr != nil {
+		return 0, 0, fmt.Errorf("value is not an integer or out of range")
+	}
+
+	return start, stop, nil
+}
+
+func (c *respConnection) blpop(arguments []string) error {
+	if len(arguments) < 2 {
+		c.writeWrongNumberOfArguments("BLPOP")
+		return nil
+	}
+
+	keys, timeoutArgument := arguments[:len(arguments)-1], arguments[len(arguments)-1]
+
+	timeoutInSeconds, err := strconv.ParseFloat(timeoutArgument, 64)
+	if err != nil || timeoutInSeconds < 0 {
+		c.writeError("ERR timeout is not a float or out of range")
+		return nil
+	}
+
+	stacks := make([]*stack.Stack, len(keys))
+	for i, key := range keys {
+		if stacks[i], err = c.server.stackNamed(key); err != nil {
+			c.writeError("ERR " + err.Error())
+			return nil
+		}
+	}
+
+	var deadline time.Time
+	if timeoutInSeconds > 0 {
+		deadline = time.Now().Add(time.Duration(timeoutInSeconds * float64(time.Second)))
+	}
+
+	for {
+		for i, s := range stacks {
+			if value, stackWasEmpty := s.Pop(); !stackWasEmpty {
+				c.writeArrayOfBulkStrings([]string{keys[i], stringFrom(value)})
+				return nil
+			}
+		}
+
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			c.writeNullArray()
+			return nil
+		}
+
+		if err := c.waitWhileConnectionIsOpen(BlockingPopPollInterval); err != nil {
+			return err
+		}
+	}
+}
+
+// waitWhileConnectionIsOpen waits for the duration.  If the client closes the connection
+// or the server is closed in the meantime, an error is returned, so that a blocked BLPOP
+// does not pop a value it cannot deliver.
+func (c *respConnection) waitWhileConnectionIsOpen(duration time.Duration) error {
+	if c.server.hasBeenClosed() {
+		return ErrServerClosed
+	}
+
+	if c.reader.Buffered() > 0 {
+		time.Sleep(duration)
+		return nil
+	}
+
+	c.connection.SetReadDeadline(time.Now().Add(duration))
+	_, err := c.reader.Peek(1)
+	c.connection.SetReadDeadline(time.Time{})
+
+	var netError net.Error
+	if err != nil && !(errors.As(err, &netError) && netError.Timeout()) {
+		return err
+	}
+
+	return nil
+}
+
+func (c *respConnection) writeSimpleString(s string) {
+	c.writer.WriteString("+" + s + "\r\n")
+}
+
+func (c *respConnection) writeError(message string) {
+	c.writer.WriteString("-" + message + "\r\n")
+}
+
+func (c *respConnection) writeWrongNumberOfArguments(command string) {
+	c.writeError(fmt.Sprintf("ERR wrong number of arguments for '%s' command", strings.ToLower(command)))
+}
+
+func (c *respConnection) writeInteger(i int64) {
+	c.writer.WriteString(":" + strconv.FormatInt(i, 10) + "\r\n")
+}
+
+func (c *respConnection) writeBulkString(s string) {
+	c.writer.WriteString("$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n")
+}
+
+func (c *respConnection) writeNullBulkString() {
+	c.writer.WriteString("$-1\r\n")
+}
+
+func (c *respConnection) writeArrayOfBulkStrings(values []string) {
+	c.writer.WriteString("*" + strconv.Itoa(len(values)) + "\r\n")
+	for _, value := range values {
+		c.writeBulkString(value)
+	}
+}
+
+func (c *respConnection) writeNullArray() {
+	c.writer.WriteString("*-1\r\n")
+}
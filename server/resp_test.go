@@ -0,0 +1,292 @@
+package server_test
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blorticus-go/stack"
+	"github.com/blorticus-go/stack/server"
+)
+
+func startRESPServer(t *testing.T, stacks map[string]*stack.Stack) string {
+	s := server.New()
+	for name, st := range stacks {
+		s.Register(name, st)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen on loopback: %s", err)
+	}
+
+	served := make(chan error, 1)
+	go func() { served <- s.ServeRESP(listener) }()
+
+	t.Cleanup(func() {
+		s.Close()
+		if err := <-served; !errors.Is(err, server.ErrServerClosed) {
+			t.Errorf("expected ServeRESP() to return ErrServerClosed, got (%v)", err)
+		}
+	})
+
+	return listener.Addr().String()
+}
+
+// respCommand encodes a command as an array of bulk strings, as Redis clients send it.
+func respCommand(arguments ...string) string {
+	var command strings.Builder
+	fmt.Fprintf(&command, "*%d\r\n", len(arguments))
+	for _, argument := range arguments {
+		fmt.Fprintf(&command, "$%d\r\n%s\r\n", len(argument), argument)
+	}
+
+	return command.String()
+}
+
+// readRESPReply reads a complete reply and returns it in its wire form.
+func readRESPReply(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	switch line[0] {
+	case '$':
+		var length int
+		fmt.Sscanf(line, "$%d", &length)
+		if length < 0 {
+			return line, nil
+		}
+
+		body := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, body); err != nil {
+			return "", err
+		}
+		return line + string(body), nil
+
+	case '*':
+		var numberOfElements int
+		fmt.Sscanf(line, "*%d", &numberOfElements)
+
+		reply := line
+		for i := 0; i < numberOfElements; i++ {
+			element, err := readRESPReply(reader)
+			if err != nil {
+				return "", err
+			}
+			reply += element
+		}
+		return reply, nil
+	}
+
+	return line, nil
+}
+
+func TestRESPListCommands(t *testing.T) {
+	address := startRESPServer(t, map[string]*stack.Stack{
+		"list":    stack.NewStack(),
+		"bounded": stack.NewStack().WithAMaximumDepthOf(2),
+		"window":  stack.NewBoundedDiscardingStack(2),
+	})
+
+	connection, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("unable to connect: %s", err)
+	}
+	defer connection.Close()
+
+	reader := bufio.NewReader(connection)
+
+	for _, testCase := range []struct {
+		command       string
+		expectedReply string
+	}{
+		{respCommand("PING"), "+PONG\r\n"},
+		{respCommand("ping", "hi"), "$2\r\nhi\r\n"},
+		{"PING\r\n", "+PONG\r\n"},
+		{respCommand("ECHO", "a\r\nb"), "$4\r\na\r\nb\r\n"},
+		{respCommand("LPUSH", "list", "a", "b", "c"), ":3\r\n"},
+		{respCommand("LLEN", "list"), ":3\r\n"},
+		{respCommand("LRANGE", "list", "0", "-1"), "*3\r\n$1\r\nc\r\n$1\r\nb\r\n$1\r\na\r\n"},
+		{respCommand("LRANGE", "list", "1", "1"), "*1\r\n$1\r\nb\r\n"},
+		{respCommand("LRANGE", "list", "5", "10"), "*0\r\n"},
+		{respCommand("LPOP", "list"), "$1\r\nc\r\n"},
+		{"LPUSH list d e\r\n", ":4\r\n"},
+		{respCommand("LTRIM", "list", "0", "1"), "+OK\r\n"},
+		{respCommand("LRANGE", "list", "0", "-1"), "*2\r\n$1\r\ne\r\n$1\r\nd\r\n"},
+		{respCommand("LPOP", "list", "5"), "*2\r\n$1\r\ne\r\n$1\r\nd\r\n"},
+		{respCommand("LPOP", "list"), "$-1\r\n"},
+		{respCommand("LPOP", "list", "2"), "*-1\r\n"},
+		{respCommand("LPOP", "list", "0"), "*0\r\n"},
+		{respCommand("LPUSH", "bounded", "1", "2", "3"), "-FULL stack is full\r\n"},
+		{respCommand("LLEN", "bounded"), ":2\r\n"},
+		{respCommand("LPUSH", "window", "1", "2", "3"), ":2\r\n"},
+		{respCommand("LRANGE", "window", "0", "-1"), "*2\r\n$1\r\n3\r\n$1\r\n2\r\n"},
+		{respCommand("LPOP", "list", "-1"), "-ERR value is out of range, must be positive\r\n"},
+		{respCommand("LRANGE", "list", "x", "1"), "-ERR value is not an integer or out of range\r\n"},
+		{respCommand("LLEN", "missing"), "-ERR no stack named (missing)\r\n"},
+		{respCommand("LPUSH", "list"), "-ERR wrong number of arguments for 'lpush' command\r\n"},
+		{respCommand("HSET", "h", "f", "v"), "-ERR unknown command 'HSET'\r\n"},
+		{respCommand("BLPOP", "window", "0"), "*2\r\n$6\r\nwindow\r\n$1\r\n3\r\n"},
+		{respCommand("BLPOP", "list", "0.05"), "*-1\r\n"},
+		{respCommand("BLPOP", "list", "-1"), "-ERR timeout is not a float or out of range\r\n"},
+	} {
+		fmt.Fprint(connection, testCase.command)
+
+		reply, err := readRESPReply(reader)
+		if err != nil {
+			t.Fatalf("[%q] unexpected error reading reply: %s", testCase.command, err)
+		}
+
+		if reply != testCase.expectedReply {
+			t.Errorf("[%q] expected reply (%q), got (%q)", testCase.command, testCase.expectedReply, reply)
+		}
+	}
+
+	fmt.Fprint(connection, respCommand("QUIT"))
+	if reply, _ := readRESPReply(reader); reply != "+OK\r\n" {
+		t.Errorf("expected +OK for QUIT, got (%q)", reply)
+	}
+
+	if _, err := reader.ReadByte(); err == nil {
+		t.Errorf("expected connection to be closed after QUIT")
+	}
+}
+
+func TestRESPBlockingPop(t *testing.T) {
+	first, second := stack.NewStack(), stack.NewStack()
+	address := startRESPServer(t, map[string]*stack.Stack{"first": first, "second": second})
+
+	connection, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("unable to connect: %s", err)
+	}
+	defer connection.Close()
+
+	reader := bufio.NewReader(connection)
+	fmt.Fprint(connection, respCommand("BLPOP", "first", "second", "5"))
+
+	time.Sleep(50 * time.Millisecond)
+	second.Push("value")
+
+	reply, err := readRESPReply(reader)
+	if err != nil {
+		t.Fatalf("unexpected error reading reply: %s", err)
+	}
+
+	if expectedReply := "*2\r\n$6\r\nsecond\r\n$5\r\nvalue\r\n"; reply != expectedReply {
+		t.Errorf("expected reply (%q), got (%q)", expectedReply, reply)
+	}
+}
+
+func TestRESPBlockingPopAbandonedByClient(t *testing.T) {
+	s := stack.NewStack()
+	address := startRESPServer(t, map[string]*stack.Stack{"s": s})
+
+	connection, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("unable to connect: %s", err)
+	}
+
+	fmt.Fprint(connection, respCommand("BLPOP", "s", "0"))
+	time.Sleep(50 * time.Millisecond)
+	connection.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	s.Push("kept")
+	time.Sleep(3 * server.BlockingPopPollInterval)
+
+	if depth := s.Depth(); depth != 1 {
+		t.Errorf("expected value to remain on the stack after client closed, depth is %d", depth)
+	}
+}
+
+func TestRESPProtocolError(t *testing.T) {
+	address := startRESPServer(t, map[string]*stack.Stack{})
+
+	connection, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("unable to connect: %s", err)
+	}
+	defer connection.Close()
+
+	fmt.Fprint(connection, "*1\r\n:1\r\n")
+
+	reply, _ := readRESPReply(bufio.NewReader(connection))
+	if !strings.HasPrefix(reply, "-ERR Protocol error") {
+		t.Errorf("expected protocol error, got (%q)", reply)
+	}
+}
+
+func TestRESPBulkLengthThatWouldOverflow(t *testing.T) {
+	address := startRESPServer(t, map[string]*stack.Stack{})
+
+	connection, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("unable to connect: %s", err)
+	}
+	defer connection.Close()
+
+	fmt.Fprint(connection, "*2\r\n$1\r\na\r\n$9223372036854775807\r\n")
+
+	reply, _ := readRESPReply(bufio.NewReader(connection))
+	if reply != "-ERR Protocol error: invalid bulk length\r\n" {
+		t.Errorf("expected invalid bulk length error, got (%q)", reply)
+	}
+
+	expectRESPServerToReplyToPing(t, address)
+}
+
+// panickingBackend is a Backend that cannot provide its elements, as a SpillingBackend
+// cannot when a spilled segment is unreadable.
+type panickingBackend struct {
+	stack.Backend
+}
+
+func (backend *panickingBackend) ElementAt(distanceFromTop uint) interface{} {
+	panic("unable to read element")
+}
+
+func TestRESPBackendPanic(t *testing.T) {
+	s, _ := stack.New(stack.WithBackend(&panickingBackend{stack.NewRingBackend(4)}))
+	s.Push("a")
+	address := startRESPServer(t, map[string]*stack.Stack{"broken": s})
+
+	connection, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("unable to connect: %s", err)
+	}
+	defer connection.Close()
+	reader := bufio.NewReader(connection)
+
+	fmt.Fprint(connection, respCommand("LRANGE", "broken", "0", "-1"))
+	if reply, _ := readRESPReply(reader); reply != "-ERR unable to read element\r\n" {
+		t.Errorf("expected error reply to LRANGE, got (%q)", reply)
+	}
+
+	fmt.Fprint(connection, respCommand("PING"))
+	if reply, _ := readRESPReply(reader); reply != "+PONG\r\n" {
+		t.Errorf("expected PONG on the same connection, got (%q)", reply)
+	}
+
+	expectRESPServerToReplyToPing(t, address)
+}
+
+func expectRESPServerToReplyToPing(t *testing.T, address string) {
+	connection, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("unable to connect again: %s", err)
+	}
+	defer connection.Close()
+
+	fmt.Fprint(connection, respCommand("PING"))
+	if reply, _ := readRESPReply(bufio.NewReader(connection)); reply != "+PONG\r\n" {
+		t.Errorf("expected PONG from server, got (%q)", reply)
+	}
+}
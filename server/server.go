@@ -0,0 +1,356 @@
+// Package server exposes named stacks to other processes and hosts over TCP or Unix
+// sockets, using a simple line-based protocol.  Package client provides the other end.
+//
+// Each request is a single line, and each request receives a single line in response.
+// A request is a command, the name of a stack and, for some commands, an argument,
+// separated by single spaces:
+//		PUSH <name> <value>      pushes the value; responds OK or FULL
+//		POP <name>               pops a value; responds VALUE <value> or EMPTY
+//		PEEK <name>              returns the top value; responds VALUE <value> or EMPTY
+//		DEPTH <name>             responds DEPTH <depth>
+//		RESET <name>             empties the stack; responds OK
+//		SETMAX <name> <depth>    sets the maximum depth, or removes it if <depth> is 0;
+//		                         responds OK
+// Values are double-quoted with Go escapes, as strconv.Quote() produces, so a value may
+// contain any bytes.  FULL means that Push() on the stack returned true.  Commands are
+// not case-sensitive.  Any request that cannot be carried out receives ERROR followed
+// by a quoted message.  Lines end with a newline, and a carriage return before the
+// newline is ignored.
+//
+// Values are pushed as strings.  A popped value that is not a string or a []byte is
+// formatted with fmt.Sprint().
+//
+// A Server can also serve the stacks as Redis lists, using the Redis protocol, with
+// ServeRESP().
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/blorticus-go/stack"
+)
+
+// ErrServerClosed is returned by Serve() after Close() is called.
+var ErrServerClosed = errors.New("server closed")
+
+// MaximumRequestLength is the length in bytes of the longest request line accepted.  A
+// connection that sends a longer line is closed.
+const MaximumRequestLength = 1 << 20
+
+// Server serves a set of named stacks.  Stacks may be registered and unregistered while
+// the Server is serving.
+type Server struct {
+	stacksMutex sync.RWMutex
+	stacks      map[string]*stack.Stack
+
+	closersMutex sync.Mutex
+	// openClosers are the listeners and connections that Close() closes.
+	openClosers map[io.Closer]struct{}
+	isClosed    bool
+}
+
+// New returns a Server with no registered stacks.
+func New() *Server {
+	return &Server{
+		stacks:      make(map[string]*stack.Stack),
+		openClosers: make(map[io.Closer]struct{}),
+	}
+}
+
+// Register makes a stack available to clients by the provided name.  The name must not
+// be empty, must not contain whitespace and must not already be registered.
+func (server *Server) Register(name string, s *stack.Stack) error {
+	if name == "" || strings.IndexFunc(name, isSpace) >= 0 {
+		return fmt.Errorf("stack name (%q) must be non-empty and must not contain whitespace", name)
+	}
+
+	if s == nil {
+		return fmt.Errorf("stack must not be nil")
+	}
+
+	server.stacksMutex.Lock()
+	defer server.stacksMutex.Unlock()
+
+	if _, nameIsRegistered := server.stacks[name]; nameIsRegistered {
+		return fmt.Errorf("a stack named (%s) is already registered", name)
+	}
+
+	server.stacks[name] = s
+	return nil
+}
+
+// Unregister removes the stack with the provided name, if there is one, so that it is no
+// longer available to clients.
+func (server *Server) Unregister(name string) {
+	server.stacksMutex.Lock()
+	defer server.stacksMutex.Unlock()
+
+	delete(server.stacks, name)
+}
+
+func (server *Server) stackNamed(name string) (*stack.Stack, error) {
+	server.stacksMutex.RLock()
+	defer server.stacksMutex.RUnlock()
+
+	s, nameIsRegistered := server.stacks[name]
+	if !nameIsRegistered {
+		return nil, fmt.Errorf("no stack named (%s)", name)
+	}
+
+	return s, nil
+}
+
+// ListenAndServe listens on the network address, as net.Listen() does, and then calls
+// Serve() with the listener.
+func (server *Server) ListenAndServe(network, address string) error {
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return err
+	}
+
+	return server.Serve(listener)
+}
+
+// Serve accepts connections on the listener, serving each on its own goroutine, until
+// the listener fails or Close() is called.  The listener is closed when Serve returns.
+// After Close() is called, ErrServerClosed is returned.
+func (server *Server) Serve(listener net.Listener) error {
+	return server.serve(listener, server.serveConnection)
+}
+
+func (server *Server) serve(listener net.Listener, serveConnection func(connection net.Conn)) error {
+	if !server.track(listener) {
+		listener.Close()
+		return ErrServerClosed
+	}
+	defer server.untrack(listener)
+	defer listener.Close()
+
+	for {
+		connection, err := listener.Accept()
+		if err != nil {
+			if server.hasBeenClosed() {
+				return ErrServerClosed
+			}
+			return err
+		}
+
+		if !server.track(connection) {
+			connection.Close()
+			return ErrServerClosed
+		}
+
+		go func() {
+			defer server.untrack(connection)
+			defer connection.Close()
+
+			serveConnection(connection)
+		}()
+	}
+}
+
+// Close closes all listeners and connections, causing Serve() to return.  The registered
+// stacks are not changed.
+func (server *Server) Close() error {
+	server.closersMutex.Lock()
+	defer server.closersMutex.Unlock()
+
+	server.isClosed = true
+
+	var firstError error
+	for closer := range server.openClosers {
+		if err := closer.Close(); err != nil && firstError == nil {
+			firstError = err
+		}
+	}
+
+	return firstError
+}
+
+func (server *Server) serveConnection(connection net.Conn) {
+	reader := bufio.NewReaderSize(connection, 4096)
+	writer := bufio.NewWriter(connection)
+
+	for {
+		request, err := readRequestLine(reader)
+		if err != nil {
+			return
+		}
+
+		writer.WriteString(server.responseTo(request))
+		writer.WriteByte('\n')
+
+		// Responses to requests that were sent together are written together.
+		if reader.Buffered() == 0 {
+			if err := writer.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func readRequestLine(reader *bufio.Reader) (string, error) {
+	var line []byte
+
+	for {
+		fragment, isPrefix, err := reader.ReadLine()
+		if err != nil {
+			return "", err
+		}
+
+		line = append(line, fragment...)
+		if len(line) > MaximumRequestLength {
+			return "", fmt.Errorf("request exceeds %d bytes", MaximumRequestLength)
+		}
+
+		if !isPrefix {
+			return string(line), nil
+		}
+	}
+}
+
+// commandTakesArgument records, for each command, whether it takes an argument after
+// the stack name.
+var commandTakesArgument = map[string]bool{
+	"PUSH":   true,
+	"POP":    false,
+	"PEEK":   false,
+	"DEPTH":  false,
+	"RESET":  false,
+	"SETMAX": true,
+}
+
+func (server *Server) responseTo(request string) (response string) {
+	fields := strings.SplitN(request, " ", 3)
+	if len(fields) < 2 {
+		return errorResponse("request must have a command and a stack name")
+	}
+
+	command, name := strings.ToUpper(fields[0]), fields[1]
+	argument, hasArgument := "", len(fields) == 3
+	if hasArgument {
+		argument = fields[2]
+	}
+
+	takesArgument, commandIsKnown := commandTakesArgument[command]
+	if !commandIsKnown {
+		return errorResponse(fmt.Sprintf("unknown command (%s)", fields[0]))
+	}
+
+	if hasArgument != takesArgument {
+		if takesArgument {
+			return errorResponse(fmt.Sprintf("%s requires an argument", command))
+		}
+		return errorResponse(fmt.Sprintf("%s does not take an argument", command))
+	}
+
+	s, err := server.stackNamed(name)
+	if err != nil {
+		return errorResponse(err.Error())
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			response = errorResponse(fmt.Sprint(r))
+		}
+	}()
+
+	switch command {
+	case "PUSH":
+		value, err := strconv.Unquote(argument)
+		if err != nil {
+			return errorResponse("value must be a quoted string")
+		}
+		if s.Push(value) {
+			return "FULL"
+		}
+		return "OK"
+
+	case "POP":
+		return valueResponse(s.Pop())
+
+	case "PEEK":
+		return valueResponse(s.Peek())
+
+	case "DEPTH":
+		return fmt.Sprintf("DEPTH %d", s.Depth())
+
+	case "RESET":
+		s.ResetToEmpty()
+		return "OK"
+
+	case "SETMAX":
+		maximumDepth, err := strconv.ParseUint(argument, 10, 0)
+		if err != nil {
+			return errorResponse("maximum depth must be a non-negative integer")
+		}
+		if maximumDepth == 0 {
+			s.RemoveMaximumDepth()
+		} else {
+			s.SetMaximumDepthTo(uint(maximumDepth))
+		}
+	}
+
+	return "OK"
+}
+
+func valueResponse(value interface{}, stackIsEmpty bool) string {
+	if stackIsEmpty {
+		return "EMPTY"
+	}
+
+	return "VALUE " + strconv.Quote(stringFrom(value))
+}
+
+// stringFrom returns a stack value as the string sent to clients.
+func stringFrom(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func errorResponse(message string) string {
+	return "ERROR " + strconv.Quote(message)
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\v' || r == '\f'
+}
+
+func (server *Server) track(closer io.Closer) (serverIsOpen bool) {
+	server.closersMutex.Lock()
+	defer server.closersMutex.Unlock()
+
+	if server.isClosed {
+		return false
+	}
+
+	server.openClosers[closer] = struct{}{}
+	return true
+}
+
+func (server *Server) untrack(closer io.Closer) {
+	server.closersMutex.Lock()
+	defer server.closersMutex.Unlock()
+
+	delete(server.openClosers, closer)
+}
+
+func (server *Server) hasBeenClosed() bool {
+	server.closersMutex.Lock()
+	defer server.closersMutex.Unlock()
+
+	return server.isClosed
+}
@@ -0,0 +1,161 @@
+package server_test
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	"github.com/blorticus-go/stack/server"
+)
+
+func startServer(t *testing.T, stacks map[string]*stack.Stack) (s *server.Server, address string) {
+	s = server.New()
+	for name, st := range stacks {
+		if err := s.Register(name, st); err != nil {
+			t.Fatalf("unexpected error on Register(%s): %s", name, err)
+		}
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen on loopback: %s", err)
+	}
+
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(listener) }()
+
+	t.Cleanup(func() {
+		s.Close()
+		if err := <-served; !errors.Is(err, server.ErrServerClosed) {
+			t.Errorf("expected Serve() to return ErrServerClosed, got (%v)", err)
+		}
+	})
+
+	return s, listener.Addr().String()
+}
+
+func TestProtocol(t *testing.T) {
+	bounded := stack.NewStack().WithAMaximumDepthOf(2)
+	bounded.Push(42)
+	_, address := startServer(t, map[string]*stack.Stack{
+		"work":    stack.NewStack(),
+		"bounded": bounded,
+		"window":  stack.NewBoundedDiscardingStack(2),
+	})
+
+	connection, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("unable to connect: %s", err)
+	}
+	defer connection.Close()
+
+	reader := bufio.NewReader(connection)
+
+	for _, testCase := range []struct {
+		request          string
+		expectedResponse string
+	}{
+		{`PUSH work "a b"`, `OK`},
+		{`push work "\x00\n"`, `OK`},
+		{`DEPTH work`, `DEPTH 2`},
+		{`PEEK work`, `VALUE "\x00\n"`},
+		{`POP work`, `VALUE "\x00\n"`},
+		{`POP work`, `VALUE "a b"`},
+		{`POP work`, `EMPTY`},
+		{`PEEK work`, `EMPTY`},
+		{`POP bounded`, `VALUE "42"`},
+		{`PUSH bounded "1"`, `OK`},
+		{`PUSH bounded "2"`, `OK`},
+		{`PUSH bounded "3"`, `FULL`},
+		{`SETMAX bounded 1`, `OK`},
+		{`DEPTH bounded`, `DEPTH 1`},
+		{`SETMAX bounded 0`, `OK`},
+		{`PUSH bounded "3"`, `OK`},
+		{"RESET bounded\r", `OK`},
+		{`DEPTH bounded`, `DEPTH 0`},
+		{`PUSH window "1"`, `OK`},
+		{`PUSH window "2"`, `FULL`},
+		{`SETMAX window 1`, `ERROR "You may not set a maximum stack depth with a discarding stack"`},
+		{`SETMAX work -1`, `ERROR "maximum depth must be a non-negative integer"`},
+		{`PUSH work unquoted`, `ERROR "value must be a quoted string"`},
+		{`PUSH work`, `ERROR "PUSH requires an argument"`},
+		{`DEPTH work 1`, `ERROR "DEPTH does not take an argument"`},
+		{`POP missing`, `ERROR "no stack named (missing)"`},
+		{`SHOVE work "1"`, `ERROR "unknown command (SHOVE)"`},
+		{`POP`, `ERROR "request must have a command and a stack name"`},
+	} {
+		fmt.Fprintf(connection, "%s\n", testCase.request)
+
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("[%s] unexpected error reading response: %s", testCase.request, err)
+		}
+
+		if response != testCase.expectedResponse+"\n" {
+			t.Errorf("[%s] expected response (%s), got (%s)", testCase.request, testCase.expectedResponse, response[:len(response)-1])
+		}
+	}
+}
+
+func TestPipelinedRequests(t *testing.T) {
+	_, address := startServer(t, map[string]*stack.Stack{"s": stack.NewStack()})
+
+	connection, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("unable to connect: %s", err)
+	}
+	defer connection.Close()
+
+	fmt.Fprint(connection, "PUSH s \"1\"\nPUSH s \"2\"\nPOP s\nDEPTH s\n")
+
+	reader := bufio.NewReader(connection)
+	for _, expectedResponse := range []string{"OK\n", "OK\n", "VALUE \"2\"\n", "DEPTH 1\n"} {
+		if response, _ := reader.ReadString('\n'); response != expectedResponse {
+			t.Errorf("expected response (%q), got (%q)", expectedResponse, response)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	s := server.New()
+
+	for _, name := range []string{"", "has space", "tab\there"} {
+		if err := s.Register(name, stack.NewStack()); err == nil {
+			t.Errorf("[%q] expected error on Register, got none", name)
+		}
+	}
+
+	if err := s.Register("s", nil); err == nil {
+		t.Errorf("expected error on Register with nil stack, got none")
+	}
+
+	if err := s.Register("s", stack.NewStack()); err != nil {
+		t.Fatalf("unexpected error on Register: %s", err)
+	}
+
+	if err := s.Register("s", stack.NewStack()); err == nil {
+		t.Errorf("expected error on Register of duplicate name, got none")
+	}
+
+	s.Unregister("s")
+	if err := s.Register("s", stack.NewStack()); err != nil {
+		t.Errorf("unexpected error on Register after Unregister: %s", err)
+	}
+}
+
+func TestServeAfterClose(t *testing.T) {
+	s := server.New()
+	s.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen on loopback: %s", err)
+	}
+
+	if err := s.Serve(listener); !errors.Is(err, server.ErrServerClosed) {
+		t.Errorf("expected ErrServerClosed, got (%v)", err)
+	}
+}
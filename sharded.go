@@ -0,0 +1,152 @@
+package stack
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// ShardedStack is a stack of arbitrary, untyped values that spreads its elements across
+// a number of internal stacks, called shards, so that many goroutines can push and pop
+// concurrently without all waiting on a single Stack.
+//
+// The price is that ordering is relaxed.  A ShardedStack tracks the shard that received
+// the most recent Push() and starts each Pop() there.  If that shard is empty, Pop()
+// steals from the other shards, most recently pushed first.  When a single goroutine
+// uses a ShardedStack and no shard fills, it behaves exactly as a LIFO stack.  When
+// goroutines push and pop concurrently, a Pop() returns one of the most recently pushed
+// values but not necessarily the most recent.  A Pop() reports that the stack was empty
+// only if it found every shard empty.
+type ShardedStack struct {
+	shards                   []*Stack
+	indexOfNextShardForPush  uint64
+	shardsDiscardAtMaxDepths bool
+}
+
+// NewShardedStack returns an empty sharded stack with the specified number of shards.
+// Each shard is created by New() with the provided options, so, for example, a maximum
+// depth applies separately to each shard.  An error is returned if the number of shards
+// is zero or if the options are invalid.  WithBackend() may not be used, because a
+// Backend cannot be shared between shards.
+func NewShardedStack(numberOfShards uint, options ...Option) (*ShardedStack, error) {
+	if numberOfShards < 1 {
+		return nil, fmt.Errorf("a sharded stack must have at least one shard")
+	}
+
+	shardConfiguration, err := newConfigurationFrom(options)
+	if err != nil {
+		return nil, err
+	}
+
+	if shardConfiguration.backend != nil {
+		return nil, fmt.Errorf("a backend may not be set for a sharded stack")
+	}
+
+	shards := make([]*Stack, numberOfShards)
+	for i := range shards {
+		shard, err := New(options...)
+		if err != nil {
+			return nil, err
+		}
+
+		shards[i] = shard
+	}
+
+	return &ShardedStack{
+		shards:                   shards,
+		indexOfNextShardForPush:  0,
+		shardsDiscardAtMaxDepths: shardConfiguration.discardsOldest,
+	}, nil
+}
+
+// Push pushes a value onto one of the shards.  If that shard is full, the other shards
+// are tried in turn.  Push returns true if every shard was full, in which case the value
+// was not pushed, and false otherwise.  If the shards are discarding stacks, a Push
+// always succeeds and returns false, though it may discard an element from the bottom
+// of a shard.
+func (sharded *ShardedStack) Push(value interface{}) (cannotPushBecauseStackIsFull bool) {
+	startingShard := sharded.shardIndexOf(atomic.AddUint64(&sharded.indexOfNextShardForPush, 1) - 1)
+
+	for i := range sharded.shards {
+		shard := sharded.shards[(startingShard+i)%len(sharded.shards)]
+		if shardWasFull := shard.Push(value); !shardWasFull || sharded.shardsDiscardAtMaxDepths {
+			return false
+		}
+	}
+
+	sharded.retreatPushIndex()
+
+	return true
+}
+
+// Pop removes a value from the top of the shard that received the most recent Push()
+// and returns it.  If that shard is empty, a value is stolen from the top of another
+// shard.  If every shard was empty, Pop returns an undefined value and true.  Otherwise,
+// it returns the popped value and false.
+func (sharded *ShardedStack) Pop() (value interface{}, stackWasEmptyBeforePop bool) {
+	startingShard := sharded.shardIndexOf(sharded.retreatPushIndex())
+
+	for i := range sharded.shards {
+		shard := sharded.shards[(startingShard+len(sharded.shards)-i)%len(sharded.shards)]
+		if value, shardWasEmpty := shard.Pop(); !shardWasEmpty {
+			return value, false
+		}
+	}
+
+	return nil, true
+}
+
+// Depth returns the total number of values currently on all shards.  Because each shard
+// is counted separately, the result may not reflect concurrent operations precisely.
+func (sharded *ShardedStack) Depth() uint {
+	depth := uint(0)
+	for _, shard := range sharded.shards {
+		depth += shard.Depth()
+	}
+
+	return depth
+}
+
+// IsEmpty returns true if every shard is empty, or false otherwise.
+func (sharded *ShardedStack) IsEmpty() bool {
+	for _, shard := range sharded.shards {
+		if !shard.IsEmpty() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ResetToEmpty silently discards all elements on all shards.  Elements pushed
+// concurrently with the reset may survive it.
+func (sharded *ShardedStack) ResetToEmpty() {
+	for _, shard := range sharded.shards {
+		shard.ResetToEmpty()
+	}
+
+	atomic.StoreUint64(&sharded.indexOfNextShardForPush, 0)
+}
+
+// NumberOfShards returns the number of shards.
+func (sharded *ShardedStack) NumberOfShards() uint {
+	return uint(len(sharded.shards))
+}
+
+func (sharded *ShardedStack) shardIndexOf(pushIndex uint64) int {
+	return int(pushIndex % uint64(len(sharded.shards)))
+}
+
+// retreatPushIndex moves the push index back by one, unless it is already zero, and
+// returns the index of the shard that received the most recent Push().
+func (sharded *ShardedStack) retreatPushIndex() uint64 {
+	for {
+		index := atomic.LoadUint64(&sharded.indexOfNextShardForPush)
+		if index == 0 {
+			return uint64(len(sharded.shards)) - 1
+		}
+
+		if atomic.CompareAndSwapUint64(&sharded.indexOfNextShardForPush, index, index-1) {
+			return index - 1
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package stack_test
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	. "github.com/onsi/gomega"
+)
+
+func TestShardedStackIsLIFOForASingleGoroutine(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s, err := stack.NewShardedStack(3)
+	g.Expect(err).To(BeNil())
+
+	for i := 0; i < 10; i++ {
+		g.Expect(s.Push(i)).To(BeFalse())
+	}
+
+	for i := 9; i >= 5; i-- {
+		value, stackWasEmpty := s.Pop()
+		g.Expect(stackWasEmpty).To(BeFalse())
+		g.Expect(value).To(Equal(i))
+	}
+
+	g.Expect(s.Push(100)).To(BeFalse())
+	g.Expect(s.Depth()).To(Equal(uint(6)))
+
+	for _, expectedValue := range []int{100, 4, 3, 2, 1, 0} {
+		value, stackWasEmpty := s.Pop()
+		g.Expect(stackWasEmpty).To(BeFalse())
+		g.Expect(value).To(Equal(expectedValue))
+	}
+
+	_, stackWasEmpty := s.Pop()
+	g.Expect(stackWasEmpty).To(BeTrue())
+	g.Expect(s.IsEmpty()).To(BeTrue())
+}
+
+func TestShardedStackWithMaximumDepths(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s, err := stack.NewShardedStack(2, stack.WithMaxDepth(2))
+	g.Expect(err).To(BeNil())
+
+	for i := 0; i < 4; i++ {
+		g.Expect(s.Push(i)).To(BeFalse())
+	}
+	g.Expect(s.Push(4)).To(BeTrue())
+	g.Expect(s.Depth()).To(Equal(uint(4)))
+
+	s.ResetToEmpty()
+	g.Expect(s.IsEmpty()).To(BeTrue())
+
+	discarding, err := stack.NewShardedStack(2, stack.WithMaxDepth(1), stack.WithDiscardOldest())
+	g.Expect(err).To(BeNil())
+
+	for i := 0; i < 4; i++ {
+		g.Expect(discarding.Push(i)).To(BeFalse())
+	}
+	g.Expect(discarding.Depth()).To(Equal(uint(2)))
+
+	_, err = stack.NewShardedStack(0)
+	g.Expect(err).ToNot(BeNil())
+
+	_, err = stack.NewShardedStack(2, stack.WithBackend(stack.NewRingBackend(1)))
+	g.Expect(err).ToNot(BeNil())
+
+	_, err = stack.NewShardedStack(2, stack.WithDiscardOldest())
+	g.Expect(err).ToNot(BeNil())
+}
+
+func TestShardedStackConcurrentPushAndPop(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s, err := stack.NewShardedStack(4)
+	g.Expect(err).To(BeNil())
+
+	const numberOfGoroutines, valuesPerGoroutine = 8, 500
+
+	var wg sync.WaitGroup
+	poppedValues := make(chan interface{}, numberOfGoroutines*valuesPerGoroutine)
+	for i := 0; i < numberOfGoroutines; i++ {
+		wg.Add(1)
+		go func(goroutineNumber int) {
+			defer wg.Done()
+			for j := 0; j < valuesPerGoroutine; j++ {
+				s.Push(goroutineNumber*valuesPerGoroutine + j)
+				if j%2 == 1 {
+					if value, stackWasEmpty := s.Pop(); !stackWasEmpty {
+						poppedValues <- value
+					}
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for !s.IsEmpty() {
+		value, _ := s.Pop()
+		poppedValues <- value
+	}
+	close(poppedValues)
+
+	seen := make(map[int]bool)
+	for value := range poppedValues {
+		g.Expect(seen[value.(int)]).To(BeFalse())
+		seen[value.(int)] = true
+	}
+	g.Expect(seen).To(HaveLen(numberOfGoroutines * valuesPerGoroutine))
+}
+
+type pushPopper interface {
+	Push(value interface{}) bool
+	Pop() (interface{}, bool)
+}
+
+func benchmarkParallelPushPop(b *testing.B, newStack func() pushPopper) {
+	for _, procs := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("GOMAXPROCS=%d", procs), func(b *testing.B) {
+			defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+
+			s := newStack()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					s.Push(1)
+					s.Pop()
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkStackParallelPushPop(b *testing.B) {
+	benchmarkParallelPushPop(b, func() pushPopper {
+		return stack.NewStack()
+	})
+}
+
+func BenchmarkShardedStackParallelPushPop(b *testing.B) {
+	benchmarkParallelPushPop(b, func() pushPopper {
+		s, _ := stack.NewShardedStack(uint(runtime.GOMAXPROCS(0)))
+		return s
+	})
+}
@@ -0,0 +1,325 @@
+//go:build unix
+
+package stack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// SharedStackLayout describes the file used by a SharedStack.
+type SharedStackLayout struct {
+	// MaximumDepth is the number of element slots in the file, which is the maximum
+	// number of elements allowed in the stack.  It must be at least 1.
+	MaximumDepth uint
+
+	// MaximumElementSize is the size of each slot, which is the maximum length in bytes
+	// of an element.  It must be at least 1.
+	MaximumElementSize uint
+
+	// DiscardsOldest makes the stack a discarding stack, which behaves as a stack
+	// created by NewBoundedDiscardingStack() does.  Otherwise, it behaves as a stack
+	// with a maximum depth set by WithAMaximumDepthOf().
+	DiscardsOldest bool
+}
+
+// SharedStack is a bounded stack of byte slices that is stored in a memory-mapped file,
+// so that processes on the same host can share it by opening the same file.  The file
+// has a fixed number of fixed-size slots, arranged as a ring so that a discarding stack
+// can evict its bottom element without moving the others.  Every operation holds an
+// exclusive flock() on the file, so operations are atomic across processes as well as
+// across goroutines.
+//
+// Elements are copied into and out of the file, so a slice passed to Push() may be
+// reused, and a slice returned by Pop() or Peek() belongs to the caller.
+type SharedStack struct {
+	file          *os.File
+	mappedFile    []byte
+	layout        SharedStackLayout
+	lockInProcess sync.Mutex
+}
+
+// The file begins with a header, which is followed by the slots.  Each slot has the
+// length of the element it contains followed by the bytes of the element.  All integers
+// are little-endian.
+const (
+	sharedStackMagic                   = "STKSHM01"
+	sharedStackHeaderSize              = 64
+	offsetOfMaximumDepthInHeader       = 8
+	offsetOfMaximumElementSize         = 16
+	offsetOfDiscardsOldestInHeader     = 24
+	offsetOfIndexOfBottomInHeader      = 32
+	offsetOfDepthInHeader              = 40
+	sizeOfElementLengthInSlot          = 4
+	largestSupportedMaximumElementSize = 1<<32 - 1
+)
+
+// OpenSharedStack opens the shared stack in the named file, creating the file with the
+// provided layout if it does not exist or is empty.  If the file already contains a
+// shared stack, its layout must match the provided layout, and the stack retains its
+// current contents.  Each process that shares the stack should open the file itself.
+func OpenSharedStack(pathOfFile string, layout SharedStackLayout) (*SharedStack, error) {
+	if layout.MaximumDepth < 1 {
+		return nil, fmt.Errorf("stack size must be at least 1")
+	}
+
+	if layout.MaximumElementSize < 1 || layout.MaximumElementSize > largestSupportedMaximumElementSize {
+		return nil, fmt.Errorf("maximum element size must be between 1 and %d", uint64(largestSupportedMaximumElementSize))
+	}
+
+	file, err := os.OpenFile(pathOfFile, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	stack := &SharedStack{file: file, layout: layout}
+
+	if err := stack.mapFile(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return stack, nil
+}
+
+func (stack *SharedStack) mapFile() error {
+	if err := lockFile(stack.file); err != nil {
+		return err
+	}
+	defer unlockFile(stack.file)
+
+	fileSize := sharedStackHeaderSize + int64(stack.layout.MaximumDepth)*int64(sizeOfElementLengthInSlot+stack.layout.MaximumElementSize)
+
+	info, err := stack.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	fileIsNew := info.Size() == 0
+	if fileIsNew {
+		if err := stack.file.Truncate(fileSize); err != nil {
+			return err
+		}
+	} else if info.Size() != fileSize {
+		return fmt.Errorf("file (%s) is %d bytes, but the layout requires %d", stack.file.Name(), info.Size(), fileSize)
+	}
+
+	stack.mappedFile, err = syscall.Mmap(int(stack.file.Fd()), 0, int(fileSize), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return err
+	}
+
+	if fileIsNew {
+		stack.writeHeader()
+		return nil
+	}
+
+	if err := stack.validateHeader(); err != nil {
+		syscall.Munmap(stack.mappedFile)
+		return err
+	}
+
+	return nil
+}
+
+func (stack *SharedStack) writeHeader() {
+	copy(stack.mappedFile, sharedStackMagic)
+	binary.LittleEndian.PutUint64(stack.mappedFile[offsetOfMaximumDepthInHeader:], uint64(stack.layout.MaximumDepth))
+	binary.LittleEndian.PutUint64(stack.mappedFile[offsetOfMaximumElementSize:], uint64(stack.layout.MaximumElementSize))
+	if stack.layout.DiscardsOldest {
+		stack.mappedFile[offsetOfDiscardsOldestInHeader] = 1
+	}
+}
+
+func (stack *SharedStack) validateHeader() error {
+	if !bytes.Equal(stack.mappedFile[:len(sharedStackMagic)], []byte(sharedStackMagic)) {
+		return fmt.Errorf("file (%s) does not contain a shared stack", stack.file.Name())
+	}
+
+	layoutInFile := SharedStackLayout{
+		MaximumDepth:       uint(binary.LittleEndian.Uint64(stack.mappedFile[offsetOfMaximumDepthInHeader:])),
+		MaximumElementSize: uint(binary.LittleEndian.Uint64(stack.mappedFile[offsetOfMaximumElementSize:])),
+		DiscardsOldest:     stack.mappedFile[offsetOfDiscardsOldestInHeader] == 1,
+	}
+
+	if layoutInFile != stack.layout {
+		return fmt.Errorf("file (%s) has layout %+v, not %+v", stack.file.Name(), layoutInFile, stack.layout)
+	}
+
+	return nil
+}
+
+// Close unmaps and closes the file.  The file itself, and the stack in it, remain for
+// other processes.  The SharedStack must not be used after Close() is called.
+func (stack *SharedStack) Close() error {
+	stack.lockInProcess.Lock()
+	defer stack.lockInProcess.Unlock()
+
+	err := syscall.Munmap(stack.mappedFile)
+	stack.mappedFile = nil
+
+	if closeErr := stack.file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// Layout returns the layout of the stack file.
+func (stack *SharedStack) Layout() SharedStackLayout {
+	return stack.layout
+}
+
+// Push pushes a copy of the value to the top of the stack.  If this is a discarding stack
+// and the stack is full after the push, the element at the bottom is discarded, and true
+// is returned.  Otherwise, if the stack is already full, the value is not pushed, and true
+// is returned.  In any other case, false is returned.  If the value is longer than the
+// maximum element size, Push will panic.
+func (stack *SharedStack) Push(value []byte) (cannotPushBecauseStackIsFull bool) {
+	if uint(len(value)) > stack.layout.MaximumElementSize {
+		panic(fmt.Sprintf("element of %d bytes exceeds the maximum element size of %d", len(value), stack.layout.MaximumElementSize))
+	}
+
+	release := stack.lock()
+	defer release()
+
+	indexOfBottom, depth := stack.indexOfBottom(), stack.depth()
+
+	if depth == stack.layout.MaximumDepth {
+		if !stack.layout.DiscardsOldest {
+			return true
+		}
+
+		indexOfBottom = (indexOfBottom + 1) % stack.layout.MaximumDepth
+		depth--
+		stack.setIndexOfBottom(indexOfBottom)
+	}
+
+	slot := stack.slotAt((indexOfBottom + depth) % stack.layout.MaximumDepth)
+	binary.LittleEndian.PutUint32(slot, uint32(len(value)))
+	copy(slot[sizeOfElementLengthInSlot:], value)
+
+	stack.setDepth(depth + 1)
+
+	return stack.layout.DiscardsOldest && depth+1 == stack.layout.MaximumDepth
+}
+
+// Pop removes the element at the top of the stack and returns a copy of it.  If the stack
+// is empty, Pop returns nil and true.  Otherwise, it returns the element and false.
+func (stack *SharedStack) Pop() (value []byte, stackWasEmptyBeforePop bool) {
+	release := stack.lock()
+	defer release()
+
+	value, stackWasEmptyBeforePop = stack.top()
+	if !stackWasEmptyBeforePop {
+		stack.setDepth(stack.depth() - 1)
+	}
+
+	return value, stackWasEmptyBeforePop
+}
+
+// Peek returns a copy of the element at the top of the stack without removing it.  If
+// the stack is empty, Peek returns nil and true.  Otherwise, it returns the element and
+// false.
+func (stack *SharedStack) Peek() (value []byte, stackIsEmpty bool) {
+	release := stack.lock()
+	defer release()
+
+	return stack.top()
+}
+
+// Depth returns the number of elements on the stack.
+func (stack *SharedStack) Depth() uint {
+	release := stack.lock()
+	defer release()
+
+	return stack.depth()
+}
+
+// IsEmpty returns true if the stack is empty (i.e., the depth is 0), or false otherwise.
+func (stack *SharedStack) IsEmpty() bool {
+	return stack.Depth() == 0
+}
+
+// ResetToEmpty silently discards all elements on the stack.
+func (stack *SharedStack) ResetToEmpty() {
+	release := stack.lock()
+	defer release()
+
+	stack.setIndexOfBottom(0)
+	stack.setDepth(0)
+}
+
+func (stack *SharedStack) top() (value []byte, stackIsEmpty bool) {
+	depth := stack.depth()
+	if depth == 0 {
+		return nil, true
+	}
+
+	slot := stack.slotAt((stack.indexOfBottom() + depth - 1) % stack.layout.MaximumDepth)
+	length := binary.LittleEndian.Uint32(slot)
+
+	value = make([]byte, length)
+	copy(value, slot[sizeOfElementLengthInSlot:])
+
+	return value, false
+}
+
+func (stack *SharedStack) slotAt(index uint) []byte {
+	sizeOfSlot := sizeOfElementLengthInSlot + stack.layout.MaximumElementSize
+	offsetOfSlot := sharedStackHeaderSize + index*sizeOfSlot
+
+	return stack.mappedFile[offsetOfSlot : offsetOfSlot+sizeOfSlot]
+}
+
+func (stack *SharedStack) indexOfBottom() uint {
+	return uint(binary.LittleEndian.Uint64(stack.mappedFile[offsetOfIndexOfBottomInHeader:]))
+}
+
+func (stack *SharedStack) setIndexOfBottom(index uint) {
+	binary.LittleEndian.PutUint64(stack.mappedFile[offsetOfIndexOfBottomInHeader:], uint64(index))
+}
+
+func (stack *SharedStack) depth() uint {
+	return uint(binary.LittleEndian.Uint64(stack.mappedFile[offsetOfDepthInHeader:]))
+}
+
+func (stack *SharedStack) setDepth(depth uint) {
+	binary.LittleEndian.PutUint64(stack.mappedFile[offsetOfDepthInHeader:], uint64(depth))
+}
+
+// lock excludes other goroutines and other processes from the stack until the returned
+// release function is called.  A flock() is held by an open file, not by a goroutine,
+// so goroutines in this process are excluded by a mutex.  If the file cannot be locked,
+// lock panics.
+func (stack *SharedStack) lock() (release func()) {
+	stack.lockInProcess.Lock()
+
+	if err := lockFile(stack.file); err != nil {
+		stack.lockInProcess.Unlock()
+		panic(fmt.Sprintf("unable to lock shared stack file: %s", err))
+	}
+
+	return func() {
+		unlockFile(stack.file)
+		stack.lockInProcess.Unlock()
+	}
+}
+
+func lockFile(file *os.File) error {
+	for {
+		err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX)
+		if !errors.Is(err, syscall.EINTR) {
+			return err
+		}
+	}
+}
+
+func unlockFile(file *os.File) {
+	syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,177 @@
+//go:build unix
+
+package stack_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	. "github.com/onsi/gomega"
+)
+
+func TestSharedStack(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	pathOfFile := filepath.Join(t.TempDir(), "stack")
+	layout := stack.SharedStackLayout{MaximumDepth: 3, MaximumElementSize: 8}
+
+	s, err := stack.OpenSharedStack(pathOfFile, layout)
+	g.Expect(err).To(BeNil())
+	defer s.Close()
+
+	g.Expect(s.IsEmpty()).To(BeTrue())
+	_, stackWasEmpty := s.Pop()
+	g.Expect(stackWasEmpty).To(BeTrue())
+
+	element := []byte("one")
+	g.Expect(s.Push(element)).To(BeFalse())
+	element[0] = 'O'
+	g.Expect(s.Push([]byte("two"))).To(BeFalse())
+	g.Expect(s.Push([]byte{})).To(BeFalse())
+	g.Expect(s.Push([]byte("four"))).To(BeTrue())
+	g.Expect(s.Depth()).To(Equal(uint(3)))
+
+	value, _ := s.Pop()
+	g.Expect(value).To(Equal([]byte{}))
+
+	other, err := stack.OpenSharedStack(pathOfFile, layout)
+	g.Expect(err).To(BeNil())
+	g.Expect(other.Depth()).To(Equal(uint(2)))
+
+	value, stackIsEmpty := other.Peek()
+	g.Expect(stackIsEmpty).To(BeFalse())
+	g.Expect(value).To(Equal([]byte("two")))
+	g.Expect(other.Close()).To(Succeed())
+
+	value, _ = s.Pop()
+	g.Expect(value).To(Equal([]byte("two")))
+	value, _ = s.Pop()
+	g.Expect(value).To(Equal([]byte("one")))
+
+	g.Expect(testForPanic(func() { s.Push([]byte("ninebytes")) })).To(BeTrue())
+
+	s.Push([]byte("x"))
+	s.ResetToEmpty()
+	g.Expect(s.IsEmpty()).To(BeTrue())
+}
+
+func TestSharedDiscardingStack(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s, err := stack.OpenSharedStack(filepath.Join(t.TempDir(), "stack"), stack.SharedStackLayout{MaximumDepth: 3, MaximumElementSize: 2, DiscardsOldest: true})
+	g.Expect(err).To(BeNil())
+	defer s.Close()
+
+	for _, testCase := range []struct {
+		valueToPush           string
+		expectedFullAfterPush bool
+	}{
+		{"1", false},
+		{"2", false},
+		{"3", true},
+		{"4", true},
+		{"5", true},
+	} {
+		g.Expect(s.Push([]byte(testCase.valueToPush))).To(Equal(testCase.expectedFullAfterPush))
+	}
+
+	for _, expectedValue := range []string{"5", "4", "3"} {
+		value, _ := s.Pop()
+		g.Expect(string(value)).To(Equal(expectedValue))
+	}
+
+	g.Expect(s.IsEmpty()).To(BeTrue())
+}
+
+func TestOpenSharedStackErrors(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	directory := t.TempDir()
+	pathOfFile := filepath.Join(directory, "stack")
+
+	_, err := stack.OpenSharedStack(pathOfFile, stack.SharedStackLayout{MaximumDepth: 0, MaximumElementSize: 1})
+	g.Expect(err).ToNot(BeNil())
+
+	_, err = stack.OpenSharedStack(pathOfFile, stack.SharedStackLayout{MaximumDepth: 1, MaximumElementSize: 0})
+	g.Expect(err).ToNot(BeNil())
+
+	s, _ := stack.OpenSharedStack(pathOfFile, stack.SharedStackLayout{MaximumDepth: 2, MaximumElementSize: 4})
+	s.Close()
+
+	_, err = stack.OpenSharedStack(pathOfFile, stack.SharedStackLayout{MaximumDepth: 4, MaximumElementSize: 1})
+	g.Expect(err).ToNot(BeNil())
+
+	_, err = stack.OpenSharedStack(pathOfFile, stack.SharedStackLayout{MaximumDepth: 2, MaximumElementSize: 4, DiscardsOldest: true})
+	g.Expect(err).ToNot(BeNil())
+
+	pathOfOtherFile := filepath.Join(directory, "other")
+	os.WriteFile(pathOfOtherFile, make([]byte, 64+2*(4+4)), 0o600)
+	_, err = stack.OpenSharedStack(pathOfOtherFile, stack.SharedStackLayout{MaximumDepth: 2, MaximumElementSize: 4})
+	g.Expect(err).ToNot(BeNil())
+}
+
+// TestSharedStackAcrossProcesses runs this test binary as several worker processes,
+// each of which pushes to the same shared stack.
+func TestSharedStackAcrossProcesses(t *testing.T) {
+	if pathOfFile := os.Getenv("SHARED_STACK_WORKER_FILE"); pathOfFile != "" {
+		runSharedStackWorker(pathOfFile, os.Getenv("SHARED_STACK_WORKER_ID"))
+		return
+	}
+
+	g := NewGomegaWithT(t)
+
+	const numberOfWorkers, pushesPerWorker = 4, 200
+	pathOfFile := filepath.Join(t.TempDir(), "stack")
+
+	workers := make([]*exec.Cmd, numberOfWorkers)
+	for i := range workers {
+		workers[i] = exec.Command(os.Args[0], "-test.run=^TestSharedStackAcrossProcesses$")
+		workers[i].Env = append(os.Environ(), "SHARED_STACK_WORKER_FILE="+pathOfFile, "SHARED_STACK_WORKER_ID="+strconv.Itoa(i))
+		g.Expect(workers[i].Start()).To(Succeed())
+	}
+
+	for _, worker := range workers {
+		g.Expect(worker.Wait()).To(Succeed())
+	}
+
+	s, err := stack.OpenSharedStack(pathOfFile, sharedStackWorkerLayout)
+	g.Expect(err).To(BeNil())
+	defer s.Close()
+
+	g.Expect(s.Depth()).To(Equal(uint(numberOfWorkers * pushesPerWorker)))
+
+	nextExpectedValueFromWorker := make(map[byte]int)
+	for i := 0; i < numberOfWorkers; i++ {
+		nextExpectedValueFromWorker[byte('0'+i)] = pushesPerWorker - 1
+	}
+
+	for !s.IsEmpty() {
+		value, _ := s.Pop()
+		worker, counter := value[0], value[2:]
+		g.Expect(string(counter)).To(Equal(fmt.Sprintf("%03d", nextExpectedValueFromWorker[worker])))
+		nextExpectedValueFromWorker[worker]--
+	}
+}
+
+var sharedStackWorkerLayout = stack.SharedStackLayout{MaximumDepth: 1000, MaximumElementSize: 8}
+
+func runSharedStackWorker(pathOfFile string, workerID string) {
+	s, err := stack.OpenSharedStack(pathOfFile, sharedStackWorkerLayout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer s.Close()
+
+	for i := 0; i < 200; i++ {
+		if s.Push([]byte(fmt.Sprintf("%s:%03d", workerID, i))) {
+			fmt.Fprintln(os.Stderr, "stack unexpectedly full")
+			os.Exit(1)
+		}
+	}
+}
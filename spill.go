@@ -0,0 +1,426 @@
+package stack
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Codec converts a segment of stack elements to and from the bytes of a file, for a
+// SpillingBackend.
+type Codec interface {
+	// EncodeSegment writes the values, which are ordered from the bottom of the stack
+	// to the top, to the writer.
+	EncodeSegment(writer io.Writer, values []interface{}) error
+
+	// DecodeSegment reads values written by EncodeSegment from the reader, returning
+	// them in the same order.
+	DecodeSegment(reader io.Reader) ([]interface{}, error)
+}
+
+// GobCodec returns a Codec that uses encoding/gob.  Each value is encoded as an
+// interface value, so the concrete type of any value that is not a built-in type must
+// be registered with gob.Register().
+func GobCodec() Codec {
+	return gobCodec{}
+}
+
+type gobCodec struct{}
+
+func (gobCodec) EncodeSegment(writer io.Writer, values []interface{}) error {
+	encoder := gob.NewEncoder(writer)
+
+	if err := encoder.Encode(len(values)); err != nil {
+		return err
+	}
+
+	for i := range values {
+		if err := encoder.Encode(&values[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (gobCodec) DecodeSegment(reader io.Reader) ([]interface{}, error) {
+	decoder := gob.NewDecoder(reader)
+
+	var numberOfValues int
+	if err := decoder.Decode(&numberOfValues); err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, numberOfValues)
+	for i := range values {
+		if err := decoder.Decode(&values[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// SpillConfiguration describes how a SpillingBackend uses memory and disk.
+type SpillConfiguration struct {
+	// ElementsInMemory is the number of elements at the top of the stack that are kept in
+	// memory.  It must be at least 2.
+	ElementsInMemory uint
+
+	// ElementsPerSegment is the number of elements written to each file when elements
+	// are spilled.  It may not exceed ElementsInMemory.  If it is 0, half of
+	// ElementsInMemory is used, so that alternating pushes and pops near a segment
+	// boundary do not repeatedly write and read the same elements.
+	ElementsPerSegment uint
+
+	// Directory is where segment files are created.  If it is empty, the default
+	// directory for temporary files is used.
+	Directory string
+
+	// Codec converts segments to and from file contents.  If it is nil, GobCodec()
+	// is used.
+	Codec Codec
+}
+
+// SpillingBackend is a Backend for stacks that are too deep to keep in memory.  It keeps
+// the elements at the top of the stack in memory, and when there are more than
+// SpillConfiguration.ElementsInMemory of them, it writes the oldest to a temporary file
+// as a segment of SpillConfiguration.ElementsPerSegment elements.  When pops empty the
+// memory, the most recently written segment is read back and its file is removed.  This
+// is transparent to the Stack, which is created with WithBackend().
+//
+// Elements evicted from the bottom of a discarding stack come from the oldest segment,
+// which is read into memory for the purpose, so memory holds up to one segment more
+// than ElementsInMemory.  Peek() and other operations that examine an element that has
+// been spilled read the file containing it, and the most recently read segment is cached.
+//
+// If a segment cannot be written, it is kept in memory instead, and the error is
+// reported by Err().  If a segment cannot be read back, the elements in it are lost, so
+// the stack operation that needed them panics.  The panic is raised in the goroutine
+// that called the operation, as Stack raises any panic from its Backend, so it can be
+// recovered there.
+//
+// Segment files are removed as they are read back and when the stack is reset, but the
+// files for any remaining elements persist until Close() is called.  A stack created by
+// Clone() has its own copy of the backend, which cannot be closed, so its files persist
+// until it is reset or emptied.
+type SpillingBackend struct {
+	configuration SpillConfiguration
+	topElements   Backend
+	// segments are ordered from the bottom of the stack to the top.
+	segments                    []*spilledSegment
+	numberOfElementsInSegments  uint
+	segmentWhoseValuesAreCached *spilledSegment
+	cachedValuesOfSegment       []interface{}
+	firstErrorWritingSegment    error
+}
+
+// spilledSegment is either in a file, in which case pathOfFile is set, or in memory, in
+// which case values is set.  Values are ordered from the bottom of the stack to the top.
+type spilledSegment struct {
+	pathOfFile       string
+	numberOfElements uint
+	values           []interface{}
+}
+
+// NewSpillingBackend returns an empty SpillingBackend.  An error is returned if the
+// configuration is invalid or if the directory cannot be used.
+func NewSpillingBackend(configuration SpillConfiguration) (*SpillingBackend, error) {
+	if configuration.ElementsInMemory < 2 {
+		return nil, fmt.Errorf("elements in memory must be at least 2")
+	}
+
+	if configuration.ElementsPerSegment == 0 {
+		configuration.ElementsPerSegment = configuration.ElementsInMemory / 2
+	} else if configuration.ElementsPerSegment > configuration.ElementsInMemory {
+		return nil, fmt.Errorf("elements per segment (%d) may not exceed elements in memory (%d)", configuration.ElementsPerSegment, configuration.ElementsInMemory)
+	}
+
+	if configuration.Directory == "" {
+		configuration.Directory = os.TempDir()
+	}
+
+	if info, err := os.Stat(configuration.Directory); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("(%s) is not a directory", configuration.Directory)
+	}
+
+	if configuration.Codec == nil {
+		configuration.Codec = GobCodec()
+	}
+
+	return &SpillingBackend{
+		configuration: configuration,
+		topElements:   NewRingBackend(configuration.ElementsInMemory + 1),
+	}, nil
+}
+
+// NumberOfSpilledElements returns the number of elements that are not among those at the
+// top of the stack kept in memory.  This includes elements in segments that could not be
+// written and elements of a segment read into memory for eviction from the bottom.  It
+// must not be called while the stack is in use.
+func (backend *SpillingBackend) NumberOfSpilledElements() uint {
+	return backend.numberOfElementsInSegments
+}
+
+// Err returns the first error encountered when writing a segment to a file, or nil if
+// there has been no such error.  It must not be called while the stack is in use.
+func (backend *SpillingBackend) Err() error {
+	return backend.firstErrorWritingSegment
+}
+
+// Close removes all elements, including any segment files.  It should be called once the
+// stack using the backend is no longer needed, and must not be called while the stack is
+// in use.  The error from the first file that could not be removed, if any, is returned.
+func (backend *SpillingBackend) Close() error {
+	return backend.clear()
+}
+
+func (backend *SpillingBackend) PushOnTop(value interface{}) {
+	backend.topElements.PushOnTop(value)
+
+	if backend.topElements.Depth() > backend.configuration.ElementsInMemory {
+		backend.spillOldestTopElements()
+	}
+}
+
+func (backend *SpillingBackend) PopFromTop() interface{} {
+	if backend.topElements.Depth() == 0 {
+		backend.reloadNewestSegment()
+	}
+
+	return backend.topElements.PopFromTop()
+}
+
+func (backend *SpillingBackend) RemoveFromBottom() interface{} {
+	if len(backend.segments) == 0 {
+		return backend.topElements.RemoveFromBottom()
+	}
+
+	oldestSegment := backend.segments[0]
+	backend.readIntoMemory(oldestSegment)
+
+	value := oldestSegment.values[0]
+	oldestSegment.values[0] = nil
+	oldestSegment.values = oldestSegment.values[1:]
+	oldestSegment.numberOfElements--
+	backend.numberOfElementsInSegments--
+
+	if oldestSegment.numberOfElements == 0 {
+		backend.segments = backend.segments[1:]
+	}
+
+	return value
+}
+
+func (backend *SpillingBackend) ElementAt(distanceFromTop uint) interface{} {
+	if distanceFromTop < backend.topElements.Depth() {
+		return backend.topElements.ElementAt(distanceFromTop)
+	}
+
+	distanceFromTopOfSegment := distanceFromTop - backend.topElements.Depth()
+	for i := len(backend.segments) - 1; i >= 0; i-- {
+		segment := backend.segments[i]
+		if distanceFromTopOfSegment < segment.numberOfElements {
+			return backend.valuesOf(segment)[segment.numberOfElements-1-distanceFromTopOfSegment]
+		}
+
+		distanceFromTopOfSegment -= segment.numberOfElements
+	}
+
+	panic("element requested beyond the bottom of the stack")
+}
+
+func (backend *SpillingBackend) Depth() uint {
+	return backend.topElements.Depth() + backend.numberOfElementsInSegments
+}
+
+func (backend *SpillingBackend) Clear() {
+	backend.clear()
+}
+
+// Clone returns an independent copy of the backend, which has its own copy of each
+// segment file.  If a segment file cannot be copied, its values are read into memory
+// for the copy, and the error is reported by the Err() method of the copy.
+func (backend *SpillingBackend) Clone() Backend {
+	clone := &SpillingBackend{
+		configuration:              backend.configuration,
+		topElements:                backend.topElements.Clone(),
+		segments:                   make([]*spilledSegment, len(backend.segments)),
+		numberOfElementsInSegments: backend.numberOfElementsInSegments,
+		firstErrorWritingSegment:   backend.firstErrorWritingSegment,
+	}
+
+	for i, segment := range backend.segments {
+		if segment.pathOfFile == "" {
+			clone.segments[i] = &spilledSegment{numberOfElements: segment.numberOfElements, values: append([]interface{}(nil), segment.values...)}
+			continue
+		}
+
+		pathOfCopy, err := backend.copyOfFile(segment.pathOfFile)
+		if err != nil {
+			clone.recordErrorWritingSegment(err)
+			clone.segments[i] = &spilledSegment{numberOfElements: segment.numberOfElements, values: append([]interface{}(nil), backend.valuesOf(segment)...)}
+			continue
+		}
+
+		clone.segments[i] = &spilledSegment{pathOfFile: pathOfCopy, numberOfElements: segment.numberOfElements}
+	}
+
+	return clone
+}
+
+func (backend *SpillingBackend) spillOldestTopElements() {
+	values := make([]interface{}, backend.configuration.ElementsPerSegment)
+	for i := range values {
+		values[i] = backend.topElements.RemoveFromBottom()
+	}
+
+	segment := &spilledSegment{numberOfElements: uint(len(values))}
+
+	if pathOfFile, err := backend.writeSegmentFile(values); err != nil {
+		backend.recordErrorWritingSegment(err)
+		segment.values = values
+	} else {
+		segment.pathOfFile = pathOfFile
+	}
+
+	backend.segments = append(backend.segments, segment)
+	backend.numberOfElementsInSegments += segment.numberOfElements
+}
+
+func (backend *SpillingBackend) reloadNewestSegment() {
+	newestSegment := backend.segments[len(backend.segments)-1]
+	backend.readIntoMemory(newestSegment)
+
+	for _, value := range newestSegment.values {
+		backend.topElements.PushOnTop(value)
+	}
+
+	backend.segments = backend.segments[:len(backend.segments)-1]
+	backend.numberOfElementsInSegments -= newestSegment.numberOfElements
+}
+
+// readIntoMemory sets the values of a segment that is in a file and removes the file.
+func (backend *SpillingBackend) readIntoMemory(segment *spilledSegment) {
+	if segment.pathOfFile == "" {
+		return
+	}
+
+	segment.values = backend.valuesOf(segment)
+	os.Remove(segment.pathOfFile)
+	segment.pathOfFile = ""
+
+	if backend.segmentWhoseValuesAreCached == segment {
+		backend.segmentWhoseValuesAreCached, backend.cachedValuesOfSegment = nil, nil
+	}
+}
+
+// valuesOf returns the values of a segment, reading them from its file if it is in
+// one.  The returned slice must not be modified.
+func (backend *SpillingBackend) valuesOf(segment *spilledSegment) []interface{} {
+	if segment.pathOfFile == "" {
+		return segment.values
+	}
+
+	if backend.segmentWhoseValuesAreCached != segment {
+		values, err := backend.readSegmentFile(segment.pathOfFile)
+		if err != nil {
+			panic(fmt.Sprintf("unable to read spilled stack segment: %s", err))
+		}
+
+		if uint(len(values)) != segment.numberOfElements {
+			panic(fmt.Sprintf("spilled stack segment (%s) has %d elements, but %d were written", segment.pathOfFile, len(values), segment.numberOfElements))
+		}
+
+		backend.segmentWhoseValuesAreCached, backend.cachedValuesOfSegment = segment, values
+	}
+
+	return backend.cachedValuesOfSegment
+}
+
+func (backend *SpillingBackend) writeSegmentFile(values []interface{}) (pathOfFile string, err error) {
+	file, err := os.CreateTemp(backend.configuration.Directory, "stack-segment-*")
+	if err != nil {
+		return "", err
+	}
+
+	writer := bufio.NewWriter(file)
+	err = backend.configuration.Codec.EncodeSegment(writer, values)
+	if err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+
+	return file.Name(), nil
+}
+
+func (backend *SpillingBackend) readSegmentFile(pathOfFile string) ([]interface{}, error) {
+	file, err := os.Open(pathOfFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return backend.configuration.Codec.DecodeSegment(bufio.NewReader(file))
+}
+
+func (backend *SpillingBackend) copyOfFile(pathOfFile string) (pathOfCopy string, err error) {
+	source, err := os.Open(pathOfFile)
+	if err != nil {
+		return "", err
+	}
+	defer source.Close()
+
+	fileCopy, err := os.CreateTemp(backend.configuration.Directory, "stack-segment-*")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = io.Copy(fileCopy, source)
+	if closeErr := fileCopy.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(fileCopy.Name())
+		return "", err
+	}
+
+	return fileCopy.Name(), nil
+}
+
+func (backend *SpillingBackend) recordErrorWritingSegment(err error) {
+	if backend.firstErrorWritingSegment == nil {
+		backend.firstErrorWritingSegment = err
+	}
+}
+
+func (backend *SpillingBackend) clear() error {
+	var firstErrorRemovingFile error
+
+	for _, segment := range backend.segments {
+		if segment.pathOfFile != "" {
+			if err := os.Remove(segment.pathOfFile); err != nil && firstErrorRemovingFile == nil {
+				firstErrorRemovingFile = err
+			}
+		}
+	}
+
+	backend.topElements.Clear()
+	backend.segments = nil
+	backend.numberOfElementsInSegments = 0
+	backend.segmentWhoseValuesAreCached, backend.cachedValuesOfSegment = nil, nil
+
+	return firstErrorRemovingFile
+}
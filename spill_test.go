@@ -0,0 +1,178 @@
+package stack_test
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	. "github.com/onsi/gomega"
+)
+
+func numberOfFilesIn(g *WithT, directory string) int {
+	entries, err := os.ReadDir(directory)
+	g.Expect(err).To(BeNil())
+	return len(entries)
+}
+
+func TestSpillingBackend(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	directory := t.TempDir()
+	backend, err := stack.NewSpillingBackend(stack.SpillConfiguration{ElementsInMemory: 4, ElementsPerSegment: 2, Directory: directory})
+	g.Expect(err).To(BeNil())
+	defer backend.Close()
+
+	s, err := stack.New(stack.WithBackend(backend))
+	g.Expect(err).To(BeNil())
+
+	for i := 0; i < 10; i++ {
+		s.Push(i)
+	}
+
+	g.Expect(s.Depth()).To(Equal(uint(10)))
+	g.Expect(numberOfFilesIn(g, directory)).To(Equal(3))
+
+	clone := s.Clone()
+	g.Expect(clone.Equal(s, nil)).To(BeTrue())
+	g.Expect(numberOfFilesIn(g, directory)).To(Equal(6))
+
+	for i := 9; i >= 0; i-- {
+		value, stackWasEmpty := s.Pop()
+		g.Expect(stackWasEmpty).To(BeFalse())
+		g.Expect(value).To(Equal(i))
+	}
+
+	_, stackWasEmpty := s.Pop()
+	g.Expect(stackWasEmpty).To(BeTrue())
+	g.Expect(numberOfFilesIn(g, directory)).To(Equal(3))
+
+	value, _ := clone.Peek()
+	g.Expect(value).To(Equal(9))
+
+	clone.ResetToEmpty()
+	g.Expect(numberOfFilesIn(g, directory)).To(Equal(0))
+	g.Expect(backend.Err()).To(BeNil())
+}
+
+func TestSpillingBackendWithDiscarding(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	directory := t.TempDir()
+	backend, _ := stack.NewSpillingBackend(stack.SpillConfiguration{ElementsInMemory: 4, Directory: directory})
+	s, err := stack.New(stack.WithBackend(backend), stack.WithMaxDepth(7), stack.WithDiscardOldest())
+	g.Expect(err).To(BeNil())
+
+	for i := 0; i < 20; i++ {
+		s.Push(i)
+	}
+
+	g.Expect(s.Depth()).To(Equal(uint(7)))
+	for i := 19; i >= 13; i-- {
+		value, _ := s.Pop()
+		g.Expect(value).To(Equal(i))
+	}
+
+	g.Expect(numberOfFilesIn(g, directory)).To(Equal(0))
+}
+
+func TestSpillingBackendPeekAtSpilledElement(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	backend, _ := stack.NewSpillingBackend(stack.SpillConfiguration{ElementsInMemory: 2, ElementsPerSegment: 1, Directory: t.TempDir()})
+	defer backend.Close()
+	s, _ := stack.New(stack.WithBackend(backend))
+
+	s.Push("a")
+	s.Push("b")
+	s.Push("c")
+	g.Expect(backend.NumberOfSpilledElements()).To(Equal(uint(1)))
+
+	g.Expect(s.Pick(2)).To(Succeed())
+	value, _ := s.Peek()
+	g.Expect(value).To(Equal("a"))
+}
+
+func TestSpillingBackendAgainstModel(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	backend, _ := stack.NewSpillingBackend(stack.SpillConfiguration{ElementsInMemory: 8, ElementsPerSegment: 3, Directory: t.TempDir()})
+	defer backend.Close()
+	s, _ := stack.New(stack.WithBackend(backend))
+
+	random := rand.New(rand.NewSource(40))
+	model := []interface{}{}
+
+	for i := 0; i < 3000; i++ {
+		if random.Intn(5) < 2 {
+			value, stackWasEmpty := s.Pop()
+			g.Expect(stackWasEmpty).To(Equal(len(model) == 0))
+			if len(model) > 0 {
+				g.Expect(value).To(Equal(model[len(model)-1]))
+				model = model[:len(model)-1]
+			}
+		} else {
+			s.Push(i)
+			model = append(model, i)
+		}
+
+		g.Expect(s.Depth()).To(Equal(uint(len(model))))
+	}
+}
+
+func TestNewSpillingBackendErrors(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	_, err := stack.NewSpillingBackend(stack.SpillConfiguration{ElementsInMemory: 1})
+	g.Expect(err).ToNot(BeNil())
+
+	_, err = stack.NewSpillingBackend(stack.SpillConfiguration{ElementsInMemory: 4, ElementsPerSegment: 5})
+	g.Expect(err).ToNot(BeNil())
+
+	_, err = stack.NewSpillingBackend(stack.SpillConfiguration{ElementsInMemory: 4, Directory: filepath.Join(t.TempDir(), "missing")})
+	g.Expect(err).ToNot(BeNil())
+}
+
+func TestSpillingBackendKeepsSegmentInMemoryWhenWriteFails(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	directory := t.TempDir()
+	backend, _ := stack.NewSpillingBackend(stack.SpillConfiguration{ElementsInMemory: 2, ElementsPerSegment: 1, Directory: directory})
+	s, _ := stack.New(stack.WithBackend(backend))
+
+	s.Push(func() {})
+	s.Push(1)
+	s.Push(2)
+
+	g.Expect(s.Depth()).To(Equal(uint(3)))
+	g.Expect(numberOfFilesIn(g, directory)).To(Equal(0))
+
+	s.Pop()
+	s.Pop()
+	value, _ := s.Pop()
+	g.Expect(value).To(BeAssignableToTypeOf(func() {}))
+	g.Expect(backend.Err()).ToNot(BeNil())
+}
+
+func TestSpillingBackendPanicsInCallerWhenSegmentCannotBeRead(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	directory := t.TempDir()
+	backend, _ := stack.NewSpillingBackend(stack.SpillConfiguration{ElementsInMemory: 2, ElementsPerSegment: 1, Directory: directory})
+	defer backend.Close()
+	s, _ := stack.New(stack.WithBackend(backend))
+
+	s.Push("a")
+	s.Push("b")
+	s.Push("c")
+	g.Expect(numberOfFilesIn(g, directory)).To(Equal(1))
+
+	entries, _ := os.ReadDir(directory)
+	g.Expect(os.Remove(filepath.Join(directory, entries[0].Name()))).To(Succeed())
+
+	g.Expect(func() { s.Pick(2) }).To(PanicWith(ContainSubstring("unable to read spilled stack segment")))
+
+	value, _ := s.Pop()
+	g.Expect(value).To(Equal("c"))
+}
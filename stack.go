@@ -1,14 +1,23 @@
 // Package stack implements a LIFO stack safe for concurrent operation.
 package stack
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+)
 
 // Stack represents a LIFO stack of arbitrary, untyped values.
 type Stack struct {
 	manipulator                       *stackManipulator
 	channelOfOperationsForManipulator chan<- *stackManipulationMessage
+	identifier                        uint64
+	hasBeenClosed                     uint32
 }
 
+var lastAssignedStackIdentifier uint64
+
 // NewStack returns an empty stack.
 func NewStack() *Stack {
 	return NewStackWithInitialSizeHint(100)
@@ -17,31 +26,39 @@ func NewStack() *Stack {
 // NewStackWithInitialSizeHint returns an empty stack using a backing store with the specified
 // number of elements.
 func NewStackWithInitialSizeHint(initialElementStorageSize uint) *Stack {
-	m := newStackManipulator(initialElementStorageSize)
-	go m.Start()
-
-	return &Stack{
-		manipulator:                       m,
-		channelOfOperationsForManipulator: m.requestChannel(),
-	}
+	return mustNew(WithInitialCapacity(initialElementStorageSize))
 }
 
 // NewBoundedDiscardingStack returns an unbounded, discarding stack which can contain
 // no more than the specified number of elements.  When the stack contains that number
 // of elements, a Push() will succeed, but the element at the bottom of the stack will
-// be discarded and all stack elements will move down one slot.
+// be discarded and all stack elements will move down one slot.  This will panic if
+// the maximum number of elements is zero.
 func NewBoundedDiscardingStack(maximumNumberOfAllowedElements uint) *Stack {
 	initialSizeHint := uint(100)
 	if maximumNumberOfAllowedElements < 100 {
 		initialSizeHint = maximumNumberOfAllowedElements
 	}
 
-	m := newStackManipulator(initialSizeHint).whichDiscardsAtSize(maximumNumberOfAllowedElements)
+	return mustNew(WithInitialCapacity(initialSizeHint), WithMaxDepth(maximumNumberOfAllowedElements), WithDiscardOldest())
+}
+
+func mustNew(options ...Option) *Stack {
+	stack, err := New(options...)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return stack
+}
+
+func newStackUsingManipulator(m *stackManipulator) *Stack {
 	go m.Start()
 
 	return &Stack{
 		manipulator:                       m,
 		channelOfOperationsForManipulator: m.requestChannel(),
+		identifier:                        atomic.AddUint64(&lastAssignedStackIdentifier, 1),
 	}
 }
 
@@ -57,13 +74,13 @@ func (stack *Stack) WithAMaximumDepthOf(maximumNumberOfAllowedElements uint) *St
 	}
 
 	responseChannel := make(chan *stackManipulationResponse)
-	stack.channelOfOperationsForManipulator <- &stackManipulationMessage{
+	stack.send(&stackManipulationMessage{
 		operation:       setMaximumDepth,
 		depth:           maximumNumberOfAllowedElements,
 		responseChannel: responseChannel,
-	}
+	})
 
-	response := <-responseChannel
+	response := stack.receive(responseChannel)
 
 	if response.operationError != nil {
 		panic(response.operationError.Error())
@@ -83,6 +100,26 @@ func (stack *Stack) SetMaximumDepthTo(maximumNumberOfAllowedElements uint) *Stac
 	return stack.WithAMaximumDepthOf(maximumNumberOfAllowedElements)
 }
 
+// RemoveMaximumDepth lifts any maximum depth previously set with WithAMaximumDepthOf()
+// or SetMaximumDepthTo(), so that the stack is once again unbounded.  Elements already
+// on the stack are retained.  A maximum depth may be applied again later.  This will
+// panic if it is called on a discarding stack, which cannot be unbounded.
+func (stack *Stack) RemoveMaximumDepth() *Stack {
+	if stack.manipulator.discardsFIFOAfterMaxSize {
+		panic("You may not remove the maximum stack depth from a discarding stack")
+	}
+
+	responseChannel := make(chan *stackManipulationResponse)
+	stack.send(&stackManipulationMessage{
+		operation:       removeMaximumDepth,
+		responseChannel: responseChannel,
+	})
+
+	stack.receive(responseChannel)
+
+	return stack
+}
+
 // Push pushes a value to the top of the stack.  If this is a standard stack that
 // has no maximum depth, it will succeed and return false, meaning the stack was
 // not full before the Push (because the stack cannot be full).  If this is a standard
@@ -94,13 +131,13 @@ func (stack *Stack) SetMaximumDepthTo(maximumNumberOfAllowedElements uint) *Stac
 // the discarding stack isn't full, the value will be added and false will be returned.
 func (stack *Stack) Push(value interface{}) (cannotPushBecauseStackIsFull bool) {
 	responseChannel := make(chan *stackManipulationResponse)
-	stack.channelOfOperationsForManipulator <- &stackManipulationMessage{
+	stack.send(&stackManipulationMessage{
 		operation:       push,
 		valueToPush:     value,
 		responseChannel: responseChannel,
-	}
+	})
 
-	response := <-responseChannel
+	response := stack.receive(responseChannel)
 
 	return response.stackIsEmptyOrFullBeforeOperation
 }
@@ -110,12 +147,27 @@ func (stack *Stack) Push(value interface{}) (cannotPushBecauseStackIsFull bool)
 // was not empty before the operation, it will return the popped value and false.
 func (stack *Stack) Pop() (value interface{}, stackWasEmptyBeforePop bool) {
 	responseChannel := make(chan *stackManipulationResponse)
-	stack.channelOfOperationsForManipulator <- &stackManipulationMessage{
+	stack.send(&stackManipulationMessage{
 		operation:       pop,
 		responseChannel: responseChannel,
-	}
+	})
 
-	response := <-responseChannel
+	response := stack.receive(responseChannel)
+
+	return response.poppedValueOrCurrentDepth, response.stackIsEmptyOrFullBeforeOperation
+}
+
+// Peek returns the value at the top of the stack without removing it.  If the stack
+// is empty, Peek will return an undefined value and true.  If it is not empty, it will
+// return the value at the top of the stack and false.
+func (stack *Stack) Peek() (value interface{}, stackIsEmpty bool) {
+	responseChannel := make(chan *stackManipulationResponse)
+	stack.send(&stackManipulationMessage{
+		operation:       peek,
+		responseChannel: responseChannel,
+	})
+
+	response := stack.receive(responseChannel)
 
 	return response.poppedValueOrCurrentDepth, response.stackIsEmptyOrFullBeforeOperation
 }
@@ -163,12 +215,12 @@ func (stack *Stack) PopString() (string, bool) {
 // Depth returns the number of values currently on the stack.
 func (stack *Stack) Depth() uint {
 	responseChannel := make(chan *stackManipulationResponse)
-	stack.channelOfOperationsForManipulator <- &stackManipulationMessage{
+	stack.send(&stackManipulationMessage{
 		operation:       getDepth,
 		responseChannel: responseChannel,
-	}
+	})
 
-	response := <-responseChannel
+	response := stack.receive(responseChannel)
 
 	return response.poppedValueOrCurrentDepth.(uint)
 }
@@ -176,12 +228,12 @@ func (stack *Stack) Depth() uint {
 // IsEmpty returns true if the stack is empty (i.e., the depth is 0), or false otherwise.
 func (stack *Stack) IsEmpty() bool {
 	responseChannel := make(chan *stackManipulationResponse)
-	stack.channelOfOperationsForManipulator <- &stackManipulationMessage{
+	stack.send(&stackManipulationMessage{
 		operation:       getDepth,
 		responseChannel: responseChannel,
-	}
+	})
 
-	response := <-responseChannel
+	response := stack.receive(responseChannel)
 
 	return response.poppedValueOrCurrentDepth.(uint) == 0
 }
@@ -189,54 +241,225 @@ func (stack *Stack) IsEmpty() bool {
 // ResetToEmpty silently discards all elements on the stack and sets the stack depth to 0.
 func (stack *Stack) ResetToEmpty() {
 	responseChannel := make(chan *stackManipulationResponse)
-	stack.channelOfOperationsForManipulator <- &stackManipulationMessage{
+	stack.send(&stackManipulationMessage{
 		operation:       resetToEmpty,
 		responseChannel: responseChannel,
+	})
+
+	stack.receive(responseChannel)
+}
+
+// Close stops the goroutine that performs the operations of the stack, which otherwise
+// runs for as long as the program does.  A stack that is created for a single task, such
+// as a scratch stack in a function, should be closed when the task is done.  After Close,
+// any operation on the stack panics.  Close may be called more than once, but it must not
+// be called while another goroutine may still operate on the stack.
+func (stack *Stack) Close() {
+	if !atomic.CompareAndSwapUint32(&stack.hasBeenClosed, 0, 1) {
+		return
+	}
+
+	responseChannel := make(chan *stackManipulationResponse)
+	stack.channelOfOperationsForManipulator <- &stackManipulationMessage{
+		operation:       stopManipulator,
+		responseChannel: responseChannel,
 	}
 
 	<-responseChannel
 }
 
+// MaximumDepth returns the maximum number of elements allowed in the stack, or 0 if the
+// stack has no maximum depth.
+func (stack *Stack) MaximumDepth() uint {
+	release := stack.holdManipulator()
+	defer release()
+
+	return stack.manipulator.maximumStackDepth
+}
+
+// IsDiscarding returns true if this is a discarding stack, as created by
+// NewBoundedDiscardingStack() or with WithDiscardOldest(), or false otherwise.  A stack
+// that is discarding remains so for its lifetime.
+func (stack *Stack) IsDiscarding() bool {
+	return stack.manipulator.discardsFIFOAfterMaxSize
+}
+
+// send sends a message to the stack manipulator.  If the stack is recording its
+// operations, the message identifies the calling goroutine, and if the stack is tracing
+// its operations, the message has the time at which it was sent.
+func (stack *Stack) send(message *stackManipulationMessage) {
+	if atomic.LoadUint32(&stack.hasBeenClosed) != 0 {
+		panic("operation on a closed stack")
+	}
+
+	if stack.manipulator.recorder != nil {
+		message.callerGoroutine = stack.manipulator.recorder.identifyCaller()
+	}
+
+	if stack.manipulator.isTraced() {
+		message.timeOfSend = time.Now()
+	}
+
+	stack.channelOfOperationsForManipulator <- message
+}
+
+// receive waits for the response to a message sent to the stack manipulator.  If the
+// operation panicked, the panic is raised again in the caller.
+func (stack *Stack) receive(responseChannel <-chan *stackManipulationResponse) *stackManipulationResponse {
+	response := <-responseChannel
+	if response.recoveredPanic != nil {
+		panic(response.recoveredPanic)
+	}
+
+	return response
+}
+
+// holdManipulator blocks the stack manipulator until the returned release function is
+// called.  Until then, the caller has exclusive access to the manipulator and may operate
+// on it directly.
+func (stack *Stack) holdManipulator() (release func()) {
+	responseChannel := make(chan *stackManipulationResponse)
+	releaseChannel := make(chan struct{})
+	stack.send(&stackManipulationMessage{
+		operation:       holdForExclusiveAccess,
+		releaseChannel:  releaseChannel,
+		responseChannel: responseChannel,
+	})
+
+	<-responseChannel
+
+	return func() { close(releaseChannel) }
+}
+
+// contentsFromBottom returns the elements of the stack, from the bottom of the stack to
+// the top.
+func (stack *Stack) contentsFromBottom() []interface{} {
+	release := stack.holdManipulator()
+	defer release()
+
+	backend := stack.manipulator.backend
+	contents := make([]interface{}, backend.Depth())
+	for i := range contents {
+		contents[i] = backend.ElementAt(uint(len(contents) - 1 - i))
+	}
+
+	return contents
+}
+
+// holdManipulatorsOf holds the manipulator of each distinct stack, as holdManipulator()
+// does.  The manipulators are always held in the order of their stack identifiers, so
+// that concurrent holds of overlapping sets of stacks cannot deadlock.
+func holdManipulatorsOf(stacks ...*Stack) (release func()) {
+	distinctStacks := make([]*Stack, 0, len(stacks))
+	for _, stack := range stacks {
+		alreadyIncluded := false
+		for _, includedStack := range distinctStacks {
+			if includedStack == stack {
+				alreadyIncluded = true
+				break
+			}
+		}
+
+		if !alreadyIncluded {
+			distinctStacks = append(distinctStacks, stack)
+		}
+	}
+
+	sort.Slice(distinctStacks, func(i, j int) bool {
+		return distinctStacks[i].identifier < distinctStacks[j].identifier
+	})
+
+	releaseFunctions := make([]func(), len(distinctStacks))
+	for i, stack := range distinctStacks {
+		releaseFunctions[i] = stack.holdManipulator()
+	}
+
+	return func() {
+		for i := len(releaseFunctions) - 1; i >= 0; i-- {
+			releaseFunctions[i]()
+		}
+	}
+}
+
 type stackOperation int
 
 const (
 	push stackOperation = iota
 	pop
+	peek
 	resetToEmpty
 	setMaximumDepth
+	removeMaximumDepth
 	getDepth
+	holdForExclusiveAccess
+	popIf
+	pushIf
+	compareAndSwapTop
+	pick
+	roll
+	drop
+	stopManipulator
 )
 
+// namesOfOperations are the names by which operations are identified in traces.
+var namesOfOperations = map[stackOperation]string{
+	push:                   "push",
+	pop:                    "pop",
+	peek:                   "peek",
+	resetToEmpty:           "resetToEmpty",
+	setMaximumDepth:        "setMaximumDepth",
+	removeMaximumDepth:     "removeMaximumDepth",
+	getDepth:               "getDepth",
+	holdForExclusiveAccess: "hold",
+	popIf:                  "popIf",
+	pushIf:                 "pushIf",
+	compareAndSwapTop:      "compareAndSwapTop",
+	pick:                   "pick",
+	roll:                   "roll",
+	drop:                   "drop",
+}
+
 type stackManipulationResponse struct {
 	poppedValueOrCurrentDepth         interface{}
 	stackIsEmptyOrFullBeforeOperation bool
 	operationError                    error
+	conditionWasSatisfied             bool
+	// recoveredPanic is a panic raised while the operation was performed, by the
+	// condition of PopIf() or PushIf() or by the Backend.
+	recoveredPanic interface{}
 }
 
 type stackManipulationMessage struct {
 	operation       stackOperation
 	valueToPush     interface{}
 	depth           uint
+	releaseChannel  <-chan struct{}
+	popCondition    func(top interface{}) bool
+	pushCondition   func(depth uint, top interface{}) bool
+	valueToCompare  interface{}
+	callerGoroutine string
+	timeOfSend      time.Time
 	responseChannel chan<- *stackManipulationResponse
 }
 
 type stackManipulator struct {
 	channelOfRequestedOperations chan *stackManipulationMessage
-	stackBackingSlice            []interface{}
-	currentStackDepth            uint
+	backend                      Backend
 	maximumStackDepth            uint
-	indexInSliceOfHead           int
 	discardsFIFOAfterMaxSize     bool
+	discardedElementCallback     func(discardedValue interface{})
+	recorder                     *Recorder
+	tracer                       Tracer
 }
 
-func newStackManipulator(initialSizeHint uint) *stackManipulator {
+func newStackManipulator(backend Backend) *stackManipulator {
 	return &stackManipulator{
 		channelOfRequestedOperations: make(chan *stackManipulationMessage),
-		stackBackingSlice:            make([]interface{}, initialSizeHint),
-		currentStackDepth:            0,
+		backend:                      backend,
 		maximumStackDepth:            0,
-		indexInSliceOfHead:           -1,
 		discardsFIFOAfterMaxSize:     false,
+		discardedElementCallback:     nil,
+		tracer:                       NoOpTracer{},
 	}
 }
 
@@ -246,38 +469,158 @@ func (manipulator *stackManipulator) whichDiscardsAtSize(maximumDepth uint) *sta
 	return manipulator
 }
 
+func (manipulator *stackManipulator) whichIsBoundedAtSize(maximumDepth uint) *stackManipulator {
+	manipulator.maximumStackDepth = maximumDepth
+	return manipulator
+}
+
+func (manipulator *stackManipulator) whichRecordsWith(recorder *Recorder) *stackManipulator {
+	manipulator.recorder = recorder
+	return manipulator
+}
+
+func (manipulator *stackManipulator) whichTracesWith(tracer Tracer) *stackManipulator {
+	manipulator.tracer = tracer
+	return manipulator
+}
+
+func (manipulator *stackManipulator) whichCallsOnDiscard(callback func(discardedValue interface{})) *stackManipulator {
+	manipulator.discardedElementCallback = callback
+	return manipulator
+}
+
+// clone returns a manipulator with the same configuration as this one and a clone of
+// its backend, except that it has no recorder.  The returned manipulator has not been
+// started.
+func (manipulator *stackManipulator) clone() *stackManipulator {
+	return &stackManipulator{
+		channelOfRequestedOperations: make(chan *stackManipulationMessage),
+		backend:                      manipulator.backend.Clone(),
+		maximumStackDepth:            manipulator.maximumStackDepth,
+		discardsFIFOAfterMaxSize:     manipulator.discardsFIFOAfterMaxSize,
+		discardedElementCallback:     manipulator.discardedElementCallback,
+		tracer:                       manipulator.tracer,
+	}
+}
+
 func (manipulator *stackManipulator) requestChannel() chan<- *stackManipulationMessage {
 	return manipulator.channelOfRequestedOperations
 }
 
 func (manipulator *stackManipulator) Start() {
+	if manipulator.recorder != nil {
+		manipulator.recordStart()
+	}
+
 	for {
 		nextRequest := <-manipulator.channelOfRequestedOperations
 
-		switch nextRequest.operation {
-		case push:
-			wasStackAlreadyFull := manipulator.push(nextRequest.valueToPush)
-			nextRequest.responseChannel <- &stackManipulationResponse{nil, wasStackAlreadyFull, nil}
+		if nextRequest.operation == stopManipulator {
+			nextRequest.responseChannel <- &stackManipulationResponse{}
+			return
+		}
 
-		case pop:
-			topOfStackValue, wasStackAlreadyEmpty := manipulator.pop()
-			nextRequest.responseChannel <- &stackManipulationResponse{topOfStackValue, wasStackAlreadyEmpty, nil}
+		trace, tracerFailure := manipulator.startTrace(nextRequest)
 
-		case resetToEmpty:
-			manipulator.resetToEmpty()
-			nextRequest.responseChannel <- &stackManipulationResponse{nil, false, nil}
+		if nextRequest.operation == holdForExclusiveAccess {
+			// A hold is recorded once it is released, so that the record reflects whatever
+			// the holder did.
+			response := &stackManipulationResponse{nil, false, nil, false, nil}
+			nextRequest.responseChannel <- response
+			<-nextRequest.releaseChannel
 
-		case setMaximumDepth:
-			err := manipulator.setMaximumDepth(nextRequest.depth)
-			nextRequest.responseChannel <- &stackManipulationResponse{nil, false, err}
+			if manipulator.recorder != nil {
+				manipulator.record(nextRequest, response)
+			}
 
-		case getDepth:
-			depth := manipulator.getCurrentDepth()
-			nextRequest.responseChannel <- &stackManipulationResponse{depth, false, nil}
+			// the holder no longer waits, so a failure of the tracer cannot be reported
+			manipulator.endTrace(trace, response)
+			continue
 		}
+
+		response := manipulator.perform(nextRequest)
+
+		if manipulator.recorder != nil {
+			manipulator.record(nextRequest, response)
+		}
+
+		if endFailure := manipulator.endTrace(trace, response); tracerFailure == nil {
+			tracerFailure = endFailure
+		}
+
+		if tracerFailure != nil && response.recoveredPanic == nil {
+			response.recoveredPanic = tracerFailure
+		}
+
+		nextRequest.responseChannel <- response
 	}
 }
 
+// perform performs a requested operation other than a hold and returns the response to
+// it.  If the operation panics, which a Backend may do when it cannot provide an element,
+// the panic is recovered and returned in the response, to be raised again in the caller's
+// goroutine rather than terminating the process.
+func (manipulator *stackManipulator) perform(request *stackManipulationMessage) (response *stackManipulationResponse) {
+	defer func() {
+		if recoveredPanic := recover(); recoveredPanic != nil {
+			response = &stackManipulationResponse{recoveredPanic: recoveredPanic}
+		}
+	}()
+
+	switch request.operation {
+	case push:
+		wasStackAlreadyFull := manipulator.push(request.valueToPush)
+		return &stackManipulationResponse{nil, wasStackAlreadyFull, nil, false, nil}
+
+	case pop:
+		topOfStackValue, wasStackAlreadyEmpty := manipulator.pop()
+		return &stackManipulationResponse{topOfStackValue, wasStackAlreadyEmpty, nil, false, nil}
+
+	case peek:
+		topOfStackValue, isStackEmpty := manipulator.peek()
+		return &stackManipulationResponse{topOfStackValue, isStackEmpty, nil, false, nil}
+
+	case resetToEmpty:
+		manipulator.resetToEmpty()
+		return &stackManipulationResponse{nil, false, nil, false, nil}
+
+	case setMaximumDepth:
+		err := manipulator.setMaximumDepth(request.depth)
+		return &stackManipulationResponse{nil, false, err, false, nil}
+
+	case removeMaximumDepth:
+		manipulator.removeMaximumDepth()
+		return &stackManipulationResponse{nil, false, nil, false, nil}
+
+	case getDepth:
+		depth := manipulator.getCurrentDepth()
+		return &stackManipulationResponse{depth, false, nil, false, nil}
+
+	case popIf:
+		return manipulator.popIf(request.popCondition)
+
+	case pushIf:
+		return manipulator.pushIf(request.pushCondition, request.valueToPush)
+
+	case compareAndSwapTop:
+		return manipulator.compareAndSwapTop(request.valueToCompare, request.valueToPush)
+
+	case pick:
+		err := manipulator.pick(request.depth)
+		return &stackManipulationResponse{nil, false, err, false, nil}
+
+	case roll:
+		err := manipulator.roll(request.depth)
+		return &stackManipulationResponse{nil, false, err, false, nil}
+
+	case drop:
+		err := manipulator.drop(request.depth)
+		return &stackManipulationResponse{nil, false, err, false, nil}
+	}
+
+	return nil
+}
+
 func (manipulator *stackManipulator) push(value interface{}) (stackWasAlreadyFull bool) {
 	if manipulator.discardsFIFOAfterMaxSize {
 		return manipulator.pushWithDiscarding(value)
@@ -288,63 +631,49 @@ func (manipulator *stackManipulator) push(value interface{}) (stackWasAlreadyFul
 }
 
 func (manipulator *stackManipulator) pushWithDiscarding(value interface{}) (stackWasAlreadyFull bool) {
-	manipulator.indexInSliceOfHead++
-
-	if manipulator.indexInSliceOfHead == int(manipulator.maximumStackDepth) {
-		manipulator.stackBackingSlice[0] = value
-		manipulator.indexInSliceOfHead = 0
-	} else {
-		if manipulator.indexInSliceOfHead == len(manipulator.stackBackingSlice) {
-			manipulator.stackBackingSlice = append(manipulator.stackBackingSlice, value)
-			manipulator.currentStackDepth++
-		} else {
-			manipulator.stackBackingSlice[manipulator.indexInSliceOfHead] = value
-			if manipulator.currentStackDepth < manipulator.maximumStackDepth {
-				manipulator.currentStackDepth++
-			}
-		}
+	if manipulator.backend.Depth() >= manipulator.maximumStackDepth {
+		manipulator.discard(manipulator.backend.RemoveFromBottom())
 	}
 
-	return manipulator.currentStackDepth >= manipulator.maximumStackDepth
+	manipulator.backend.PushOnTop(value)
+
+	return manipulator.backend.Depth() >= manipulator.maximumStackDepth
 }
 
 func (manipulator *stackManipulator) pushWithoutDiscarding(value interface{}) (stackWasAlreadyFull bool) {
-	if manipulator.maximumStackDepth > 0 && manipulator.currentStackDepth == manipulator.maximumStackDepth {
+	if manipulator.maximumStackDepth > 0 && manipulator.backend.Depth() >= manipulator.maximumStackDepth {
 		return true
 	}
 
-	manipulator.indexInSliceOfHead++
-
-	if manipulator.currentStackDepth >= uint(len(manipulator.stackBackingSlice)) {
-		manipulator.stackBackingSlice = append(manipulator.stackBackingSlice, value)
-	} else {
-		manipulator.stackBackingSlice[manipulator.indexInSliceOfHead] = value
-	}
-
-	manipulator.currentStackDepth++
+	manipulator.backend.PushOnTop(value)
 
 	return false
 }
 
 func (manipulator *stackManipulator) pop() (value interface{}, stackWasAlreadyEmpty bool) {
-	if manipulator.currentStackDepth == 0 {
+	if manipulator.backend.Depth() == 0 {
 		return nil, true
 	}
 
-	value = manipulator.stackBackingSlice[manipulator.indexInSliceOfHead]
-	manipulator.indexInSliceOfHead--
-	manipulator.currentStackDepth--
+	return manipulator.backend.PopFromTop(), false
+}
 
-	if manipulator.indexInSliceOfHead < 0 {
-		manipulator.indexInSliceOfHead = int(manipulator.maximumStackDepth) - 1
+func (manipulator *stackManipulator) peek() (value interface{}, stackIsEmpty bool) {
+	if manipulator.backend.Depth() == 0 {
+		return nil, true
 	}
 
-	return value, false
+	return manipulator.backend.ElementAt(0), false
+}
+
+func (manipulator *stackManipulator) discard(value interface{}) {
+	if manipulator.discardedElementCallback != nil {
+		manipulator.discardedElementCallback(value)
+	}
 }
 
 func (manipulator *stackManipulator) resetToEmpty() {
-	manipulator.indexInSliceOfHead = -1
-	manipulator.currentStackDepth = 0
+	manipulator.backend.Clear()
 }
 
 func (manipulator *stackManipulator) setMaximumDepth(newMaximumDepth uint) error {
@@ -352,14 +681,8 @@ func (manipulator *stackManipulator) setMaximumDepth(newMaximumDepth uint) error
 		return fmt.Errorf("stack size must be at least 1")
 	}
 
-	if newMaximumDepth < manipulator.maximumStackDepth {
-		if manipulator.indexInSliceOfHead >= int(newMaximumDepth) {
-			manipulator.indexInSliceOfHead = int(newMaximumDepth) - 1
-		}
-
-		if manipulator.currentStackDepth >= newMaximumDepth {
-			manipulator.currentStackDepth = newMaximumDepth
-		}
+	for manipulator.backend.Depth() > newMaximumDepth {
+		manipulator.discard(manipulator.backend.PopFromTop())
 	}
 
 	manipulator.maximumStackDepth = newMaximumDepth
@@ -367,6 +690,10 @@ func (manipulator *stackManipulator) setMaximumDepth(newMaximumDepth uint) error
 	return nil
 }
 
+func (manipulator *stackManipulator) removeMaximumDepth() {
+	manipulator.maximumStackDepth = 0
+}
+
 func (manipulator *stackManipulator) getCurrentDepth() uint {
-	return manipulator.currentStackDepth
+	return manipulator.backend.Depth()
 }
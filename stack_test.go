@@ -2,6 +2,7 @@ package stack_test
 
 import (
 	"fmt"
+	"runtime"
 	"testing"
 
 	"github.com/blorticus-go/stack"
@@ -223,6 +224,84 @@ func TestMaximumResizeSmaller(t *testing.T) {
 
 }
 
+func TestRemoveMaximumDepth(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s := stack.NewStack().WithAMaximumDepthOf(2)
+
+	for _, testCase := range []*stackOperationTestCase{
+		{testname: "Push First Value", operation: "push", valueToPush: "first", expectStackToHaveBeenFull: false, expectedStackDepthAfterOperation: 1},
+		{testname: "Push Second value", operation: "push", valueToPush: "second", expectStackToHaveBeenFull: false, expectedStackDepthAfterOperation: 2},
+		{testname: "Push Third value", operation: "push", valueToPush: "third", expectStackToHaveBeenFull: true, expectedStackDepthAfterOperation: 2},
+	} {
+		testCase.evaluateAgainstStack(s, g)
+	}
+
+	s.RemoveMaximumDepth()
+
+	for _, testCase := range []*stackOperationTestCase{
+		{testname: "Push Third value after removal", operation: "push", valueToPush: "third", expectStackToHaveBeenFull: false, expectedStackDepthAfterOperation: 3},
+		{testname: "Push Fourth value after removal", operation: "push", valueToPush: "fourth", expectStackToHaveBeenFull: false, expectedStackDepthAfterOperation: 4},
+		{testname: "First Pop after removal", operation: "pop", expectedPopValue: "fourth", expectStackToHaveBeenEmpty: false, expectedStackDepthAfterOperation: 3},
+	} {
+		testCase.evaluateAgainstStack(s, g)
+	}
+
+	s.SetMaximumDepthTo(2)
+
+	for _, testCase := range []*stackOperationTestCase{
+		{testname: "First Pop after reapply", operation: "pop", expectedPopValue: "second", expectStackToHaveBeenEmpty: false, expectedStackDepthAfterOperation: 1},
+		{testname: "Push Second value after reapply", operation: "push", valueToPush: "second", expectStackToHaveBeenFull: false, expectedStackDepthAfterOperation: 2},
+		{testname: "Push Third value after reapply", operation: "push", valueToPush: "third", expectStackToHaveBeenFull: true, expectedStackDepthAfterOperation: 2},
+		{testname: "Second Pop after reapply", operation: "pop", expectedPopValue: "second", expectStackToHaveBeenEmpty: false, expectedStackDepthAfterOperation: 1},
+		{testname: "Third Pop after reapply", operation: "pop", expectedPopValue: "first", expectStackToHaveBeenEmpty: false, expectedStackDepthAfterOperation: 0},
+	} {
+		testCase.evaluateAgainstStack(s, g)
+	}
+}
+
+func TestMaximumDepth(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s := stack.NewStack()
+	g.Expect(s.MaximumDepth()).To(Equal(uint(0)))
+	g.Expect(s.WithAMaximumDepthOf(3).MaximumDepth()).To(Equal(uint(3)))
+	g.Expect(s.RemoveMaximumDepth().MaximumDepth()).To(Equal(uint(0)))
+	g.Expect(stack.NewBoundedDiscardingStack(5).MaximumDepth()).To(Equal(uint(5)))
+}
+
+func TestPeek(t *testing.T) {
+	g := NewGomegaWithT(t)
+	s := stack.NewStack()
+
+	value, stackIsEmpty := s.Peek()
+	g.Expect(value).To(BeNil())
+	g.Expect(stackIsEmpty).To(BeTrue())
+
+	s.Push("first")
+	s.Push("second")
+
+	value, stackIsEmpty = s.Peek()
+	g.Expect(value).To(Equal("second"))
+	g.Expect(stackIsEmpty).To(BeFalse())
+	g.Expect(s.Depth()).To(Equal(uint(2)))
+}
+
+func TestClose(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	numberOfGoroutinesBefore := runtime.NumGoroutine()
+
+	s := stack.NewStack()
+	s.Push(1)
+	s.Close()
+	s.Close()
+
+	g.Eventually(runtime.NumGoroutine).Should(BeNumerically("<=", numberOfGoroutinesBefore))
+	g.Expect(func() { s.Push(2) }).To(PanicWith("operation on a closed stack"))
+	g.Expect(func() { s.Depth() }).To(Panic())
+}
+
 func TestPanicConditions(t *testing.T) {
 	f := func() { stack.NewStack().WithAMaximumDepthOf(0) }
 	if functionDidPanic := testForPanic(f); !functionDidPanic {
@@ -234,6 +313,11 @@ func TestPanicConditions(t *testing.T) {
 		t.Errorf("On attempt to set MaximumDepth on DiscardingStack expected panic, did not panic")
 	}
 
+	f = func() { stack.NewBoundedDiscardingStack(5).RemoveMaximumDepth() }
+	if functionDidPanic := testForPanic(f); !functionDidPanic {
+		t.Errorf("On attempt to remove MaximumDepth on DiscardingStack expected panic, did not panic")
+	}
+
 }
 
 type typedPopTestCase struct {
@@ -0,0 +1,331 @@
+// Package stackhttp provides an http.Handler that exposes registered stacks through a
+// JSON API, for debugging and for simple remote use.  The routes, relative to where the
+// Handler is mounted, are:
+//		GET    /stacks                      names and depths of all stacks
+//		GET    /stacks/{name}/depth         {"depth": 2}
+//		GET    /stacks/{name}/peek          {"value": "b", "stackIsEmpty": false}
+//		GET    /stacks/{name}/snapshot      {"depth": 2, "maximumDepth": 10,
+//		                                     "isDiscarding": false,
+//		                                     "elementsFromTop": ["b", "a"]}
+//		POST   /stacks/{name}               push {"value": "c"};
+//		                                    responds {"cannotPushBecauseStackIsFull": false,
+//		                                              "depth": 3}
+//		DELETE /stacks/{name}/top           pop; responds {"value": "c", "stackWasEmpty": false}
+//		PUT    /stacks/{name}/max-depth     set {"maximumDepth": 5}, or remove it with 0;
+//		                                    responds with the snapshot
+// A pushed value may be any JSON value and is pushed as encoding/json decodes it into an
+// interface{}.  A value that cannot be encoded as JSON is reported as the string that
+// fmt.Sprint() produces.  Errors are reported as {"error": "..."} with an appropriate
+// status code.  A Handler that is read-only serves only GET requests, and responds to
+// others with 403 Forbidden.
+package stackhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/blorticus-go/stack"
+)
+
+// MaximumRequestBodySize is the size in bytes of the largest request body accepted.
+const MaximumRequestBodySize = 1 << 20
+
+// Handler serves the JSON API for its registered stacks.  Stacks may be registered and
+// unregistered while the Handler is serving.
+type Handler struct {
+	stacksMutex sync.RWMutex
+	stacks      map[string]*stack.Stack
+	isReadOnly  bool
+}
+
+// NewHandler returns a Handler with no registered stacks, which permits changes to them.
+func NewHandler() *Handler {
+	return &Handler{stacks: make(map[string]*stack.Stack)}
+}
+
+// WhichIsReadOnly makes the Handler serve only GET requests, so that stacks can be
+// inspected but not changed.  It is usually chained with the constructor, as in:
+//		h := stackhttp.NewHandler().WhichIsReadOnly()
+func (handler *Handler) WhichIsReadOnly() *Handler {
+	handler.isReadOnly = true
+	return handler
+}
+
+// Register makes a stack available by the provided name, which must not be empty, must
+// not contain a slash and must not already be registered.
+func (handler *Handler) Register(name string, s *stack.Stack) error {
+	if name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("stack name (%q) must be non-empty and must not contain a slash", name)
+	}
+
+	if s == nil {
+		return fmt.Errorf("stack must not be nil")
+	}
+
+	handler.stacksMutex.Lock()
+	defer handler.stacksMutex.Unlock()
+
+	if _, nameIsRegistered := handler.stacks[name]; nameIsRegistered {
+		return fmt.Errorf("a stack named (%s) is already registered", name)
+	}
+
+	handler.stacks[name] = s
+	return nil
+}
+
+// Unregister removes the stack with the provided name, if there is one.
+func (handler *Handler) Unregister(name string) {
+	handler.stacksMutex.Lock()
+	defer handler.stacksMutex.Unlock()
+
+	delete(handler.stacks, name)
+}
+
+// Snapshot is the JSON representation of a stack, as of a single moment.
+type Snapshot struct {
+	Depth           uint          `json:"depth"`
+	MaximumDepth    uint          `json:"maximumDepth"`
+	IsDiscarding    bool          `json:"isDiscarding"`
+	ElementsFromTop []interface{} `json:"elementsFromTop"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type depthResponse struct {
+	Depth uint `json:"depth"`
+}
+
+type stackSummary struct {
+	Name  string `json:"name"`
+	Depth uint   `json:"depth"`
+}
+
+type peekResponse struct {
+	Value        interface{} `json:"value"`
+	StackIsEmpty bool        `json:"stackIsEmpty"`
+}
+
+type pushRequest struct {
+	// Value is raw so that an explicit null can be told apart from a missing value.
+	Value json.RawMessage `json:"value"`
+}
+
+type pushResponse struct {
+	CannotPushBecauseStackIsFull bool `json:"cannotPushBecauseStackIsFull"`
+	Depth                        uint `json:"depth"`
+}
+
+type popResponse struct {
+	Value         interface{} `json:"value"`
+	StackWasEmpty bool        `json:"stackWasEmpty"`
+}
+
+type maximumDepthRequest struct {
+	MaximumDepth *uint `json:"maximumDepth"`
+}
+
+// ServeHTTP serves a request for one of the routes described in the package documentation.
+func (handler *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	pathElements := strings.Split(strings.Trim(request.URL.Path, "/"), "/")
+
+	if pathElements[0] != "stacks" || len(pathElements) > 3 {
+		writeError(writer, http.StatusNotFound, "no such route")
+		return
+	}
+
+	if len(pathElements) == 1 {
+		if requireMethod(writer, request, http.MethodGet) {
+			writeJSON(writer, http.StatusOK, handler.summaries())
+		}
+		return
+	}
+
+	name := pathElements[1]
+	s, nameIsRegistered := handler.stackNamed(name)
+	if !nameIsRegistered {
+		writeError(writer, http.StatusNotFound, fmt.Sprintf("no stack named (%s)", name))
+		return
+	}
+
+	route := ""
+	if len(pathElements) == 3 {
+		route = pathElements[2]
+	}
+
+	switch route {
+	case "":
+		if requireMethod(writer, request, http.MethodPost) && handler.permitsChange(writer) {
+			handler.push(writer, request, s)
+		}
+
+	case "depth":
+		if requireMethod(writer, request, http.MethodGet) {
+			writeJSON(writer, http.StatusOK, depthResponse{s.Depth()})
+		}
+
+	case "peek":
+		if requireMethod(writer, request, http.MethodGet) {
+			value, stackIsEmpty := s.Peek()
+			writeJSON(writer, http.StatusOK, peekResponse{jsonValueFrom(value), stackIsEmpty})
+		}
+
+	case "snapshot":
+		if requireMethod(writer, request, http.MethodGet) {
+			writeJSON(writer, http.StatusOK, snapshotOf(s))
+		}
+
+	case "top":
+		if requireMethod(writer, request, http.MethodDelete) && handler.permitsChange(writer) {
+			value, stackWasEmpty := s.Pop()
+			writeJSON(writer, http.StatusOK, popResponse{jsonValueFrom(value), stackWasEmpty})
+		}
+
+	case "max-depth":
+		if requireMethod(writer, request, http.MethodPut) && handler.permitsChange(writer) {
+			handler.setMaximumDepth(writer, request, s)
+		}
+
+	default:
+		writeError(writer, http.StatusNotFound, "no such route")
+	}
+}
+
+func (handler *Handler) push(writer http.ResponseWriter, request *http.Request, s *stack.Stack) {
+	var body pushRequest
+	if !readJSON(writer, request, &body) {
+		return
+	}
+
+	if body.Value == nil {
+		writeError(writer, http.StatusBadRequest, "request must have a value")
+		return
+	}
+
+	var value interface{}
+	json.Unmarshal(body.Value, &value)
+
+	cannotPush := s.Push(value)
+	writeJSON(writer, http.StatusOK, pushResponse{cannotPush, s.Depth()})
+}
+
+func (handler *Handler) setMaximumDepth(writer http.ResponseWriter, request *http.Request, s *stack.Stack) {
+	var body maximumDepthRequest
+	if !readJSON(writer, request, &body) {
+		return
+	}
+
+	if body.MaximumDepth == nil {
+		writeError(writer, http.StatusBadRequest, "request must have a maximumDepth")
+		return
+	}
+
+	if s.IsDiscarding() {
+		writeError(writer, http.StatusConflict, "the maximum depth of a discarding stack cannot be changed")
+		return
+	}
+
+	if *body.MaximumDepth == 0 {
+		s.RemoveMaximumDepth()
+	} else {
+		s.SetMaximumDepthTo(*body.MaximumDepth)
+	}
+
+	writeJSON(writer, http.StatusOK, snapshotOf(s))
+}
+
+func (handler *Handler) permitsChange(writer http.ResponseWriter) bool {
+	if handler.isReadOnly {
+		writeError(writer, http.StatusForbidden, "the stacks are read-only")
+		return false
+	}
+
+	return true
+}
+
+func (handler *Handler) stackNamed(name string) (*stack.Stack, bool) {
+	handler.stacksMutex.RLock()
+	defer handler.stacksMutex.RUnlock()
+
+	s, nameIsRegistered := handler.stacks[name]
+	return s, nameIsRegistered
+}
+
+func (handler *Handler) summaries() []stackSummary {
+	handler.stacksMutex.RLock()
+	defer handler.stacksMutex.RUnlock()
+
+	summaries := make([]stackSummary, 0, len(handler.stacks))
+	for name, s := range handler.stacks {
+		summaries = append(summaries, stackSummary{name, s.Depth()})
+	}
+
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Name < summaries[j].Name })
+
+	return summaries
+}
+
+// snapshotOf returns a Snapshot of the stack.  The elements and the depth are taken
+// together, but the maximum depth is taken separately, so it may be inconsistent with
+// them if it is changed at the same moment.
+func snapshotOf(s *stack.Stack) Snapshot {
+	elements := s.Range(0, -1)
+	for i, element := range elements {
+		elements[i] = jsonValueFrom(element)
+	}
+
+	return Snapshot{
+		Depth:           uint(len(elements)),
+		MaximumDepth:    s.MaximumDepth(),
+		IsDiscarding:    s.IsDiscarding(),
+		ElementsFromTop: elements,
+	}
+}
+
+// jsonValueFrom returns the value if it can be encoded as JSON, or otherwise the string
+// that fmt.Sprint() produces for it.
+func jsonValueFrom(value interface{}) interface{} {
+	if _, err := json.Marshal(value); err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return value
+}
+
+func requireMethod(writer http.ResponseWriter, request *http.Request, method string) bool {
+	if request.Method != method {
+		writer.Header().Set("Allow", method)
+		writeError(writer, http.StatusMethodNotAllowed, fmt.Sprintf("method must be %s", method))
+		return false
+	}
+
+	return true
+}
+
+func readJSON(writer http.ResponseWriter, request *http.Request, body interface{}) bool {
+	decoder := json.NewDecoder(io.LimitReader(request.Body, MaximumRequestBodySize))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(body); err != nil {
+		writeError(writer, http.StatusBadRequest, fmt.Sprintf("request body is not valid: %s", err))
+		return false
+	}
+
+	return true
+}
+
+func writeJSON(writer http.ResponseWriter, statusCode int, body interface{}) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	json.NewEncoder(writer).Encode(body)
+}
+
+func writeError(writer http.ResponseWriter, statusCode int, message string) {
+	writeJSON(writer, statusCode, errorResponse{message})
+}
@@ -0,0 +1,175 @@
+package stackhttp_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/blorticus-go/stack"
+	"github.com/blorticus-go/stack/stackhttp"
+)
+
+type exchange struct {
+	method             string
+	path               string
+	body               string
+	expectedStatusCode int
+	expectedBody       string
+}
+
+func (e exchange) evaluateAgainst(t *testing.T, handler http.Handler) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(e.method, e.path, strings.NewReader(e.body)))
+
+	if recorder.Code != e.expectedStatusCode {
+		t.Errorf("[%s %s] expected status %d, got %d (%s)", e.method, e.path, e.expectedStatusCode, recorder.Code, recorder.Body.String())
+	}
+
+	if e.expectedBody == "" {
+		return
+	}
+
+	var expected, actual interface{}
+	if err := json.Unmarshal([]byte(e.expectedBody), &expected); err != nil {
+		t.Fatalf("[%s %s] expected body is not JSON: %s", e.method, e.path, err)
+	}
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), &actual); err != nil {
+		t.Errorf("[%s %s] response body is not JSON: %s", e.method, e.path, recorder.Body.String())
+		return
+	}
+
+	expectedAsJSON, _ := json.Marshal(expected)
+	actualAsJSON, _ := json.Marshal(actual)
+	if string(expectedAsJSON) != string(actualAsJSON) {
+		t.Errorf("[%s %s] expected body %s, got %s", e.method, e.path, expectedAsJSON, actualAsJSON)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	handler := stackhttp.NewHandler()
+	handler.Register("work", stack.NewStack())
+	handler.Register("window", stack.NewBoundedDiscardingStack(2))
+
+	unencodable := stack.NewStack()
+	unencodable.Push(func() {})
+	handler.Register("odd", unencodable)
+
+	for _, e := range []exchange{
+		{"GET", "/stacks", "", 200, `[{"name": "odd", "depth": 1}, {"name": "window", "depth": 0}, {"name": "work", "depth": 0}]`},
+		{"POST", "/stacks/work", `{"value": "a"}`, 200, `{"cannotPushBecauseStackIsFull": false, "depth": 1}`},
+		{"POST", "/stacks/work", `{"value": {"n": 1}}`, 200, `{"cannotPushBecauseStackIsFull": false, "depth": 2}`},
+		{"POST", "/stacks/work", `{"value": null}`, 200, `{"cannotPushBecauseStackIsFull": false, "depth": 3}`},
+		{"GET", "/stacks/work/depth", "", 200, `{"depth": 3}`},
+		{"GET", "/stacks/work/peek", "", 200, `{"value": null, "stackIsEmpty": false}`},
+		{"GET", "/stacks/work/snapshot", "", 200, `{"depth": 3, "maximumDepth": 0, "isDiscarding": false, "elementsFromTop": [null, {"n": 1}, "a"]}`},
+		{"DELETE", "/stacks/work/top", "", 200, `{"value": null, "stackWasEmpty": false}`},
+		{"PUT", "/stacks/work/max-depth", `{"maximumDepth": 1}`, 200, `{"depth": 1, "maximumDepth": 1, "isDiscarding": false, "elementsFromTop": ["a"]}`},
+		{"POST", "/stacks/work", `{"value": "b"}`, 200, `{"cannotPushBecauseStackIsFull": true, "depth": 1}`},
+		{"PUT", "/stacks/work/max-depth", `{"maximumDepth": 0}`, 200, `{"depth": 1, "maximumDepth": 0, "isDiscarding": false, "elementsFromTop": ["a"]}`},
+		{"DELETE", "/stacks/work/top", "", 200, `{"value": "a", "stackWasEmpty": false}`},
+		{"DELETE", "/stacks/work/top", "", 200, `{"value": null, "stackWasEmpty": true}`},
+		{"GET", "/stacks/work/peek", "", 200, `{"value": null, "stackIsEmpty": true}`},
+		{"PUT", "/stacks/window/max-depth", `{"maximumDepth": 5}`, 409, ""},
+		{"POST", "/stacks/work", `{"value": 1, "extra": 2}`, 400, ""},
+		{"POST", "/stacks/work", `{}`, 400, `{"error": "request must have a value"}`},
+		{"POST", "/stacks/work", `not json`, 400, ""},
+		{"PUT", "/stacks/work/max-depth", `{}`, 400, `{"error": "request must have a maximumDepth"}`},
+		{"PUT", "/stacks/work/max-depth", `{"maximumDepth": -1}`, 400, ""},
+		{"GET", "/stacks/missing/depth", "", 404, `{"error": "no stack named (missing)"}`},
+		{"GET", "/stacks/work/nothing", "", 404, ""},
+		{"GET", "/other", "", 404, ""},
+		{"POST", "/stacks/work/depth", "", 405, ""},
+		{"GET", "/stacks/work", "", 405, ""},
+	} {
+		e.evaluateAgainst(t, handler)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/stacks/odd/peek", nil))
+
+	var peek struct{ Value interface{} }
+	json.Unmarshal(recorder.Body.Bytes(), &peek)
+	if _, valueIsString := peek.Value.(string); recorder.Code != 200 || !valueIsString {
+		t.Errorf("expected a value that cannot be encoded to be reported as a string, got (%d, %s)", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestReadOnlyHandler(t *testing.T) {
+	s := stack.NewStack()
+	s.Push("a")
+
+	handler := stackhttp.NewHandler().WhichIsReadOnly()
+	handler.Register("s", s)
+
+	for _, e := range []exchange{
+		{"GET", "/stacks/s/snapshot", "", 200, `{"depth": 1, "maximumDepth": 0, "isDiscarding": false, "elementsFromTop": ["a"]}`},
+		{"POST", "/stacks/s", `{"value": "b"}`, 403, `{"error": "the stacks are read-only"}`},
+		{"DELETE", "/stacks/s/top", "", 403, ""},
+		{"PUT", "/stacks/s/max-depth", `{"maximumDepth": 1}`, 403, ""},
+	} {
+		e.evaluateAgainst(t, handler)
+	}
+
+	if depth := s.Depth(); depth != 1 {
+		t.Errorf("expected read-only handler to leave the stack unchanged, depth is %d", depth)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	handler := stackhttp.NewHandler()
+
+	for _, name := range []string{"", "a/b"} {
+		if err := handler.Register(name, stack.NewStack()); err == nil {
+			t.Errorf("[%q] expected error on Register", name)
+		}
+	}
+
+	if err := handler.Register("s", stack.NewStack()); err != nil {
+		t.Fatalf("unexpected error on Register: %s", err)
+	}
+
+	if err := handler.Register("s", stack.NewStack()); err == nil {
+		t.Errorf("expected error on Register of duplicate name")
+	}
+
+	handler.Unregister("s")
+	exchange{"GET", "/stacks/s/depth", "", 404, ""}.evaluateAgainst(t, handler)
+}
+
+func TestHandlerWithServer(t *testing.T) {
+	handler := stackhttp.NewHandler()
+	handler.Register("s", stack.NewStack())
+
+	server := httptest.NewServer(http.StripPrefix("/debug", handler))
+	defer server.Close()
+
+	response, err := http.Post(server.URL+"/debug/stacks/s", "application/json", strings.NewReader(`{"value": 42}`))
+	if err != nil {
+		t.Fatalf("unexpected error on POST: %s", err)
+	}
+	response.Body.Close()
+
+	response, err = http.Get(server.URL + "/debug/stacks/s/peek")
+	if err != nil {
+		t.Fatalf("unexpected error on GET: %s", err)
+	}
+	defer response.Body.Close()
+
+	if contentType := response.Header.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("expected Content-Type application/json, got %s", contentType)
+	}
+
+	var peek struct {
+		Value        float64
+		StackIsEmpty bool
+	}
+	json.NewDecoder(response.Body).Decode(&peek)
+	if peek.Value != 42 || peek.StackIsEmpty {
+		t.Errorf("expected peek of 42, got %+v", peek)
+	}
+}
@@ -0,0 +1,173 @@
+package stack
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Tracer receives a notification as the stack begins and ends each operation, so that
+// operations can be reported to a tracing system.  Operations are performed one at a
+// time by a single goroutine, and a request for an operation waits until that goroutine
+// receives it, so the time an operation takes is divided between the time it waits to
+// be received and the time it is processed.  An OperationTrace reports both.
+//
+// The methods are called from the goroutine that serializes stack operations, so they
+// delay every operation on the stack, and they must not themselves operate on the stack.
+// They should not panic.  If one does, the panic is recovered so that the stack keeps
+// working, and is raised again in the caller of the operation, as a panic of the Backend
+// is, once the operation has been performed.  A caller that holds the stack, as Range()
+// does, is no longer waiting when the hold ends, so a panic for a "hold" is discarded.
+type Tracer interface {
+	// OnOperationStart is called when the stack receives an operation, before it is
+	// performed.  The TimeOfEnd of the trace is not yet set.
+	OnOperationStart(operation *OperationTrace)
+
+	// OnOperationEnd is called when the operation has been performed, before its result
+	// is returned to the caller.  It receives the same OperationTrace as the matching
+	// OnOperationStart.
+	OnOperationEnd(operation *OperationTrace)
+}
+
+// OperationTrace describes a single operation for a Tracer.
+type OperationTrace struct {
+	// Operation is the name of the operation, as used by a Recorder, such as "push",
+	// "popIf" or "hold".  A "hold" is exclusive access to the stack by one of its methods,
+	// such as Range(), and ends when that method releases the stack.
+	Operation string
+
+	// TimeOfSend is when the operation was requested, TimeOfStart is when the stack
+	// received it and TimeOfEnd is when the stack finished performing it.
+	TimeOfSend  time.Time
+	TimeOfStart time.Time
+	TimeOfEnd   time.Time
+
+	// Error is the error returned by the operation, or the panic raised while it was
+	// performed, by the condition of PopIf() or PushIf() or by the Backend, if there was
+	// one.
+	Error error
+
+	// Annotation is for the Tracer's own use.  For example, OnOperationStart may set it
+	// to a span that OnOperationEnd then ends.
+	Annotation interface{}
+}
+
+// QueueWaitTime returns the time the operation waited before the stack received it.
+func (operation *OperationTrace) QueueWaitTime() time.Duration {
+	return operation.TimeOfStart.Sub(operation.TimeOfSend)
+}
+
+// ProcessingTime returns the time the stack took to perform the operation.
+func (operation *OperationTrace) ProcessingTime() time.Duration {
+	return operation.TimeOfEnd.Sub(operation.TimeOfStart)
+}
+
+// NoOpTracer is a Tracer that does nothing.  It is the Tracer of a stack created without
+// WithTracer(), and a stack with this Tracer does not read the clock to trace operations.
+type NoOpTracer struct{}
+
+func (NoOpTracer) OnOperationStart(operation *OperationTrace) {}
+func (NoOpTracer) OnOperationEnd(operation *OperationTrace)   {}
+
+// InMemoryTracer is a Tracer that keeps every operation that has ended, for use in tests
+// and for simple measurements.  It may be shared by several stacks.
+type InMemoryTracer struct {
+	mutex      sync.Mutex
+	operations []OperationTrace
+}
+
+// NewInMemoryTracer returns an InMemoryTracer with no operations.
+func NewInMemoryTracer() *InMemoryTracer {
+	return &InMemoryTracer{}
+}
+
+func (tracer *InMemoryTracer) OnOperationStart(operation *OperationTrace) {}
+
+func (tracer *InMemoryTracer) OnOperationEnd(operation *OperationTrace) {
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+
+	tracer.operations = append(tracer.operations, *operation)
+}
+
+// Operations returns the operations that have ended, in the order in which they ended.
+func (tracer *InMemoryTracer) Operations() []OperationTrace {
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+
+	return append([]OperationTrace(nil), tracer.operations...)
+}
+
+// Reset discards the operations kept so far.
+func (tracer *InMemoryTracer) Reset() {
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+
+	tracer.operations = nil
+}
+
+// WithTracer sets the Tracer that is notified of every operation performed on the stack.
+// A clone of the stack uses the same Tracer.
+func WithTracer(tracer Tracer) Option {
+	return func(configuration *stackConfiguration) error {
+		if tracer == nil {
+			return fmt.Errorf("tracer must not be nil")
+		}
+
+		configuration.tracer = tracer
+		return nil
+	}
+}
+
+func (manipulator *stackManipulator) isTraced() bool {
+	return manipulator.tracer != Tracer(NoOpTracer{})
+}
+
+// startTrace notifies the tracer that the manipulator has received a request, and
+// returns the trace for the request, or nil if operations are not traced.  It also
+// returns the failure of the tracer, if it panicked.
+func (manipulator *stackManipulator) startTrace(request *stackManipulationMessage) (trace *OperationTrace, tracerFailure error) {
+	if !manipulator.isTraced() {
+		return nil, nil
+	}
+
+	trace = &OperationTrace{
+		Operation:   namesOfOperations[request.operation],
+		TimeOfSend:  request.timeOfSend,
+		TimeOfStart: time.Now(),
+	}
+
+	return trace, notifyTracer(manipulator.tracer.OnOperationStart, trace)
+}
+
+// endTrace notifies the tracer that the manipulator has performed a request, and returns
+// the failure of the tracer, if it panicked.
+func (manipulator *stackManipulator) endTrace(trace *OperationTrace, response *stackManipulationResponse) (tracerFailure error) {
+	if trace == nil {
+		return nil
+	}
+
+	trace.TimeOfEnd = time.Now()
+
+	if response.operationError != nil {
+		trace.Error = response.operationError
+	} else if response.recoveredPanic != nil {
+		trace.Error = fmt.Errorf("operation panicked: %v", response.recoveredPanic)
+	}
+
+	return notifyTracer(manipulator.tracer.OnOperationEnd, trace)
+}
+
+// notifyTracer calls a method of a Tracer, and returns an error describing the panic it
+// raised, if it panicked.
+func notifyTracer(notification func(operation *OperationTrace), trace *OperationTrace) (tracerFailure error) {
+	defer func() {
+		if recoveredPanic := recover(); recoveredPanic != nil {
+			tracerFailure = fmt.Errorf("tracer panicked: %v", recoveredPanic)
+		}
+	}()
+
+	notification(trace)
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package stack_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blorticus-go/stack"
+	. "github.com/onsi/gomega"
+)
+
+func TestInMemoryTracer(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tracer := stack.NewInMemoryTracer()
+	s, err := stack.New(stack.WithTracer(tracer))
+	g.Expect(err).To(BeNil())
+
+	s.Push("a")
+	s.Pop()
+	s.Range(0, -1)
+	g.Expect(s.Drop(2)).ToNot(Succeed())
+	s.Push("b")
+	g.Expect(func() { s.PopIf(func(top interface{}) bool { panic("unexpected") }) }).To(Panic())
+	s.Depth()
+
+	operations := tracer.Operations()
+
+	names := []string{}
+	for _, operation := range operations {
+		names = append(names, operation.Operation)
+
+		g.Expect(operation.TimeOfSend.IsZero()).To(BeFalse(), operation.Operation)
+		g.Expect(operation.QueueWaitTime()).To(BeNumerically(">=", 0), operation.Operation)
+		g.Expect(operation.ProcessingTime()).To(BeNumerically(">=", 0), operation.Operation)
+	}
+
+	g.Expect(names).To(Equal([]string{"push", "pop", "hold", "drop", "push", "popIf", "getDepth"}))
+	g.Expect(operations[0].Error).To(BeNil())
+	g.Expect(errors.Is(operations[3].Error, stack.ErrStackUnderflow)).To(BeTrue())
+	g.Expect(operations[5].Error).To(MatchError(ContainSubstring("unexpected")))
+
+	// The hold made by Clone() may be traced after the clone has begun operating, so wait
+	// for the original stack to finish with it.
+	clone := s.Clone()
+	s.Depth()
+	tracer.Reset()
+	clone.Depth()
+	g.Expect(tracer.Operations()).To(HaveLen(1))
+
+	_, err = stack.New(stack.WithTracer(nil))
+	g.Expect(err).ToNot(BeNil())
+}
+
+// slowTracer delays the first push, so that an operation sent meanwhile waits for it.
+type slowTracer struct {
+	*stack.InMemoryTracer
+	firstPushHasStarted chan struct{}
+	once                sync.Once
+}
+
+func (tracer *slowTracer) OnOperationStart(operation *stack.OperationTrace) {
+	operation.Annotation = "annotated"
+
+	if operation.Operation == "push" {
+		tracer.once.Do(func() {
+			close(tracer.firstPushHasStarted)
+			time.Sleep(50 * time.Millisecond)
+		})
+	}
+}
+
+func (tracer *slowTracer) OnOperationEnd(operation *stack.OperationTrace) {
+	tracer.InMemoryTracer.OnOperationEnd(operation)
+}
+
+func TestTracerSeparatesQueueWaitFromProcessing(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tracer := &slowTracer{InMemoryTracer: stack.NewInMemoryTracer(), firstPushHasStarted: make(chan struct{})}
+	s, _ := stack.New(stack.WithTracer(tracer))
+
+	firstPushHasReturned := make(chan struct{})
+	go func() {
+		s.Push("first")
+		close(firstPushHasReturned)
+	}()
+
+	<-tracer.firstPushHasStarted
+	s.Push("second")
+	<-firstPushHasReturned
+
+	operations := tracer.Operations()
+	g.Expect(operations).To(HaveLen(2))
+
+	g.Expect(operations[0].ProcessingTime()).To(BeNumerically(">=", 50*time.Millisecond))
+	g.Expect(operations[1].QueueWaitTime()).To(BeNumerically(">=", 25*time.Millisecond))
+	g.Expect(operations[1].ProcessingTime()).To(BeNumerically("<", 25*time.Millisecond))
+
+	for _, operation := range operations {
+		g.Expect(operation.Annotation).To(Equal("annotated"))
+		g.Expect(operation.TimeOfStart).To(BeTemporally(">=", operation.TimeOfSend))
+		g.Expect(operation.TimeOfEnd).To(BeTemporally(">=", operation.TimeOfStart))
+	}
+}
+
+func TestNoOpTracer(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s, err := stack.New(stack.WithTracer(stack.NoOpTracer{}))
+	g.Expect(err).To(BeNil())
+
+	s.Push(1)
+	g.Expect(s.Depth()).To(Equal(uint(1)))
+}
+
+// panickingTracer panics in OnOperationStart or OnOperationEnd for the named operation.
+type panickingTracer struct {
+	operationThatPanicsOnStart string
+	operationThatPanicsOnEnd   string
+}
+
+func (tracer *panickingTracer) OnOperationStart(operation *stack.OperationTrace) {
+	if operation.Operation == tracer.operationThatPanicsOnStart {
+		panic("unable to start span")
+	}
+}
+
+func (tracer *panickingTracer) OnOperationEnd(operation *stack.OperationTrace) {
+	if operation.Operation == tracer.operationThatPanicsOnEnd {
+		panic("unable to end span")
+	}
+}
+
+func TestTracerThatPanics(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	s, _ := stack.New(stack.WithTracer(&panickingTracer{operationThatPanicsOnStart: "push", operationThatPanicsOnEnd: "pop"}))
+
+	g.Expect(func() { s.Push(1) }).To(PanicWith(MatchError("tracer panicked: unable to start span")))
+	g.Expect(s.Depth()).To(Equal(uint(1)))
+
+	g.Expect(func() { s.Pop() }).To(PanicWith(MatchError("tracer panicked: unable to end span")))
+	g.Expect(s.Depth()).To(Equal(uint(0)))
+
+	holdingTracer := &panickingTracer{operationThatPanicsOnStart: "hold", operationThatPanicsOnEnd: "hold"}
+	held, _ := stack.New(stack.WithTracer(holdingTracer))
+	held.Push("a")
+	g.Expect(held.Range(0, -1)).To(Equal([]interface{}{"a"}))
+	g.Expect(held.Depth()).To(Equal(uint(1)))
+}